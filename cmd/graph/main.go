@@ -2,66 +2,128 @@ package main
 
 import (
 	"context"
+	"flag"
 	"log"
 	"net/http"
+	"os"
 
 	"github.com/99designs/gqlgen/graphql/handler"
 	"github.com/99designs/gqlgen/graphql/playground"
-	"github.com/joho/godotenv"
 	"github.com/marcos-brito/booklist/internal/auth"
+	"github.com/marcos-brito/booklist/internal/config"
 	"github.com/marcos-brito/booklist/internal/conn"
+	"github.com/marcos-brito/booklist/internal/export"
+	"github.com/marcos-brito/booklist/internal/hooks"
+	"github.com/marcos-brito/booklist/internal/importer"
+	"github.com/marcos-brito/booklist/internal/loaders"
+	"github.com/marcos-brito/booklist/internal/migrate"
 	"github.com/marcos-brito/booklist/internal/resolvers"
+	"github.com/marcos-brito/booklist/internal/store"
+	"github.com/redis/go-redis/v9"
+	"gorm.io/gorm"
 )
 
-func setupPostgres() {
-	db, err := conn.NewPostgresConnection()
+func setupDatabase(cfg *config.Database) *gorm.DB {
+	db, err := conn.NewConnection(cfg)
 	if err != nil {
-		log.Fatalf("couldn't connect postgres: %s", err)
+		log.Fatalf("couldn't connect to the database: %s", err)
 	}
 
-	conn.InitDatabase(db)
-	err = conn.Migrate(db)
+	migrator, err := migrate.New(db)
+	if err != nil {
+		log.Fatalf("couldn't run migrations: %s", err)
+	}
+
+	err = migrator.Check()
+	if err != nil {
+		log.Fatalf("refusing to start: %s", err)
+	}
 
+	applied, err := migrator.Up()
 	if err != nil {
 		log.Fatalf("couldn't run migrations: %s", err)
 	}
+
+	for _, migration := range applied {
+		log.Printf("applied migration %04d_%s", migration.Version, migration.Name)
+	}
+
+	return db
 }
 
-func setupRedis() {
-	rdb := conn.NewRedisClient()
+func setupRedis(cfg *config.Redis) *redis.Client {
+	rdb := conn.NewRedisClient(cfg)
 	err := rdb.Ping(context.Background()).Err()
 
 	if err != nil {
 		log.Fatalf("couldn't connect to redis: %s", err)
 	}
 
-	conn.InitRedis(rdb)
+	return rdb
 }
 
-func main() {
-	err := godotenv.Load("../../.env")
+func setupConfig() *config.Config {
+	flags := flag.NewFlagSet("graph", flag.ExitOnError)
+	printConfig := flags.Bool("print-config", false, "print the configuration in effect with secrets redacted and exit")
+	cfg, err := config.Load(flags, os.Args[1:])
 	if err != nil {
-		log.Fatalf("couldn't load .env")
+		log.Fatalf("couldn't load the configuration: %s", err)
 	}
 
-	ory := conn.NewOryClient()
-	conn.InitOry(ory)
+	if *printConfig {
+		err = cfg.Print(os.Stdout)
+		if err != nil {
+			log.Fatal(err)
+		}
 
-	setupPostgres()
-	setupRedis()
+		os.Exit(0)
+	}
+
+	err = cfg.Validate()
+	if err != nil {
+		log.Fatalf("invalid configuration:\n%s", err)
+	}
+
+	return cfg
+}
+
+func main() {
+	cfg := setupConfig()
+	ory := conn.NewOryClient()
+	db := setupDatabase(&cfg.Database)
+	repos := store.NewRepositories(db)
+	resolver := &resolvers.Resolver{
+		Repos:      repos,
+		Importer:   importer.NewDBRunner(db),
+		Redis:      setupRedis(&cfg.Redis),
+		Identities: auth.NewOryProvider(ory),
+	}
 
 	router := http.NewServeMux()
-	graphql := handler.NewDefaultServer(resolvers.NewExecutableSchema(resolvers.Config{Resolvers: &resolvers.Resolver{}}))
+	graphql := handler.NewDefaultServer(resolvers.NewExecutableSchema(resolvers.Config{Resolvers: resolver}))
+	graphql.SetErrorPresenter(resolvers.ErrorPresenter)
 
-	router.Handle("/graphql", graphql)
+	router.Handle("/graphql", loaders.Middleware(graphql, repos))
+	router.Handle("GET /export/{token}", export.Handler(repos.Exports, repos.Users))
+	router.Handle("POST /hooks/ory/identity-deleted", hooks.IdentityDeleted(repos.Users, cfg.Ory.WebhookSecret))
 	router.Handle("/", playground.Handler("Booklist", "/graphql"))
 
 	server := http.Server{
-		Addr:    ":8080",
-		Handler: auth.SessionMiddleware(router, conn.Ory),
+		Addr:              cfg.Server.Addr,
+		Handler:           auth.SessionMiddleware(router, ory),
+		ReadHeaderTimeout: cfg.Server.ReadHeaderTimeout,
+		ReadTimeout:       cfg.Server.ReadTimeout,
+		WriteTimeout:      cfg.Server.WriteTimeout,
+		IdleTimeout:       cfg.Server.IdleTimeout,
+	}
+
+	var err error
+	if cfg.Server.TLS() {
+		err = server.ListenAndServeTLS(cfg.Server.TLSCertFile, cfg.Server.TLSKeyFile)
+	} else {
+		err = server.ListenAndServe()
 	}
 
-	err = server.ListenAndServe()
 	if err != nil {
 		log.Fatalf("couldn't start the server: %s", err)
 	}
@@ -0,0 +1,136 @@
+package main
+
+import (
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/marcos-brito/booklist/internal/config"
+	"github.com/marcos-brito/booklist/internal/conn"
+	"github.com/marcos-brito/booklist/internal/migrate"
+)
+
+const usage = `Usage: migrate [flags] <command>
+
+Commands:
+  up             apply every pending migration
+  down           revert the latest migration applied
+  status         list the migrations and when they were applied
+  create <name>  add empty up and down files for a new migration
+
+Flags:
+`
+
+func setupMigrator(cfg *config.Config) *migrate.Migrator {
+	err := cfg.Validate()
+	if err != nil {
+		log.Fatalf("invalid configuration:\n%s", err)
+	}
+
+	db, err := conn.NewConnection(&cfg.Database)
+	if err != nil {
+		log.Fatalf("couldn't connect to the database: %s", err)
+	}
+
+	migrator, err := migrate.New(db)
+	if err != nil {
+		log.Fatalf("couldn't load migrations: %s", err)
+	}
+
+	return migrator
+}
+
+func up(cfg *config.Config) {
+	applied, err := setupMigrator(cfg).Up()
+	for _, migration := range applied {
+		fmt.Printf("applied %04d_%s\n", migration.Version, migration.Name)
+	}
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if len(applied) == 0 {
+		fmt.Println("nothing to apply")
+	}
+}
+
+func down(cfg *config.Config) {
+	migration, err := setupMigrator(cfg).Down()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	if migration == nil {
+		fmt.Println("nothing to revert")
+		return
+	}
+
+	fmt.Printf("reverted %04d_%s\n", migration.Version, migration.Name)
+}
+
+func status(cfg *config.Config) {
+	statuses, err := setupMigrator(cfg).Status()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "VERSION\tNAME\tAPPLIED")
+	for _, status := range statuses {
+		applied := "pending"
+		if status.AppliedAt != nil {
+			applied = status.AppliedAt.Format(time.DateTime)
+		}
+
+		if status.Unknown {
+			applied += " (unknown to this build)"
+		}
+
+		fmt.Fprintf(w, "%04d\t%s\t%s\n", status.Version, status.Name, applied)
+	}
+
+	w.Flush()
+}
+
+func create(dir, name string) {
+	paths, err := migrate.Create(dir, name)
+	if err != nil {
+		log.Fatalf("couldn't create migration: %s", err)
+	}
+
+	for _, path := range paths {
+		fmt.Printf("created %s\n", path)
+	}
+}
+
+func main() {
+	flags := flag.NewFlagSet("migrate", flag.ExitOnError)
+	dir := flags.String("dir", migrate.Dir, "where create writes new migrations")
+	flags.Usage = func() {
+		fmt.Fprint(flags.Output(), usage)
+		flags.PrintDefaults()
+	}
+
+	cfg, err := config.Load(flags, os.Args[1:])
+	if err != nil {
+		log.Fatalf("couldn't load the configuration: %s", err)
+	}
+
+	switch args := flags.Args(); {
+	case len(args) == 1 && args[0] == "up":
+		up(cfg)
+	case len(args) == 1 && args[0] == "down":
+		down(cfg)
+	case len(args) == 1 && args[0] == "status":
+		status(cfg)
+	case len(args) == 2 && args[0] == "create":
+		create(*dir, args[1])
+	default:
+		flags.Usage()
+		os.Exit(2)
+	}
+}
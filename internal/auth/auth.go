@@ -82,8 +82,23 @@ func GetSession(ctx context.Context) (*ory.Session, *Identity, bool) {
 	return session, ident, true
 }
 
-func FindIdentity(uuid uuid.UUID, client *ory.APIClient) (*Identity, bool) {
-	resp, _, err := client.IdentityAPI.GetIdentity(context.Background(), uuid.String()).Execute()
+// What the app needs from the identity provider, besides the sessions
+// checked by SessionMiddleware.
+type IdentityProvider interface {
+	FindIdentity(uuid uuid.UUID) (*Identity, bool)
+	DeleteIdentity(uuid uuid.UUID) error
+}
+
+type OryProvider struct {
+	client *ory.APIClient
+}
+
+func NewOryProvider(client *ory.APIClient) *OryProvider {
+	return &OryProvider{client}
+}
+
+func (op *OryProvider) FindIdentity(uuid uuid.UUID) (*Identity, bool) {
+	resp, _, err := op.client.IdentityAPI.GetIdentity(context.Background(), uuid.String()).Execute()
 	if err != nil {
 		return nil, false
 	}
@@ -95,3 +110,8 @@ func FindIdentity(uuid uuid.UUID, client *ory.APIClient) (*Identity, bool) {
 
 	return ident, true
 }
+
+func (op *OryProvider) DeleteIdentity(uuid uuid.UUID) error {
+	_, err := op.client.IdentityAPI.DeleteIdentity(context.Background(), uuid.String()).Execute()
+	return err
+}
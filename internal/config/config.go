@@ -0,0 +1,339 @@
+package config
+
+import (
+	"errors"
+	"flag"
+	"fmt"
+	"io"
+	"os"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/joho/godotenv"
+)
+
+const (
+	DriverPostgres = "postgres"
+	DriverSQLite   = "sqlite"
+)
+
+// The values libpq takes for sslmode.
+var sslModes = []string{"disable", "allow", "prefer", "require", "verify-ca", "verify-full"}
+
+type Config struct {
+	Server   Server
+	Database Database
+	Redis    Redis
+	Ory      Ory
+}
+
+type Server struct {
+	Addr string
+	// Served over HTTPS when both are set
+	TLSCertFile       string
+	TLSKeyFile        string
+	ReadHeaderTimeout time.Duration
+	ReadTimeout       time.Duration
+	// Zero means none, which subscriptions and large exports need
+	WriteTimeout time.Duration
+	IdleTimeout  time.Duration
+}
+
+func (s *Server) TLS() bool {
+	return s.TLSCertFile != "" && s.TLSKeyFile != ""
+}
+
+type Database struct {
+	Driver          string
+	SQLitePath      string
+	Postgres        Postgres
+	MaxOpenConns    int
+	MaxIdleConns    int
+	ConnMaxLifetime time.Duration
+}
+
+type Postgres struct {
+	Host           string
+	Port           int
+	User           string
+	Password       string
+	Name           string
+	SSLMode        string
+	ConnectTimeout time.Duration
+}
+
+type Redis struct {
+	Host         string
+	Port         int
+	Password     string
+	PoolSize     int
+	DialTimeout  time.Duration
+	ReadTimeout  time.Duration
+	WriteTimeout time.Duration
+}
+
+type Ory struct {
+	// Without it the webhooks refuse every request
+	WebhookSecret string
+}
+
+func Default() *Config {
+	return &Config{
+		Server: Server{
+			Addr:              ":8080",
+			ReadHeaderTimeout: 10 * time.Second,
+			ReadTimeout:       time.Minute,
+			IdleTimeout:       2 * time.Minute,
+		},
+		Database: Database{
+			Driver:     DriverPostgres,
+			SQLitePath: "booklist.db",
+			Postgres: Postgres{
+				Host:           "localhost",
+				Port:           5432,
+				SSLMode:        "disable",
+				ConnectTimeout: 10 * time.Second,
+			},
+			MaxOpenConns:    25,
+			MaxIdleConns:    10,
+			ConnMaxLifetime: 30 * time.Minute,
+		},
+		Redis: Redis{
+			Host:         "localhost",
+			Port:         6379,
+			PoolSize:     10,
+			DialTimeout:  5 * time.Second,
+			ReadTimeout:  3 * time.Second,
+			WriteTimeout: 3 * time.Second,
+		},
+	}
+}
+
+// A string flag that is redacted when the config is printed.
+type secret struct {
+	*string
+}
+
+func (s secret) String() string {
+	if s.string == nil {
+		return ""
+	}
+
+	return *s.string
+}
+
+func (s secret) Set(value string) error {
+	*s.string = value
+	return nil
+}
+
+// Returns a flag for every option, bound to the config and defaulting to
+// its current values. Each option is also read from the file and the
+// environment under its key.
+func (c *Config) flags() *flag.FlagSet {
+	fs := flag.NewFlagSet("config", flag.ContinueOnError)
+
+	fs.StringVar(&c.Server.Addr, "listen-addr", c.Server.Addr, "address the server listens on")
+	fs.StringVar(&c.Server.TLSCertFile, "tls-cert-file", c.Server.TLSCertFile, "certificate to serve HTTPS with, along with tls-key-file")
+	fs.StringVar(&c.Server.TLSKeyFile, "tls-key-file", c.Server.TLSKeyFile, "private key of tls-cert-file")
+	fs.DurationVar(&c.Server.ReadHeaderTimeout, "read-header-timeout", c.Server.ReadHeaderTimeout, "how long clients have to send the headers of a request")
+	fs.DurationVar(&c.Server.ReadTimeout, "read-timeout", c.Server.ReadTimeout, "how long clients have to send a whole request")
+	fs.DurationVar(&c.Server.WriteTimeout, "write-timeout", c.Server.WriteTimeout, "how long a response can take to write, 0 for no limit")
+	fs.DurationVar(&c.Server.IdleTimeout, "idle-timeout", c.Server.IdleTimeout, "how long idle keep-alive connections are kept open")
+
+	fs.StringVar(&c.Database.Driver, "database-driver", c.Database.Driver, "database to use, postgres or sqlite")
+	fs.StringVar(&c.Database.SQLitePath, "sqlite-path", c.Database.SQLitePath, "file of the SQLite database")
+	fs.StringVar(&c.Database.Postgres.Host, "postgres-host", c.Database.Postgres.Host, "host of the Postgres server")
+	fs.IntVar(&c.Database.Postgres.Port, "postgres-port", c.Database.Postgres.Port, "port of the Postgres server")
+	fs.StringVar(&c.Database.Postgres.User, "postgres-user", c.Database.Postgres.User, "user to connect to Postgres as")
+	fs.Var(secret{&c.Database.Postgres.Password}, "postgres-password", "password of postgres-user")
+	fs.StringVar(&c.Database.Postgres.Name, "postgres-db", c.Database.Postgres.Name, "name of the Postgres database")
+	fs.StringVar(&c.Database.Postgres.SSLMode, "postgres-sslmode", c.Database.Postgres.SSLMode, "libpq sslmode: "+strings.Join(sslModes, ", "))
+	fs.DurationVar(&c.Database.Postgres.ConnectTimeout, "postgres-connect-timeout", c.Database.Postgres.ConnectTimeout, "how long connecting to Postgres can take")
+	fs.IntVar(&c.Database.MaxOpenConns, "database-max-open-conns", c.Database.MaxOpenConns, "most connections open to the database, 0 for no limit")
+	fs.IntVar(&c.Database.MaxIdleConns, "database-max-idle-conns", c.Database.MaxIdleConns, "most idle connections kept in the pool")
+	fs.DurationVar(&c.Database.ConnMaxLifetime, "database-conn-max-lifetime", c.Database.ConnMaxLifetime, "how long a connection is reused, 0 for forever")
+
+	fs.StringVar(&c.Redis.Host, "redis-host", c.Redis.Host, "host of the Redis server")
+	fs.IntVar(&c.Redis.Port, "redis-port", c.Redis.Port, "port of the Redis server")
+	fs.Var(secret{&c.Redis.Password}, "redis-password", "password of the Redis server")
+	fs.IntVar(&c.Redis.PoolSize, "redis-pool-size", c.Redis.PoolSize, "most connections open to Redis")
+	fs.DurationVar(&c.Redis.DialTimeout, "redis-dial-timeout", c.Redis.DialTimeout, "how long connecting to Redis can take")
+	fs.DurationVar(&c.Redis.ReadTimeout, "redis-read-timeout", c.Redis.ReadTimeout, "how long reading a reply from Redis can take")
+	fs.DurationVar(&c.Redis.WriteTimeout, "redis-write-timeout", c.Redis.WriteTimeout, "how long sending a command to Redis can take")
+
+	fs.Var(secret{&c.Ory.WebhookSecret}, "ory-webhook-secret", "secret Ory sends along with webhooks")
+
+	return fs
+}
+
+// The key of an option in the file and the environment.
+func key(flag string) string {
+	return strings.ToUpper(strings.ReplaceAll(flag, "-", "_"))
+}
+
+// Reads the config from a file of KEY=value lines, the environment and
+// the flags in args, each overriding the one before it. The options are
+// added to fs, along with -config to choose the file. A missing .env is
+// fine unless it was asked for. The config isn't validated, so it can be
+// printed as it is.
+func Load(fs *flag.FlagSet, args []string) (*Config, error) {
+	c := Default()
+	options := c.flags()
+	options.VisitAll(func(f *flag.Flag) {
+		fs.Var(f.Value, f.Name, f.Usage)
+	})
+
+	path := fs.String("config", ".env", "file to read the configuration from, in KEY=value lines")
+	err := fs.Parse(args)
+	if err != nil {
+		return nil, err
+	}
+
+	set := map[string]bool{}
+	fs.Visit(func(f *flag.Flag) {
+		set[f.Name] = true
+	})
+
+	file, err := godotenv.Read(*path)
+	if err != nil && (set["config"] || !errors.Is(err, os.ErrNotExist)) {
+		return nil, fmt.Errorf("couldn't read config file: %w", err)
+	}
+
+	errs := []error{}
+	options.VisitAll(func(f *flag.Flag) {
+		if set[f.Name] {
+			return
+		}
+
+		source := "the environment"
+		value, ok := os.LookupEnv(key(f.Name))
+		if !ok {
+			source = *path
+			value, ok = file[key(f.Name)]
+		}
+
+		if ok && f.Value.Set(value) != nil {
+			errs = append(errs, fmt.Errorf("%s in %s: %q isn't a valid %s", key(f.Name), source, value, kind(f)))
+		}
+	})
+
+	return c, errors.Join(errs...)
+}
+
+// What a value of the flag looks like, for error messages.
+func kind(f *flag.Flag) string {
+	getter, ok := f.Value.(flag.Getter)
+	if !ok {
+		return "value"
+	}
+
+	switch getter.Get().(type) {
+	case int:
+		return "number"
+	case time.Duration:
+		return "duration, like 30s or 5m"
+	default:
+		return "value"
+	}
+}
+
+// Reports every problem with the config at once.
+func (c *Config) Validate() error {
+	errs := []error{}
+	required := func(value, key string) {
+		if value == "" {
+			errs = append(errs, fmt.Errorf("%s is required", key))
+		}
+	}
+
+	port := func(value int, key string) {
+		if value < 1 || value > 65535 {
+			errs = append(errs, fmt.Errorf("%s must be between 1 and 65535, not %d", key, value))
+		}
+	}
+
+	notNegative := func(value int64, key string) {
+		if value < 0 {
+			errs = append(errs, fmt.Errorf("%s can't be negative", key))
+		}
+	}
+
+	required(c.Server.Addr, "LISTEN_ADDR")
+	if (c.Server.TLSCertFile == "") != (c.Server.TLSKeyFile == "") {
+		errs = append(errs, errors.New("TLS_CERT_FILE and TLS_KEY_FILE have to be set together"))
+	}
+
+	for _, file := range []string{c.Server.TLSCertFile, c.Server.TLSKeyFile} {
+		if file == "" {
+			continue
+		}
+
+		if _, err := os.Stat(file); err != nil {
+			errs = append(errs, fmt.Errorf("can't use TLS file: %w", err))
+		}
+	}
+
+	notNegative(int64(c.Server.ReadHeaderTimeout), "READ_HEADER_TIMEOUT")
+	notNegative(int64(c.Server.ReadTimeout), "READ_TIMEOUT")
+	notNegative(int64(c.Server.WriteTimeout), "WRITE_TIMEOUT")
+	notNegative(int64(c.Server.IdleTimeout), "IDLE_TIMEOUT")
+
+	switch c.Database.Driver {
+	case DriverPostgres:
+		postgres := c.Database.Postgres
+		required(postgres.Host, "POSTGRES_HOST")
+		port(postgres.Port, "POSTGRES_PORT")
+		required(postgres.User, "POSTGRES_USER")
+		required(postgres.Name, "POSTGRES_DB")
+		if !slices.Contains(sslModes, postgres.SSLMode) {
+			errs = append(errs, fmt.Errorf("POSTGRES_SSLMODE must be one of %s, not %q", strings.Join(sslModes, ", "), postgres.SSLMode))
+		}
+
+		notNegative(int64(postgres.ConnectTimeout), "POSTGRES_CONNECT_TIMEOUT")
+	case DriverSQLite:
+		required(c.Database.SQLitePath, "SQLITE_PATH")
+	default:
+		errs = append(errs, fmt.Errorf("DATABASE_DRIVER must be %s or %s, not %q", DriverPostgres, DriverSQLite, c.Database.Driver))
+	}
+
+	notNegative(int64(c.Database.MaxOpenConns), "DATABASE_MAX_OPEN_CONNS")
+	notNegative(int64(c.Database.MaxIdleConns), "DATABASE_MAX_IDLE_CONNS")
+	notNegative(int64(c.Database.ConnMaxLifetime), "DATABASE_CONN_MAX_LIFETIME")
+
+	required(c.Redis.Host, "REDIS_HOST")
+	port(c.Redis.Port, "REDIS_PORT")
+	if c.Redis.PoolSize < 1 {
+		errs = append(errs, errors.New("REDIS_POOL_SIZE must be at least 1"))
+	}
+
+	notNegative(int64(c.Redis.DialTimeout), "REDIS_DIAL_TIMEOUT")
+	notNegative(int64(c.Redis.ReadTimeout), "REDIS_READ_TIMEOUT")
+	notNegative(int64(c.Redis.WriteTimeout), "REDIS_WRITE_TIMEOUT")
+
+	return errors.Join(errs...)
+}
+
+// Writes the config in the format of the file it's read from, with
+// secrets redacted.
+func (c *Config) Print(w io.Writer) error {
+	var err error
+	c.flags().VisitAll(func(f *flag.Flag) {
+		value := f.Value.String()
+		if _, ok := f.Value.(secret); ok && value != "" {
+			value = "[redacted]"
+		}
+
+		if strings.ContainsAny(value, " #\"'\\$") {
+			value = strconv.Quote(value)
+		}
+
+		if err == nil {
+			_, err = fmt.Fprintf(w, "%s=%s\n", key(f.Name), value)
+		}
+	})
+
+	return err
+}
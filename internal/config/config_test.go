@@ -0,0 +1,167 @@
+package config_test
+
+import (
+	"flag"
+	"os"
+	"path/filepath"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/marcos-brito/booklist/internal/config"
+	"github.com/stretchr/testify/assert"
+)
+
+// Writes a config file with the lines and returns its path.
+func WriteConfig(t *testing.T, lines ...string) string {
+	path := filepath.Join(t.TempDir(), "booklist.env")
+	err := os.WriteFile(path, []byte(strings.Join(lines, "\n")), 0o600)
+	assert.Nil(t, err)
+
+	return path
+}
+
+func Load(args ...string) (*config.Config, error) {
+	return config.Load(flag.NewFlagSet("test", flag.ContinueOnError), args)
+}
+
+// A config that passes validation, for tests to break.
+func Valid() *config.Config {
+	cfg := config.Default()
+	cfg.Database.Postgres.User = "booklist"
+	cfg.Database.Postgres.Name = "booklist"
+
+	return cfg
+}
+
+func TestLoad(t *testing.T) {
+	t.Run("should use the defaults when nothing is set", func(t *testing.T) {
+		cfg, err := Load()
+
+		assert.Nil(t, err)
+		assert.Equal(t, config.Default(), cfg)
+	})
+
+	t.Run("should let the environment override the file and flags override both", func(t *testing.T) {
+		path := WriteConfig(t,
+			"POSTGRES_HOST=file",
+			"POSTGRES_USER=file",
+			"POSTGRES_DB=file",
+			"REDIS_POOL_SIZE=30",
+		)
+
+		t.Setenv("POSTGRES_USER", "env")
+		t.Setenv("POSTGRES_DB", "env")
+		cfg, err := Load("-config", path, "-postgres-db", "flag", "-read-timeout", "5s")
+
+		assert.Nil(t, err)
+		assert.Equal(t, "file", cfg.Database.Postgres.Host)
+		assert.Equal(t, "env", cfg.Database.Postgres.User)
+		assert.Equal(t, "flag", cfg.Database.Postgres.Name)
+		assert.Equal(t, 30, cfg.Redis.PoolSize)
+		assert.Equal(t, 5*time.Second, cfg.Server.ReadTimeout)
+	})
+
+	t.Run("should fail if the file asked for is missing", func(t *testing.T) {
+		_, err := Load("-config", filepath.Join(t.TempDir(), "missing.env"))
+
+		assert.ErrorIs(t, err, os.ErrNotExist)
+	})
+
+	t.Run("should say which value is wrong and where it came from", func(t *testing.T) {
+		path := WriteConfig(t, "POSTGRES_PORT=five")
+		t.Setenv("IDLE_TIMEOUT", "forever")
+		_, err := Load("-config", path)
+
+		assert.ErrorContains(t, err, `POSTGRES_PORT in `+path+`: "five" isn't a valid number`)
+		assert.ErrorContains(t, err, `IDLE_TIMEOUT in the environment: "forever" isn't a valid duration`)
+	})
+}
+
+func TestValidate(t *testing.T) {
+	t.Run("should accept a complete config", func(t *testing.T) {
+		assert.Nil(t, Valid().Validate())
+	})
+
+	t.Run("should require what has no default", func(t *testing.T) {
+		err := config.Default().Validate()
+
+		assert.ErrorContains(t, err, "POSTGRES_USER is required")
+		assert.ErrorContains(t, err, "POSTGRES_DB is required")
+	})
+
+	t.Run("should only require Postgres settings for Postgres", func(t *testing.T) {
+		cfg := config.Default()
+		cfg.Database.Driver = config.DriverSQLite
+
+		assert.Nil(t, cfg.Validate())
+	})
+
+	t.Run("should only take the sslmodes libpq knows", func(t *testing.T) {
+		cfg := Valid()
+		cfg.Database.Postgres.SSLMode = "enable"
+		assert.ErrorContains(t, cfg.Validate(), `POSTGRES_SSLMODE must be one of disable, allow, prefer, require, verify-ca, verify-full, not "enable"`)
+
+		cfg.Database.Postgres.SSLMode = "verify-full"
+		assert.Nil(t, cfg.Validate())
+	})
+
+	t.Run("should reject an unknown driver", func(t *testing.T) {
+		cfg := Valid()
+		cfg.Database.Driver = "mysql"
+
+		assert.ErrorContains(t, cfg.Validate(), `DATABASE_DRIVER must be postgres or sqlite, not "mysql"`)
+	})
+
+	t.Run("should need both TLS files and for them to exist", func(t *testing.T) {
+		cfg := Valid()
+		cfg.Server.TLSCertFile = WriteConfig(t)
+		assert.ErrorContains(t, cfg.Validate(), "TLS_CERT_FILE and TLS_KEY_FILE have to be set together")
+
+		cfg.Server.TLSKeyFile = filepath.Join(t.TempDir(), "missing.key")
+		assert.ErrorContains(t, cfg.Validate(), "can't use TLS file")
+
+		cfg.Server.TLSKeyFile = WriteConfig(t)
+		assert.Nil(t, cfg.Validate())
+		assert.True(t, cfg.Server.TLS())
+	})
+
+	t.Run("should reject negative pools and timeouts", func(t *testing.T) {
+		cfg := Valid()
+		cfg.Database.MaxOpenConns = -1
+		cfg.Redis.PoolSize = 0
+		cfg.Redis.DialTimeout = -time.Second
+		err := cfg.Validate()
+
+		assert.ErrorContains(t, err, "DATABASE_MAX_OPEN_CONNS can't be negative")
+		assert.ErrorContains(t, err, "REDIS_POOL_SIZE must be at least 1")
+		assert.ErrorContains(t, err, "REDIS_DIAL_TIMEOUT can't be negative")
+	})
+}
+
+func TestPrint(t *testing.T) {
+	cfg := Valid()
+	cfg.Database.Postgres.Password = "hunter2"
+	cfg.Ory.WebhookSecret = "shared secret"
+
+	printed := &strings.Builder{}
+	err := cfg.Print(printed)
+	assert.Nil(t, err)
+
+	t.Run("should redact secrets that are set", func(t *testing.T) {
+		assert.NotContains(t, printed.String(), "hunter2")
+		assert.NotContains(t, printed.String(), "shared secret")
+		assert.Contains(t, printed.String(), "POSTGRES_PASSWORD=[redacted]\n")
+		assert.Contains(t, printed.String(), "REDIS_PASSWORD=\n")
+	})
+
+	t.Run("should print in a format it can read back", func(t *testing.T) {
+		path := WriteConfig(t, printed.String())
+		loaded, err := Load("-config", path)
+		assert.Nil(t, err)
+
+		cfg.Database.Postgres.Password = "[redacted]"
+		cfg.Ory.WebhookSecret = "[redacted]"
+		assert.Equal(t, cfg, loaded)
+	})
+}
@@ -0,0 +1,43 @@
+package conn
+
+import (
+	"fmt"
+
+	"github.com/marcos-brito/booklist/internal/config"
+	"gorm.io/gorm"
+)
+
+// Opens the database chosen in the config with its pool set up.
+func NewConnection(cfg *config.Database) (*gorm.DB, error) {
+	var db *gorm.DB
+	var err error
+
+	switch cfg.Driver {
+	case config.DriverPostgres:
+		db, err = NewPostgresConnection(&cfg.Postgres)
+	case config.DriverSQLite:
+		db, err = NewSQLiteConnection(cfg.SQLitePath)
+	default:
+		err = fmt.Errorf("unknown database driver %q", cfg.Driver)
+	}
+
+	if err != nil {
+		return nil, err
+	}
+
+	// An in-memory database only lives in its one connection
+	if cfg.Driver == config.DriverSQLite && cfg.SQLitePath == ":memory:" {
+		return db, nil
+	}
+
+	sqlDB, err := db.DB()
+	if err != nil {
+		return nil, err
+	}
+
+	sqlDB.SetMaxOpenConns(cfg.MaxOpenConns)
+	sqlDB.SetMaxIdleConns(cfg.MaxIdleConns)
+	sqlDB.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+
+	return db, nil
+}
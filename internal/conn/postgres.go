@@ -2,59 +2,45 @@ package conn
 
 import (
 	"fmt"
-	"os"
+	"strings"
 
-	"github.com/marcos-brito/booklist/internal/models"
+	"github.com/marcos-brito/booklist/internal/config"
 	"gorm.io/driver/postgres"
 	"gorm.io/gorm"
 )
 
-var DB *gorm.DB
-
 type DSN struct {
-	host     string
-	user     string
-	dbname   string
-	password string
-	port     string
-	sslmode  bool
+	host           string
+	user           string
+	dbname         string
+	password       string
+	port           int
+	sslmode        string
+	connectTimeout int
 }
 
 func (dsn *DSN) String() string {
-	var sslmode string
-
-	if dsn.sslmode {
-		sslmode = "enable"
-	} else {
-		sslmode = "disable"
-	}
-
-	return fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%s sslmode=%s",
-		dsn.host, dsn.user, dsn.password, dsn.dbname, dsn.port, sslmode)
-}
-
-func InitDatabase(db *gorm.DB) {
-	DB = db
+	return fmt.Sprintf("host=%s user=%s password=%s dbname=%s port=%d sslmode=%s connect_timeout=%d",
+		quote(dsn.host), quote(dsn.user), quote(dsn.password), quote(dsn.dbname), dsn.port, quote(dsn.sslmode), dsn.connectTimeout)
 }
 
-func Migrate(db *gorm.DB) error {
-	err := db.AutoMigrate(&models.Book{}, &models.Author{}, &models.Publisher{}, &models.Profile{},
-		&models.Settings{}, &models.List{}, &models.CollectionItem{})
-
-	if err != nil {
-		return err
-	}
-
-	return nil
+// Quotes a value for a libpq connection string, so it can have spaces
+// or be empty.
+func quote(value string) string {
+	value = strings.ReplaceAll(value, `\`, `\\`)
+	value = strings.ReplaceAll(value, `'`, `\'`)
+	return "'" + value + "'"
 }
 
-func NewPostgresConnection() (*gorm.DB, error) {
+func NewPostgresConnection(cfg *config.Postgres) (*gorm.DB, error) {
 	dsn := DSN{
-		host:     os.Getenv("POSTGRES_HOST"),
-		user:     os.Getenv("POSTGRES_USER"),
-		dbname:   os.Getenv("POSTGRES_DB"),
-		password: os.Getenv("POSTGRES_PASSWORD"),
-		port:     os.Getenv("POSTGRES_PORT"),
+		host:           cfg.Host,
+		user:           cfg.User,
+		dbname:         cfg.Name,
+		password:       cfg.Password,
+		port:           cfg.Port,
+		sslmode:        cfg.SSLMode,
+		connectTimeout: int(cfg.ConnectTimeout.Seconds()),
 	}
 
 	db, err := gorm.Open(postgres.Open(dsn.String()), &gorm.Config{})
@@ -2,22 +2,18 @@ package conn
 
 import (
 	"fmt"
-	"os"
 
+	"github.com/marcos-brito/booklist/internal/config"
 	"github.com/redis/go-redis/v9"
 )
 
-var Redis *redis.Client
-
-func InitRedis(client *redis.Client) {
-	Redis = client
-}
-
-func NewRedisClient() *redis.Client {
-	host := os.Getenv("REDIS_HOST")
-	port := os.Getenv("REDIS_PORT")
-
+func NewRedisClient(cfg *config.Redis) *redis.Client {
 	return redis.NewClient(&redis.Options{
-		Addr: fmt.Sprintf("%s:%s", host, port),
+		Addr:         fmt.Sprintf("%s:%d", cfg.Host, cfg.Port),
+		Password:     cfg.Password,
+		PoolSize:     cfg.PoolSize,
+		DialTimeout:  cfg.DialTimeout,
+		ReadTimeout:  cfg.ReadTimeout,
+		WriteTimeout: cfg.WriteTimeout,
 	})
 }
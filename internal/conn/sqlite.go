@@ -0,0 +1,41 @@
+package conn
+
+import (
+	"fmt"
+	"net/url"
+
+	"github.com/glebarez/sqlite"
+	"gorm.io/gorm"
+)
+
+// Foreign keys are off by default in SQLite. Writers wait for each other
+// for a while instead of failing right away.
+var sqlitePragmas = []string{
+	"foreign_keys(1)",
+	"busy_timeout(5000)",
+	"journal_mode(WAL)",
+}
+
+// Opens the SQLite database at path, creating it if it doesn't exist.
+// ":memory:" opens a database that lives as long as the connection.
+func NewSQLiteConnection(path string) (*gorm.DB, error) {
+	// Transactions take the write lock up front, otherwise two of them
+	// reading before writing can't both commit
+	query := url.Values{"_pragma": sqlitePragmas, "_txlock": {"immediate"}}
+	db, err := gorm.Open(sqlite.Open(fmt.Sprintf("%s?%s", path, query.Encode())), &gorm.Config{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %s", err)
+	}
+
+	// Every connection to an in-memory database gets a database of its own
+	if path == ":memory:" {
+		sqlDB, err := db.DB()
+		if err != nil {
+			return nil, err
+		}
+
+		sqlDB.SetMaxOpenConns(1)
+	}
+
+	return db, nil
+}
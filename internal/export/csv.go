@@ -0,0 +1,162 @@
+package export
+
+import (
+	"bytes"
+	"encoding/csv"
+	"strconv"
+	"strings"
+	"time"
+)
+
+type table struct {
+	name   string
+	header []string
+	rows   [][]string
+}
+
+func (t *table) csv() ([]byte, error) {
+	buffer := &bytes.Buffer{}
+	writer := csv.NewWriter(buffer)
+	err := writer.Write(t.header)
+	if err != nil {
+		return nil, err
+	}
+
+	err = writer.WriteAll(t.rows)
+	if err != nil {
+		return nil, err
+	}
+
+	return buffer.Bytes(), nil
+}
+
+func (a *Archive) tables() []*table {
+	return []*table{
+		a.collectionTable(),
+		a.listsTable(),
+		a.settingsTable(),
+		a.submittedBooksTable(),
+		a.goodreadsTable(),
+	}
+}
+
+func (a *Archive) collectionTable() *table {
+	t := &table{
+		name: "collection.csv",
+		header: []string{"isbn", "title", "authors", "status", "started_at", "finished_at", "rereads",
+			"progress", "rating", "review", "spoiler", "added_at"},
+	}
+
+	for _, item := range a.Collection {
+		t.rows = append(t.rows, []string{
+			item.Book.ISBN,
+			item.Book.Title,
+			strings.Join(item.Book.Authors, "; "),
+			string(item.Status),
+			formatDate(item.StartedAt),
+			formatDate(item.FinishedAt),
+			strconv.Itoa(item.Rereads),
+			strconv.Itoa(item.Progress),
+			formatFloat(item.Rating),
+			formatString(item.Review),
+			strconv.FormatBool(item.Spoiler),
+			item.AddedAt.Format(time.RFC3339),
+		})
+	}
+
+	return t
+}
+
+// One row for each book in a list. Empty lists get a row without a book,
+// so they aren't lost.
+func (a *Archive) listsTable() *table {
+	t := &table{
+		name:   "lists.csv",
+		header: []string{"list", "description", "published", "created_at", "isbn", "title"},
+	}
+
+	for _, list := range a.Lists {
+		row := []string{list.Name, formatString(list.Description), strconv.FormatBool(list.Published), list.CreatedAt.Format(time.RFC3339)}
+		if len(list.Books) == 0 {
+			t.rows = append(t.rows, append(row, "", ""))
+		}
+
+		for _, book := range list.Books {
+			t.rows = append(t.rows, append(row[:len(row):len(row)], book.ISBN, book.Title))
+		}
+	}
+
+	return t
+}
+
+func (a *Archive) settingsTable() *table {
+	settings := a.Settings
+	return &table{
+		name:   "settings.csv",
+		header: []string{"setting", "value"},
+		rows: [][]string{
+			{"private", strconv.FormatBool(settings.Private)},
+			{"show_name", strconv.FormatBool(settings.ShowName)},
+			{"show_stats", strconv.FormatBool(settings.ShowStats)},
+			{"show_collection", strconv.FormatBool(settings.ShowCollection)},
+			{"show_lists_follows", strconv.FormatBool(settings.ShowListsFollows)},
+			{"show_authors_follows", strconv.FormatBool(settings.ShowAuthorsFollows)},
+		},
+	}
+}
+
+func (a *Archive) submittedBooksTable() *table {
+	t := &table{
+		name: "submitted_books.csv",
+		header: []string{"isbn", "title", "authors", "publisher", "published_at", "page_count", "edition",
+			"approval_state", "rejection_reason"},
+	}
+
+	for _, book := range a.SubmittedBooks {
+		t.rows = append(t.rows, []string{
+			book.ISBN,
+			book.Title,
+			strings.Join(book.Authors, "; "),
+			formatString(book.Publisher),
+			formatDate(book.PublishedAt),
+			formatInt(book.PageCount),
+			formatInt(book.Edition),
+			book.ApprovalState,
+			formatString(book.RejectionReason),
+		})
+	}
+
+	return t
+}
+
+func formatDate(date *time.Time) string {
+	if date == nil {
+		return ""
+	}
+
+	return date.Format(time.DateOnly)
+}
+
+func formatFloat(value *float64) string {
+	if value == nil {
+		return ""
+	}
+
+	return strconv.FormatFloat(*value, 'f', -1, 64)
+}
+
+func formatInt(value *int) string {
+	if value == nil {
+		return ""
+	}
+
+	return strconv.Itoa(*value)
+}
+
+func formatString(value *string) string {
+	if value == nil {
+		return ""
+	}
+
+	return *value
+}
@@ -0,0 +1,249 @@
+package export
+
+import (
+	"archive/tar"
+	"archive/zip"
+	"bytes"
+	"compress/gzip"
+	"encoding/json"
+	"fmt"
+	"io"
+	"time"
+
+	"github.com/marcos-brito/booklist/internal/models"
+)
+
+// How long an export can be downloaded for.
+const TTL = time.Hour
+
+// Everything a user has, in the shape of the JSON dump. Books are
+// identified by ISBN, so the data can be matched again anywhere.
+type Archive struct {
+	ExportedAt     time.Time `json:"exportedAt"`
+	Settings       Settings  `json:"settings"`
+	Collection     []*Item   `json:"collection"`
+	Lists          []*List   `json:"lists"`
+	SubmittedBooks []*Book   `json:"submittedBooks"`
+}
+
+type Settings struct {
+	Private            bool `json:"private"`
+	ShowName           bool `json:"showName"`
+	ShowStats          bool `json:"showStats"`
+	ShowCollection     bool `json:"showCollection"`
+	ShowListsFollows   bool `json:"showListsFollows"`
+	ShowAuthorsFollows bool `json:"showAuthorsFollows"`
+}
+
+type Book struct {
+	ID          uint       `json:"id"`
+	Title       string     `json:"title"`
+	ISBN        string     `json:"isbn"`
+	Authors     []string   `json:"authors"`
+	Publisher   *string    `json:"publisher"`
+	PublishedAt *time.Time `json:"publishedAt"`
+	PageCount   *int       `json:"pageCount"`
+	Edition     *int       `json:"edition"`
+	// Only set for submitted books
+	ApprovalState   string  `json:"approvalState,omitempty"`
+	RejectionReason *string `json:"rejectionReason,omitempty"`
+}
+
+type Item struct {
+	Book       *Book         `json:"book"`
+	Status     models.Status `json:"status"`
+	StartedAt  *time.Time    `json:"startedAt"`
+	FinishedAt *time.Time    `json:"finishedAt"`
+	Rereads    int           `json:"rereads"`
+	Progress   int           `json:"progress"`
+	Rating     *float64      `json:"rating"`
+	Review     *string       `json:"review"`
+	Spoiler    bool          `json:"spoiler"`
+	AddedAt    time.Time     `json:"addedAt"`
+}
+
+type List struct {
+	Name        string    `json:"name"`
+	Description *string   `json:"description"`
+	Published   bool      `json:"published"`
+	Books       []*Book   `json:"books"`
+	CreatedAt   time.Time `json:"createdAt"`
+}
+
+func NewArchive(data *models.ExportData, now time.Time) *Archive {
+	archive := &Archive{
+		ExportedAt: now,
+		Settings: Settings{
+			Private:            data.Settings.Private,
+			ShowName:           data.Settings.ShowName,
+			ShowStats:          data.Settings.ShowStats,
+			ShowCollection:     data.Settings.ShowCollection,
+			ShowListsFollows:   data.Settings.ShowListsFollows,
+			ShowAuthorsFollows: data.Settings.ShowAuthorsFollows,
+		},
+		Collection:     []*Item{},
+		Lists:          []*List{},
+		SubmittedBooks: []*Book{},
+	}
+
+	for _, item := range data.Collection {
+		archive.Collection = append(archive.Collection, &Item{
+			Book:       newBook(&item.Book),
+			Status:     item.Status,
+			StartedAt:  item.StartedAt,
+			FinishedAt: item.FinishedAt,
+			Rereads:    item.Rereads,
+			Progress:   item.Progress,
+			Rating:     item.Rating,
+			Review:     item.Review,
+			Spoiler:    item.Spoiler,
+			AddedAt:    item.CreatedAt,
+		})
+	}
+
+	for _, list := range data.Lists {
+		books := []*Book{}
+		for i := range list.Books {
+			books = append(books, newBook(&list.Books[i]))
+		}
+
+		archive.Lists = append(archive.Lists, &List{
+			Name:        list.Name,
+			Description: list.Description,
+			Published:   list.Published,
+			Books:       books,
+			CreatedAt:   list.CreatedAt,
+		})
+	}
+
+	for _, submitted := range data.SubmittedBooks {
+		book := newBook(submitted)
+		book.ApprovalState = approvalState(submitted)
+		book.RejectionReason = submitted.RejectionReason
+		archive.SubmittedBooks = append(archive.SubmittedBooks, book)
+	}
+
+	return archive
+}
+
+func newBook(book *models.Book) *Book {
+	authors := []string{}
+	for _, author := range book.Authors {
+		authors = append(authors, author.Name)
+	}
+
+	var publisher *string
+	if book.PublisherID != nil {
+		publisher = &book.Publisher.Name
+	}
+
+	return &Book{
+		ID:          book.ID,
+		Title:       book.Title,
+		ISBN:        book.ISBN,
+		Authors:     authors,
+		Publisher:   publisher,
+		PublishedAt: book.PublishedAt,
+		PageCount:   book.PageCount,
+		Edition:     book.Edition,
+	}
+}
+
+func approvalState(book *models.Book) string {
+	switch {
+	case !book.NeedsApproval:
+		return string(models.ApprovalStateApproved)
+	case book.RejectionReason != nil:
+		return string(models.ApprovalStateRejected)
+	default:
+		return string(models.ApprovalStatePending)
+	}
+}
+
+// Name of the downloaded file, with the extension of the format.
+func Filename(format models.ExportFormat, now time.Time) string {
+	extension := "zip"
+	if format == models.ExportFormatTarGz {
+		extension = "tar.gz"
+	}
+
+	return fmt.Sprintf("booklist-%s.%s", now.Format("2006-01-02"), extension)
+}
+
+// Writes the archive with the JSON dump, a CSV for each kind of data
+// and a CSV that can be imported by Goodreads.
+func Write(w io.Writer, format models.ExportFormat, archive *Archive) error {
+	dump, err := json.MarshalIndent(archive, "", "  ")
+	if err != nil {
+		return err
+	}
+
+	files := []*file{{"booklist.json", dump}}
+	for _, table := range archive.tables() {
+		data, err := table.csv()
+		if err != nil {
+			return err
+		}
+
+		files = append(files, &file{table.name, data})
+	}
+
+	switch format {
+	case models.ExportFormatZip:
+		return writeZip(w, files, archive.ExportedAt)
+	case models.ExportFormatTarGz:
+		return writeTarGz(w, files, archive.ExportedAt)
+	default:
+		return fmt.Errorf("unknown format %q", format)
+	}
+}
+
+type file struct {
+	name string
+	data []byte
+}
+
+func writeZip(w io.Writer, files []*file, now time.Time) error {
+	writer := zip.NewWriter(w)
+	for _, file := range files {
+		entry, err := writer.CreateHeader(&zip.FileHeader{Name: file.name, Method: zip.Deflate, Modified: now})
+		if err != nil {
+			return err
+		}
+
+		_, err = entry.Write(file.data)
+		if err != nil {
+			return err
+		}
+	}
+
+	return writer.Close()
+}
+
+func writeTarGz(w io.Writer, files []*file, now time.Time) error {
+	compressed := gzip.NewWriter(w)
+	writer := tar.NewWriter(compressed)
+	for _, file := range files {
+		err := writer.WriteHeader(&tar.Header{
+			Name:    file.name,
+			Mode:    0644,
+			Size:    int64(len(file.data)),
+			ModTime: now,
+		})
+		if err != nil {
+			return err
+		}
+
+		_, err = io.Copy(writer, bytes.NewReader(file.data))
+		if err != nil {
+			return err
+		}
+	}
+
+	err := writer.Close()
+	if err != nil {
+		return err
+	}
+
+	return compressed.Close()
+}
@@ -0,0 +1,128 @@
+package export
+
+import (
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/marcos-brito/booklist/internal/models"
+)
+
+const goodreadsDate = "2006/01/02"
+
+// Goodreads has no statuses besides its default shelves, so the others
+// go to exclusive shelves that it keeps as they are.
+var goodreadsShelves = map[models.Status]string{
+	models.StatusRead:    "read",
+	models.StatusReading: "currently-reading",
+	models.StatusToRead:  "to-read",
+	models.StatusDropped: "did-not-finish",
+	models.StatusOnHold:  "on-hold",
+}
+
+// The library export of Goodreads, with the columns it writes itself.
+// Lists become shelves, and ratings are rounded to whole stars.
+func (a *Archive) goodreadsTable() *table {
+	t := &table{
+		name: "goodreads_library_export.csv",
+		header: []string{"Book Id", "Title", "Author", "Author l-f", "Additional Authors", "ISBN", "ISBN13",
+			"My Rating", "Average Rating", "Publisher", "Binding", "Number of Pages", "Year Published",
+			"Original Publication Year", "Date Read", "Date Added", "Bookshelves", "Bookshelves with positions",
+			"Exclusive Shelf", "My Review", "Spoiler", "Private Notes", "Read Count", "Owned Copies"},
+	}
+
+	shelves := map[uint][]string{}
+	for _, list := range a.Lists {
+		for _, book := range list.Books {
+			shelves[book.ID] = append(shelves[book.ID], goodreadsShelf(list.Name))
+		}
+	}
+
+	for _, item := range a.Collection {
+		author, additional := "", []string{}
+		if len(item.Book.Authors) > 0 {
+			author, additional = item.Book.Authors[0], item.Book.Authors[1:]
+		}
+
+		year := ""
+		if item.Book.PublishedAt != nil {
+			year = strconv.Itoa(item.Book.PublishedAt.Year())
+		}
+
+		rating := 0
+		if item.Rating != nil {
+			rating = int(math.Round(*item.Rating))
+		}
+
+		finished := ""
+		if item.FinishedAt != nil {
+			finished = item.FinishedAt.Format(goodreadsDate)
+		}
+
+		// Goodreads lists the exclusive shelf with the others, unless it's "read"
+		exclusive := goodreadsShelves[item.Status]
+		bookshelves := shelves[item.Book.ID]
+		if item.Status != models.StatusRead {
+			bookshelves = append([]string{exclusive}, bookshelves...)
+		}
+
+		reads := item.Rereads
+		if item.Status == models.StatusRead {
+			reads++
+		}
+
+		t.rows = append(t.rows, []string{
+			strconv.FormatUint(uint64(item.Book.ID), 10),
+			item.Book.Title,
+			author,
+			flipName(author),
+			strings.Join(additional, ", "),
+			`=""`,
+			fmt.Sprintf(`="%s"`, item.Book.ISBN),
+			strconv.Itoa(rating),
+			"",
+			formatString(item.Book.Publisher),
+			"",
+			formatInt(item.Book.PageCount),
+			year,
+			year,
+			finished,
+			item.AddedAt.Format(goodreadsDate),
+			strings.Join(bookshelves, ", "),
+			"",
+			exclusive,
+			strings.ReplaceAll(formatString(item.Review), "\n", "<br/>"),
+			goodreadsSpoiler(item),
+			"",
+			strconv.Itoa(reads),
+			"0",
+		})
+	}
+
+	return t
+}
+
+// Shelves are lowercase, with hyphens for spaces.
+func goodreadsShelf(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), "-")
+}
+
+func goodreadsSpoiler(item *Item) string {
+	if item.Review == nil {
+		return ""
+	}
+
+	return strconv.FormatBool(item.Spoiler)
+}
+
+// Turns "Jane Austen" into "Austen, Jane", taking the last word as the
+// surname.
+func flipName(name string) string {
+	i := strings.LastIndex(name, " ")
+	if i == -1 {
+		return name
+	}
+
+	return name[i+1:] + ", " + name[:i]
+}
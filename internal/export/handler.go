@@ -0,0 +1,67 @@
+package export
+
+import (
+	"bytes"
+	"fmt"
+	"log"
+	"net/http"
+	"time"
+
+	"github.com/marcos-brito/booklist/internal/auth"
+	"github.com/marcos-brito/booklist/internal/models"
+	"github.com/marcos-brito/booklist/internal/store"
+)
+
+var contentTypes = map[models.ExportFormat]string{
+	models.ExportFormatZip:   "application/zip",
+	models.ExportFormatTarGz: "application/gzip",
+}
+
+// Serves the archive of an export to the user who asked for it. The
+// token is taken from the path, so the route has to have a {token}
+// wildcard.
+func Handler(exports store.ExportRepository, users store.UserRepository) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		_, ident, ok := auth.GetSession(request.Context())
+		if !ok {
+			http.Error(writer, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		profile, err := users.FindProfileByUserUuid(ident.UUID)
+		if err != nil {
+			http.Error(writer, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		export, err := exports.FindByToken(request.PathValue("token"))
+		if err != nil || export.ProfileID != profile.ID {
+			http.Error(writer, "Export not found or expired", http.StatusNotFound)
+			return
+		}
+
+		data, err := exports.FindData(profile.ID)
+		if err != nil {
+			log.Printf("couldn't load export %d: %s", export.ID, err)
+			http.Error(writer, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		// Built in memory first, so a failure doesn't send half an archive
+		now := time.Now()
+		archive := &bytes.Buffer{}
+		err = Write(archive, export.Format, NewArchive(data, now))
+		if err != nil {
+			log.Printf("couldn't write export %d: %s", export.ID, err)
+			http.Error(writer, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		writer.Header().Set("Content-Type", contentTypes[export.Format])
+		writer.Header().Set("Content-Disposition", fmt.Sprintf(`attachment; filename="%s"`, Filename(export.Format, now)))
+		_, err = writer.Write(archive.Bytes())
+		if err != nil {
+			log.Printf("couldn't send export %d: %s", export.ID, err)
+		}
+	}
+}
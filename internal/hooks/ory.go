@@ -0,0 +1,55 @@
+package hooks
+
+import (
+	"crypto/subtle"
+	"encoding/json"
+	"log"
+	"net/http"
+
+	"github.com/google/uuid"
+	"github.com/marcos-brito/booklist/internal/store"
+)
+
+// Header with the secret shared with Ory, set in the webhook config.
+const SecretHeader = "X-Webhook-Secret"
+
+type identityDeleted struct {
+	IdentityID string `json:"identity_id"`
+}
+
+// Purges the account of an identity deleted in Ory, so a profile never
+// outlives its identity. Ory has to send a body like
+// {"identity_id": "..."} with the secret in SecretHeader. Without a
+// secret every request is refused.
+func IdentityDeleted(users store.UserRepository, secret string) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		given := request.Header.Get(SecretHeader)
+		if secret == "" || subtle.ConstantTimeCompare([]byte(given), []byte(secret)) != 1 {
+			http.Error(writer, "Unauthorized", http.StatusUnauthorized)
+			return
+		}
+
+		payload := &identityDeleted{}
+		err := json.NewDecoder(request.Body).Decode(payload)
+		if err != nil {
+			http.Error(writer, "Invalid payload", http.StatusBadRequest)
+			return
+		}
+
+		identity, err := uuid.Parse(payload.IdentityID)
+		if err != nil {
+			http.Error(writer, "Invalid identity id", http.StatusBadRequest)
+			return
+		}
+
+		// Ory retries hooks that fail, so errors are safe to return
+		err = users.DeleteAccount(identity)
+		if err != nil {
+			log.Printf("couldn't delete account of identity %s: %s", identity, err)
+			http.Error(writer, "Internal server error", http.StatusInternalServerError)
+			return
+		}
+
+		writer.WriteHeader(http.StatusNoContent)
+	}
+}
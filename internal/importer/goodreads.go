@@ -0,0 +1,65 @@
+package importer
+
+import (
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/marcos-brito/booklist/internal/models"
+)
+
+var goodreadsStatuses = map[string]models.Status{
+	"read":              models.StatusRead,
+	"currently-reading": models.StatusReading,
+	"to-read":           models.StatusToRead,
+}
+
+// Reads the library export of Goodreads. The default shelves map to a
+// status and any other exclusive shelf becomes a list, with a status
+// guessed from its name. The other shelves of a book become lists too.
+func ParseGoodreads(r io.Reader) ([]*Row, error) {
+	required := []string{"Title", "ISBN", "ISBN13", "Exclusive Shelf"}
+	return readTable(r, ',', required, func(line int, field func(string) string) *Row {
+		row := &Row{
+			Line:        line,
+			Title:       field("Title"),
+			ISBNs:       []string{goodreadsIsbn(field("ISBN13")), goodreadsIsbn(field("ISBN"))},
+			Authors:     splitNames(",", field("Author"), field("Additional Authors")),
+			PageCount:   pageCount(field("Number of Pages")),
+			PublishedAt: publishedIn(field("Year Published")),
+			FinishedAt:  parseDate(field("Date Read")),
+			Status:      models.StatusToRead,
+		}
+
+		shelf := field("Exclusive Shelf")
+		if status, ok := goodreadsStatuses[shelf]; ok {
+			row.Status = status
+		} else if shelf != "" {
+			row.addList(shelf)
+		}
+
+		// The exclusive shelf is usually among these too
+		for _, other := range splitNames(",", field("Bookshelves")) {
+			if _, ok := goodreadsStatuses[other]; !ok && other != shelf {
+				row.Lists = append(row.Lists, other)
+			}
+		}
+
+		// Books that weren't rated have a rating of 0
+		if rating, err := strconv.ParseFloat(field("My Rating"), 64); err == nil {
+			row.Rating = halfStars(rating)
+		}
+
+		if review := strings.TrimSpace(strings.ReplaceAll(field("My Review"), "<br/>", "\n")); review != "" {
+			row.Review = &review
+			row.Spoiler = field("Spoiler") == "true"
+		}
+
+		return row
+	})
+}
+
+// Goodreads writes ISBNs as spreadsheet formulas, like ="0439023483".
+func goodreadsIsbn(value string) string {
+	return strings.Trim(value, `="`)
+}
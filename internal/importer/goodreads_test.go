@@ -0,0 +1,91 @@
+package importer_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/marcos-brito/booklist/internal/importer"
+	"github.com/marcos-brito/booklist/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+const goodreadsHeader = "Book Id,Title,Author,Additional Authors,ISBN,ISBN13,My Rating,Number of Pages,Year Published,Date Read,Exclusive Shelf,My Review,Spoiler\n"
+
+func TestParseGoodreads(t *testing.T) {
+	t.Run("should read books from the export", func(t *testing.T) {
+		export := goodreadsHeader +
+			`1,The Hunger Games,Suzanne Collins,"",="0439023483",="9780439023481",4,374,2008,2021/03/14,read,"Great<br/>book",true` + "\n" +
+			`2,Dune,Frank Herbert,"Brian Herbert, Kevin J. Anderson",="",="",0,,1965,,currently-reading,,` + "\n"
+
+		rows, err := importer.ParseGoodreads(strings.NewReader(export))
+		assert.Nil(t, err)
+		assert.Len(t, rows, 2)
+
+		finished := time.Date(2021, time.March, 14, 0, 0, 0, 0, time.UTC)
+		published := time.Date(2008, time.January, 1, 0, 0, 0, 0, time.UTC)
+		first := rows[0]
+		assert.Equal(t, 2, first.Line)
+		assert.Equal(t, "The Hunger Games", first.Title)
+		assert.Equal(t, []string{"9780439023481", "0439023483"}, first.ISBNs)
+		assert.Equal(t, []string{"Suzanne Collins"}, first.Authors)
+		assert.Equal(t, 374, *first.PageCount)
+		assert.Equal(t, published, *first.PublishedAt)
+		assert.Equal(t, models.StatusRead, first.Status)
+		assert.Equal(t, finished, *first.FinishedAt)
+		assert.Equal(t, 4.0, *first.Rating)
+		assert.Equal(t, "Great\nbook", *first.Review)
+		assert.True(t, first.Spoiler)
+
+		second := rows[1]
+		assert.Equal(t, []string{"Frank Herbert", "Brian Herbert", "Kevin J. Anderson"}, second.Authors)
+		assert.Equal(t, models.StatusReading, second.Status)
+		assert.Nil(t, second.PageCount)
+		assert.Nil(t, second.FinishedAt)
+		assert.Nil(t, second.Rating)
+		assert.Nil(t, second.Review)
+	})
+
+	t.Run("should turn other shelves into lists", func(t *testing.T) {
+		export := goodreadsHeader +
+			`1,Ulysses,James Joyce,,,,,,,,did-not-finish,,` + "\n" +
+			`2,Emma,Jane Austen,,,,,,,,classics,,` + "\n"
+
+		rows, err := importer.ParseGoodreads(strings.NewReader(export))
+		assert.Nil(t, err)
+
+		assert.Equal(t, models.StatusDropped, rows[0].Status)
+		assert.Equal(t, []string{"did-not-finish"}, rows[0].Lists)
+		assert.Equal(t, models.StatusToRead, rows[1].Status)
+		assert.Equal(t, []string{"classics"}, rows[1].Lists)
+	})
+
+	t.Run("should turn the rest of the bookshelves into lists", func(t *testing.T) {
+		export := "Title,ISBN,ISBN13,Bookshelves,Exclusive Shelf\n" +
+			`Emma,,,"favorites, classics",read` + "\n" +
+			`Ulysses,,,"did-not-finish, classics",did-not-finish` + "\n" +
+			`Dune,,,"currently-reading, favorites",currently-reading` + "\n"
+
+		rows, err := importer.ParseGoodreads(strings.NewReader(export))
+		assert.Nil(t, err)
+
+		assert.Equal(t, models.StatusRead, rows[0].Status)
+		assert.Equal(t, []string{"favorites", "classics"}, rows[0].Lists)
+		assert.Equal(t, models.StatusDropped, rows[1].Status)
+		assert.Equal(t, []string{"did-not-finish", "classics"}, rows[1].Lists)
+		assert.Equal(t, models.StatusReading, rows[2].Status)
+		assert.Equal(t, []string{"favorites"}, rows[2].Lists)
+	})
+
+	t.Run("should ignore a byte order mark", func(t *testing.T) {
+		rows, err := importer.ParseGoodreads(strings.NewReader("\ufeff" + goodreadsHeader + "1,Emma,,,,,,,,,to-read,,\n"))
+		assert.Nil(t, err)
+		assert.Equal(t, "Emma", rows[0].Title)
+	})
+
+	t.Run("should fail if a required column is missing", func(t *testing.T) {
+		rows, err := importer.ParseGoodreads(strings.NewReader("Title,ISBN,ISBN13\nEmma,,\n"))
+		assert.Nil(t, rows)
+		assert.ErrorContains(t, err, "Exclusive Shelf")
+	})
+}
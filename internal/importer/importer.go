@@ -0,0 +1,359 @@
+package importer
+
+import (
+	"errors"
+	"fmt"
+	"io"
+	"log"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/marcos-brito/booklist/internal/isbn"
+	"github.com/marcos-brito/booklist/internal/models"
+	"github.com/marcos-brito/booklist/internal/store"
+	"gorm.io/gorm"
+)
+
+var (
+	ErrNoIsbn       = errors.New("has no valid ISBN")
+	ErrNoTitle      = errors.New("has no title")
+	ErrBookPending  = errors.New("matches a book waiting for approval")
+	ErrInCollection = errors.New("is already in the collection")
+	ErrInternal     = errors.New("couldn't be imported")
+)
+
+// How many rows are processed between progress updates.
+const progressEvery = 20
+
+// Shelves, or collections, that mean something other than wanting to
+// read a book. Names are compared in lowercase with hyphens for spaces.
+var shelfStatuses = map[string]models.Status{
+	"did-not-finish": models.StatusDropped,
+	"dnf":            models.StatusDropped,
+	"abandoned":      models.StatusDropped,
+	"on-hold":        models.StatusOnHold,
+	"paused":         models.StatusOnHold,
+}
+
+// A book from an export, already in the terms of the collection. ISBNs
+// are tried in order until one of them matches a book.
+type Row struct {
+	Line        int
+	Title       string
+	ISBNs       []string
+	Authors     []string
+	PageCount   *int
+	PublishedAt *time.Time
+	Status      models.Status
+	StartedAt   *time.Time
+	FinishedAt  *time.Time
+	Rating      *float64
+	Review      *string
+	Spoiler     bool
+	Lists       []string
+}
+
+// Reads an export in the given format. Errors are about the export as a
+// whole; problems with single rows are only found when running it.
+func Parse(format models.ImportFormat, r io.Reader) ([]*Row, error) {
+	switch format {
+	case models.ImportFormatGoodreads:
+		return ParseGoodreads(r)
+	case models.ImportFormatStorygraph:
+		return ParseStoryGraph(r)
+	case models.ImportFormatLibrarythingTsv:
+		return ParseLibraryThingTSV(r)
+	case models.ImportFormatLibrarythingJSON:
+		return ParseLibraryThingJSON(r)
+	default:
+		return nil, fmt.Errorf("unknown format %q", format)
+	}
+}
+
+// Runs the rows of import jobs.
+type Runner interface {
+	Run(job *models.ImportJob, profile *models.Profile, rows []*Row) error
+}
+
+// Imports rows straight into the database, each in a transaction.
+type DBRunner struct {
+	db *gorm.DB
+}
+
+func NewDBRunner(db *gorm.DB) *DBRunner {
+	return &DBRunner{db}
+}
+
+// Imports the rows into the collection of the profile, saving the
+// progress of the job as it goes. Each row is imported on its own, so a
+// row that fails is reported and skipped without affecting the others.
+func (dr *DBRunner) Run(job *models.ImportJob, profile *models.Profile, rows []*Row) (err error) {
+	jobs := store.NewImportStore(dr.db)
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			err = fmt.Errorf("import panicked: %v", recovered)
+		}
+
+		if err != nil {
+			log.Printf("import %d failed: %s", job.ID, err)
+			err = errors.Join(err, jobs.Finish(job, models.ImportStatusFailed))
+		}
+	}()
+
+	job.Status = models.ImportStatusRunning
+	err = jobs.SaveProgress(job)
+	if err != nil {
+		return err
+	}
+
+	for _, row := range rows {
+		rowErr := dr.db.Transaction(func(tx *gorm.DB) error {
+			return importRow(tx, profile, row)
+		})
+
+		if rowErr != nil {
+			err = jobs.AddError(job, row.Line, row.Title, message(rowErr))
+			if err != nil {
+				return err
+			}
+		} else {
+			job.Imported++
+		}
+
+		job.Processed++
+		if job.Processed%progressEvery == 0 {
+			err = jobs.SaveProgress(job)
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	return jobs.Finish(job, models.ImportStatusDone)
+}
+
+// Only errors about the row itself are shown to the user.
+func message(err error) string {
+	known := []error{ErrNoIsbn, ErrNoTitle, ErrBookPending, ErrInCollection}
+	for _, target := range known {
+		if errors.Is(err, target) {
+			return fmt.Sprintf("Book %s", target)
+		}
+	}
+
+	log.Printf("couldn't import row: %s", err)
+	return fmt.Sprintf("Book %s", ErrInternal)
+}
+
+func importRow(tx *gorm.DB, profile *models.Profile, row *Row) error {
+	book, err := findOrCreateBook(tx, profile, row)
+	if err != nil {
+		return err
+	}
+
+	userStore := store.NewUserStore(tx)
+	_, err = userStore.FindItemByBook(profile.UUID, book.ID)
+	if err == nil {
+		return ErrInCollection
+	}
+
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return err
+	}
+
+	item, err := userStore.AddToCollection(profile.UUID, book.ID, row.Status, row.Dates(time.Now()))
+	if err != nil {
+		return err
+	}
+
+	if row.Rating != nil {
+		_, err = userStore.RateItem(item.ID, row.Rating)
+		if err != nil {
+			return err
+		}
+	}
+
+	if row.Review != nil {
+		_, err = userStore.ReviewItem(item.ID, row.Review, row.Spoiler)
+		if err != nil {
+			return err
+		}
+	}
+
+	listStore := store.NewListStore(tx)
+	for _, name := range row.Lists {
+		list, err := listStore.FindByName(name, profile.UUID)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			list, err = listStore.Create(name, nil, false, profile.UUID)
+		}
+
+		if err != nil {
+			return err
+		}
+
+		_, err = listStore.AddBook(list.ID, book.ID)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Matches the row to a book by ISBN, or creates one waiting for approval
+// along with any authors that don't exist yet.
+func findOrCreateBook(tx *gorm.DB, profile *models.Profile, row *Row) (*models.Book, error) {
+	isbns := []string{}
+	for _, candidate := range row.ISBNs {
+		normalized, err := isbn.Normalize(candidate)
+		if err == nil {
+			isbns = append(isbns, normalized)
+		}
+	}
+
+	if len(isbns) == 0 {
+		return nil, ErrNoIsbn
+	}
+
+	bookStore := store.NewBookStore(tx)
+	for _, value := range isbns {
+		book, err := bookStore.FindByIsbn(value)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			continue
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		if !book.VisibleTo(profile) {
+			return nil, ErrBookPending
+		}
+
+		return book, nil
+	}
+
+	if row.Title == "" {
+		return nil, ErrNoTitle
+	}
+
+	authorStore := store.NewAuthorStore(tx)
+	authors := []uint{}
+	for _, name := range row.Authors {
+		author, err := authorStore.FindByName(name, profile)
+		if errors.Is(err, gorm.ErrRecordNotFound) {
+			author, err = authorStore.Create(&models.CreateAuthor{Name: name}, profile.UUID)
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		authors = append(authors, author.ID)
+	}
+
+	return bookStore.Create(&models.CreateBook{
+		Title:       row.Title,
+		Isbn:        isbns[0],
+		PublishedAt: row.PublishedAt,
+		PageCount:   row.PageCount,
+		Authors:     authors,
+	}, profile.UUID)
+}
+
+// Returns the dates the row is imported with. Exports can have dates
+// that don't fit the status of the row, like the date a book was last
+// read while it's being reread. Those are dropped instead of failing the
+// row. Books read without a date are left without one.
+func (row *Row) Dates(now time.Time) models.ReadingDates {
+	dates := models.ReadingDates{}
+	if row.StartedAt != nil && row.StartedAt.Before(now) && row.Status != models.StatusToRead {
+		dates.StartedAt = row.StartedAt
+	}
+
+	if row.FinishedAt != nil && row.FinishedAt.Before(now) && row.Status == models.StatusRead {
+		dates.FinishedAt = row.FinishedAt
+	}
+
+	if dates.StartedAt != nil && dates.FinishedAt != nil && dates.FinishedAt.Before(*dates.StartedAt) {
+		dates.StartedAt = nil
+	}
+
+	dates.Undated = row.Status == models.StatusRead && dates.FinishedAt == nil
+	return dates
+}
+
+// Adds the book to a list with the name of a shelf or collection. If
+// the name says something about the status of the book, like
+// "did-not-finish", that becomes its status.
+func (row *Row) addList(name string) {
+	row.Lists = append(row.Lists, name)
+
+	slug := strings.ReplaceAll(strings.ToLower(name), " ", "-")
+	if status, ok := shelfStatuses[slug]; ok {
+		row.Status = status
+	}
+}
+
+// Splits each value by the separator, leaving out empty names.
+func splitNames(sep string, values ...string) []string {
+	names := []string{}
+	for _, value := range values {
+		for _, name := range strings.Split(value, sep) {
+			if name := strings.TrimSpace(name); name != "" {
+				names = append(names, name)
+			}
+		}
+	}
+
+	return names
+}
+
+var dateLayouts = []string{"2006/01/02", "2006-01-02"}
+
+func parseDate(value string) *time.Time {
+	for _, layout := range dateLayouts {
+		date, err := time.Parse(layout, value)
+		if err == nil {
+			return &date
+		}
+	}
+
+	return nil
+}
+
+// Only the year of publication is kept by most exports. Values like
+// "2008" or "2008-05-01" become the first day of the year.
+func publishedIn(value string) *time.Time {
+	year, err := strconv.Atoi(value[:min(len(value), 4)])
+	if err != nil || year <= 0 {
+		return nil
+	}
+
+	published := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	return &published
+}
+
+func pageCount(value string) *int {
+	pages, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil || pages <= 0 {
+		return nil
+	}
+
+	return &pages
+}
+
+// Rounds a rating to the half stars used by the collection. Ratings
+// outside of 0.5 to 5, like the 0 of books that weren't rated, are
+// dropped.
+func halfStars(rating float64) *float64 {
+	rounded := math.Round(rating*2) / 2
+	if rounded < 0.5 || rounded > 5 {
+		return nil
+	}
+
+	return &rounded
+}
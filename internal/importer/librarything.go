@@ -0,0 +1,230 @@
+package importer
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"maps"
+	"slices"
+	"strconv"
+	"strings"
+
+	"github.com/marcos-brito/booklist/internal/models"
+)
+
+// The default collections of LibraryThing. Books in "Your library" can
+// be in any status, so it's the only one without one.
+var libraryThingCollections = map[string]*models.Status{
+	"your library":      nil,
+	"currently reading": statusPtr(models.StatusReading),
+	"read but unowned":  statusPtr(models.StatusRead),
+	"to read":           statusPtr(models.StatusToRead),
+	"wishlist":          statusPtr(models.StatusToRead),
+}
+
+func statusPtr(status models.Status) *models.Status {
+	return &status
+}
+
+// A book as both exports of LibraryThing describe it.
+type libraryThingBook struct {
+	Title       string
+	Authors     []string
+	ISBNs       []string
+	Pages       string
+	Date        string
+	Rating      string
+	Review      string
+	StartedAt   string
+	FinishedAt  string
+	Collections []string
+}
+
+// Reads the tab separated export of LibraryThing.
+func ParseLibraryThingTSV(r io.Reader) ([]*Row, error) {
+	r, err := utf8Reader(r)
+	if err != nil {
+		return nil, err
+	}
+
+	required := []string{"Title", "ISBNs", "Collections"}
+	return readTable(r, '\t', required, func(line int, field func(string) string) *Row {
+		book := &libraryThingBook{
+			Title:       field("Title"),
+			Authors:     []string{},
+			ISBNs:       append(splitNames(",", field("ISBNs")), strings.Trim(field("ISBN"), "[]")),
+			Pages:       field("Page Count"),
+			Date:        field("Date"),
+			Rating:      field("Rating"),
+			Review:      field("Review"),
+			StartedAt:   field("Date Started"),
+			FinishedAt:  field("Date Read"),
+			Collections: splitNames(",", field("Collections")),
+		}
+
+		for _, name := range splitNames("|", field("Primary Author"), field("Secondary Author")) {
+			book.Authors = append(book.Authors, flipName(name))
+		}
+
+		return book.row(line)
+	})
+}
+
+type libraryThingAuthor struct {
+	FL string `json:"fl"`
+}
+
+type libraryThingEntry struct {
+	Title        string            `json:"title"`
+	Authors      []json.RawMessage `json:"authors"`
+	ISBN         json.RawMessage   `json:"isbn"`
+	OriginalISBN string            `json:"originalisbn"`
+	Pages        flexString        `json:"pages"`
+	Date         flexString        `json:"date"`
+	Rating       flexString        `json:"rating"`
+	Review       string            `json:"review"`
+	StartedAt    string            `json:"datestarted"`
+	FinishedAt   string            `json:"datefinished"`
+	Collections  []string          `json:"collections"`
+}
+
+// Reads the JSON export of LibraryThing, an object with the books by
+// their id. There are no lines, so rows are numbered in the order of the
+// export.
+func ParseLibraryThingJSON(r io.Reader) ([]*Row, error) {
+	decoder := json.NewDecoder(r)
+	token, err := decoder.Token()
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read export: %w", err)
+	}
+
+	if token != json.Delim('{') {
+		return nil, fmt.Errorf("export must be an object of books")
+	}
+
+	rows := []*Row{}
+	for decoder.More() {
+		_, err := decoder.Token()
+		if err != nil {
+			return nil, err
+		}
+
+		entry := &libraryThingEntry{}
+		err = decoder.Decode(entry)
+		if err != nil {
+			return nil, fmt.Errorf("book %d: %w", len(rows)+1, err)
+		}
+
+		book := &libraryThingBook{
+			Title:       entry.Title,
+			ISBNs:       append(libraryThingIsbns(entry.ISBN), entry.OriginalISBN),
+			Pages:       string(entry.Pages),
+			Date:        string(entry.Date),
+			Rating:      string(entry.Rating),
+			Review:      entry.Review,
+			StartedAt:   entry.StartedAt,
+			FinishedAt:  entry.FinishedAt,
+			Collections: entry.Collections,
+		}
+
+		// Books without authors may have an empty array in place of one
+		for _, raw := range entry.Authors {
+			author := &libraryThingAuthor{}
+			if json.Unmarshal(raw, author) == nil && strings.TrimSpace(author.FL) != "" {
+				book.Authors = append(book.Authors, strings.TrimSpace(author.FL))
+			}
+		}
+
+		rows = append(rows, book.row(len(rows)+1))
+	}
+
+	return rows, nil
+}
+
+func (book *libraryThingBook) row(line int) *Row {
+	row := &Row{
+		Line:        line,
+		Title:       strings.TrimSpace(book.Title),
+		ISBNs:       book.ISBNs,
+		Authors:     book.Authors,
+		PageCount:   pageCount(book.Pages),
+		PublishedAt: publishedIn(strings.TrimSpace(book.Date)),
+		StartedAt:   parseDate(strings.TrimSpace(book.StartedAt)),
+		FinishedAt:  parseDate(strings.TrimSpace(book.FinishedAt)),
+		Status:      models.StatusToRead,
+	}
+
+	if row.FinishedAt != nil {
+		row.Status = models.StatusRead
+	}
+
+	for _, name := range book.Collections {
+		name = strings.TrimSpace(name)
+		if status, ok := libraryThingCollections[strings.ToLower(name)]; ok {
+			if status != nil {
+				row.Status = *status
+			}
+		} else if name != "" {
+			row.addList(name)
+		}
+	}
+
+	if rating, err := strconv.ParseFloat(strings.TrimSpace(book.Rating), 64); err == nil {
+		row.Rating = halfStars(rating)
+	}
+
+	if review := strings.TrimSpace(book.Review); review != "" {
+		row.Review = &review
+	}
+
+	return row
+}
+
+// ISBNs come as an array, or as an object keyed by their position.
+func libraryThingIsbns(raw json.RawMessage) []string {
+	list := []string{}
+	if json.Unmarshal(raw, &list) == nil {
+		return list
+	}
+
+	object := map[string]string{}
+	if json.Unmarshal(raw, &object) != nil {
+		return nil
+	}
+
+	for _, key := range slices.Sorted(maps.Keys(object)) {
+		list = append(list, object[key])
+	}
+
+	return list
+}
+
+// Authors are written as "Last, First" in the tab separated export.
+func flipName(name string) string {
+	parts := strings.Split(name, ",")
+	if len(parts) != 2 {
+		return name
+	}
+
+	return strings.TrimSpace(parts[1]) + " " + strings.TrimSpace(parts[0])
+}
+
+// A value that is a string in some books and a number in others.
+type flexString string
+
+func (value *flexString) UnmarshalJSON(data []byte) error {
+	var text string
+	if json.Unmarshal(data, &text) == nil {
+		*value = flexString(text)
+		return nil
+	}
+
+	var number json.Number
+	err := json.Unmarshal(data, &number)
+	if err != nil {
+		return err
+	}
+
+	*value = flexString(number)
+	return nil
+}
@@ -0,0 +1,107 @@
+package importer_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+	"unicode/utf16"
+
+	"github.com/marcos-brito/booklist/internal/importer"
+	"github.com/marcos-brito/booklist/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+const libraryThingTSV = "Book Id\tTitle\tPrimary Author\tSecondary Author\tDate\tReview\tRating\tPage Count\tDate Started\tDate Read\tCollections\tISBN\tISBNs\n" +
+	"1\tThe Hobbit\tTolkien, J. R. R.\t\t1937\tA classic\t4.5\t310\t2020-01-01\t2020-01-20\tYour library, Fantasy\t[0261102214]\t0261102214, 9780261102217\n" +
+	"2\tDracula\tStoker, Bram\t\t1897\t\t\t\t2021-03-01\t\tCurrently reading\t\t9780141439846\n" +
+	"3\tUlysses\tJoyce, James\t\t1922\t\t\t\t\t\tDid not finish\t\t9780141182803\n"
+
+func TestParseLibraryThingTSV(t *testing.T) {
+	t.Run("should read books from the export", func(t *testing.T) {
+		rows, err := importer.ParseLibraryThingTSV(strings.NewReader(libraryThingTSV))
+		assert.Nil(t, err)
+		assert.Len(t, rows, 3)
+
+		first := rows[0]
+		assert.Equal(t, "The Hobbit", first.Title)
+		assert.Equal(t, []string{"J. R. R. Tolkien"}, first.Authors)
+		assert.Equal(t, []string{"0261102214", "9780261102217", "0261102214"}, first.ISBNs)
+		assert.Equal(t, 310, *first.PageCount)
+		assert.Equal(t, models.StatusRead, first.Status)
+		assert.Equal(t, time.Date(2020, time.January, 20, 0, 0, 0, 0, time.UTC), *first.FinishedAt)
+		assert.Equal(t, 4.5, *first.Rating)
+		assert.Equal(t, "A classic", *first.Review)
+		assert.Equal(t, []string{"Fantasy"}, first.Lists)
+
+		assert.Equal(t, models.StatusReading, rows[1].Status)
+		assert.Empty(t, rows[1].Lists)
+		assert.Equal(t, models.StatusDropped, rows[2].Status)
+		assert.Equal(t, []string{"Did not finish"}, rows[2].Lists)
+	})
+
+	t.Run("should read UTF-16 exports", func(t *testing.T) {
+		units := utf16.Encode([]rune(libraryThingTSV))
+		export := []byte{0xff, 0xfe}
+		for _, unit := range units {
+			export = append(export, byte(unit), byte(unit>>8))
+		}
+
+		rows, err := importer.ParseLibraryThingTSV(strings.NewReader(string(export)))
+		assert.Nil(t, err)
+		assert.Len(t, rows, 3)
+		assert.Equal(t, "The Hobbit", rows[0].Title)
+	})
+
+	t.Run("should fail if a required column is missing", func(t *testing.T) {
+		rows, err := importer.ParseLibraryThingTSV(strings.NewReader("Title\tISBNs\nDracula\t9780141439846\n"))
+		assert.Nil(t, rows)
+		assert.ErrorContains(t, err, "Collections")
+	})
+}
+
+func TestParseLibraryThingJSON(t *testing.T) {
+	t.Run("should read books from the export", func(t *testing.T) {
+		export := `{
+			"20": {
+				"title": "The Hobbit",
+				"authors": [{"lf": "Tolkien, J. R. R.", "fl": "J. R. R. Tolkien"}],
+				"date": "1937",
+				"isbn": {"0": "0261102214", "2": "9780261102217"},
+				"pages": "310 ",
+				"rating": 5,
+				"datefinished": "2020-01-20",
+				"collections": ["Read but unowned", "Favorites"]
+			},
+			"10": {
+				"title": "Anonymous",
+				"authors": [[]],
+				"isbn": ["9780141439846"],
+				"collections": ["To read"]
+			}
+		}`
+
+		rows, err := importer.ParseLibraryThingJSON(strings.NewReader(export))
+		assert.Nil(t, err)
+		assert.Len(t, rows, 2)
+
+		first := rows[0]
+		assert.Equal(t, 1, first.Line)
+		assert.Equal(t, []string{"J. R. R. Tolkien"}, first.Authors)
+		assert.Equal(t, []string{"0261102214", "9780261102217", ""}, first.ISBNs)
+		assert.Equal(t, 310, *first.PageCount)
+		assert.Equal(t, 5.0, *first.Rating)
+		assert.Equal(t, models.StatusRead, first.Status)
+		assert.Equal(t, []string{"Favorites"}, first.Lists)
+
+		second := rows[1]
+		assert.Equal(t, 2, second.Line)
+		assert.Empty(t, second.Authors)
+		assert.Equal(t, models.StatusToRead, second.Status)
+	})
+
+	t.Run("should fail if the export isn't an object", func(t *testing.T) {
+		rows, err := importer.ParseLibraryThingJSON(strings.NewReader(`[{"title": "Dracula"}]`))
+		assert.Nil(t, rows)
+		assert.Error(t, err)
+	})
+}
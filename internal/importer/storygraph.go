@@ -0,0 +1,58 @@
+package importer
+
+import (
+	"io"
+	"strconv"
+	"strings"
+
+	"github.com/marcos-brito/booklist/internal/models"
+)
+
+var storyGraphStatuses = map[string]models.Status{
+	"read":              models.StatusRead,
+	"currently-reading": models.StatusReading,
+	"to-read":           models.StatusToRead,
+	"did-not-finish":    models.StatusDropped,
+	"paused":            models.StatusOnHold,
+}
+
+// Reads the library export of StoryGraph. Tags become lists, and the
+// dates come from the last time the book was read.
+func ParseStoryGraph(r io.Reader) ([]*Row, error) {
+	required := []string{"Title", "ISBN/UID", "Read Status"}
+	return readTable(r, ',', required, func(line int, field func(string) string) *Row {
+		row := &Row{
+			Line:       line,
+			Title:      field("Title"),
+			ISBNs:      []string{field("ISBN/UID")},
+			Authors:    splitNames(",", field("Authors")),
+			FinishedAt: parseDate(field("Last Date Read")),
+			Status:     models.StatusToRead,
+		}
+
+		if status, ok := storyGraphStatuses[field("Read Status")]; ok {
+			row.Status = status
+		}
+
+		// Read-throughs are written like "2023/01/01-2023/01/15, 2024/02/01-"
+		reads := strings.Split(field("Dates Read"), ",")
+		if dates := strings.SplitN(strings.TrimSpace(reads[len(reads)-1]), "-", 2); len(dates) == 2 {
+			row.StartedAt = parseDate(strings.TrimSpace(dates[0]))
+			if finished := parseDate(strings.TrimSpace(dates[1])); finished != nil {
+				row.FinishedAt = finished
+			}
+		}
+
+		// Ratings can be in quarter stars, like 3.75
+		if rating, err := strconv.ParseFloat(field("Star Rating"), 64); err == nil {
+			row.Rating = halfStars(rating)
+		}
+
+		if review := field("Review"); review != "" {
+			row.Review = &review
+		}
+
+		row.Lists = splitNames(",", field("Tags"))
+		return row
+	})
+}
@@ -0,0 +1,55 @@
+package importer_test
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/marcos-brito/booklist/internal/importer"
+	"github.com/marcos-brito/booklist/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+const storyGraphHeader = "Title,Authors,Contributors,ISBN/UID,Format,Read Status,Date Added,Last Date Read,Dates Read,Read Count,Star Rating,Review,Tags,Owned?\n"
+
+func TestParseStoryGraph(t *testing.T) {
+	t.Run("should read books from the export", func(t *testing.T) {
+		export := storyGraphHeader +
+			`Piranesi,Susanna Clarke,,9781635575637,hardcover,read,2023/01/01,2023/02/10,"2022/05/01-2022/05/20, 2023/02/01-2023/02/10",2,3.75,Loved it,"fantasy, favorites",Yes` + "\n" +
+			`"Good Omens","Terry Pratchett, Neil Gaiman",,9780060853983,paperback,did-not-finish,2023/01/01,,,0,,,,No` + "\n" +
+			`Middlemarch,George Eliot,,9780141439549,paperback,paused,2023/01/01,,2023/03/01-,0,,,,No` + "\n"
+
+		rows, err := importer.ParseStoryGraph(strings.NewReader(export))
+		assert.Nil(t, err)
+		assert.Len(t, rows, 3)
+
+		started := time.Date(2023, time.February, 1, 0, 0, 0, 0, time.UTC)
+		finished := time.Date(2023, time.February, 10, 0, 0, 0, 0, time.UTC)
+		first := rows[0]
+		assert.Equal(t, "Piranesi", first.Title)
+		assert.Equal(t, []string{"9781635575637"}, first.ISBNs)
+		assert.Equal(t, models.StatusRead, first.Status)
+		assert.Equal(t, started, *first.StartedAt)
+		assert.Equal(t, finished, *first.FinishedAt)
+		assert.Equal(t, 4.0, *first.Rating)
+		assert.Equal(t, "Loved it", *first.Review)
+		assert.Equal(t, []string{"fantasy", "favorites"}, first.Lists)
+
+		second := rows[1]
+		assert.Equal(t, []string{"Terry Pratchett", "Neil Gaiman"}, second.Authors)
+		assert.Equal(t, models.StatusDropped, second.Status)
+		assert.Nil(t, second.Rating)
+		assert.Empty(t, second.Lists)
+
+		third := rows[2]
+		assert.Equal(t, models.StatusOnHold, third.Status)
+		assert.Equal(t, time.Date(2023, time.March, 1, 0, 0, 0, 0, time.UTC), *third.StartedAt)
+		assert.Nil(t, third.FinishedAt)
+	})
+
+	t.Run("should fail if a required column is missing", func(t *testing.T) {
+		rows, err := importer.ParseStoryGraph(strings.NewReader("Title,Authors\nPiranesi,Susanna Clarke\n"))
+		assert.Nil(t, rows)
+		assert.ErrorContains(t, err, "ISBN/UID")
+	})
+}
@@ -0,0 +1,107 @@
+package importer
+
+import (
+	"bytes"
+	"encoding/csv"
+	"errors"
+	"fmt"
+	"io"
+	"strings"
+	"unicode/utf16"
+)
+
+// Reads a delimited export with a header, turning each record into a row
+// with the given function. The function gets the line of the record and
+// a way to get its fields by column name.
+func readTable(r io.Reader, comma rune, required []string, parse func(line int, field func(string) string) *Row) ([]*Row, error) {
+	reader := csv.NewReader(r)
+	reader.Comma = comma
+	reader.FieldsPerRecord = -1
+	reader.LazyQuotes = true
+
+	header, err := reader.Read()
+	if err != nil {
+		return nil, fmt.Errorf("couldn't read header: %w", err)
+	}
+
+	columns, err := indexColumns(header, required...)
+	if err != nil {
+		return nil, err
+	}
+
+	rows := []*Row{}
+	for {
+		record, err := reader.Read()
+		if errors.Is(err, io.EOF) {
+			break
+		}
+
+		if err != nil {
+			return nil, err
+		}
+
+		line, _ := reader.FieldPos(0)
+		rows = append(rows, parse(line, columns.reader(record)))
+	}
+
+	return rows, nil
+}
+
+type columnIndex map[string]int
+
+// Maps the names in the header to their positions. Every one of the
+// required columns has to be there.
+func indexColumns(header []string, required ...string) (columnIndex, error) {
+	columns := columnIndex{}
+	// Exports saved by spreadsheets may start with a byte order mark
+	for i, name := range header {
+		columns[strings.TrimSpace(strings.TrimPrefix(name, "\ufeff"))] = i
+	}
+
+	for _, name := range required {
+		if _, ok := columns[name]; !ok {
+			return nil, fmt.Errorf("missing column %q", name)
+		}
+	}
+
+	return columns, nil
+}
+
+// Returns a function that gets the value of a column in the record, or
+// an empty string if there is no such column.
+func (columns columnIndex) reader(record []string) func(string) string {
+	return func(name string) string {
+		i, ok := columns[name]
+		if !ok || i >= len(record) {
+			return ""
+		}
+
+		return strings.TrimSpace(record[i])
+	}
+}
+
+// Some exports, like the older ones of LibraryThing, are UTF-16 with a
+// byte order mark. Anything else is read as it is.
+func utf8Reader(r io.Reader) (io.Reader, error) {
+	data, err := io.ReadAll(r)
+	if err != nil {
+		return nil, err
+	}
+
+	var order func([]byte) uint16
+	switch {
+	case bytes.HasPrefix(data, []byte{0xff, 0xfe}):
+		order = func(b []byte) uint16 { return uint16(b[0]) | uint16(b[1])<<8 }
+	case bytes.HasPrefix(data, []byte{0xfe, 0xff}):
+		order = func(b []byte) uint16 { return uint16(b[1]) | uint16(b[0])<<8 }
+	default:
+		return bytes.NewReader(data), nil
+	}
+
+	units := []uint16{}
+	for i := 2; i+1 < len(data); i += 2 {
+		units = append(units, order(data[i:i+2]))
+	}
+
+	return strings.NewReader(string(utf16.Decode(units))), nil
+}
@@ -0,0 +1,91 @@
+package isbn
+
+import (
+	"errors"
+	"strings"
+)
+
+var (
+	ErrLength    = errors.New("must have 10 or 13 digits")
+	ErrCharacter = errors.New("must contain only digits, hyphens and spaces")
+	ErrChecksum  = errors.New("has an invalid check digit")
+)
+
+// Validates an ISBN-10 or ISBN-13 and returns it as an ISBN-13 without
+// hyphens or spaces.
+func Normalize(isbn string) (string, error) {
+	digits := strings.Map(func(r rune) rune {
+		if r == '-' || r == ' ' {
+			return -1
+		}
+
+		if r == 'x' {
+			return 'X'
+		}
+
+		return r
+	}, strings.TrimSpace(isbn))
+
+	switch len(digits) {
+	case 10:
+		return normalize10(digits)
+	case 13:
+		return normalize13(digits)
+	default:
+		return "", ErrLength
+	}
+}
+
+func normalize10(digits string) (string, error) {
+	sum := 0
+	for i, r := range digits {
+		var value int
+
+		switch {
+		case r >= '0' && r <= '9':
+			value = int(r - '0')
+		case r == 'X' && i == 9:
+			value = 10
+		default:
+			return "", ErrCharacter
+		}
+
+		sum += value * (10 - i)
+	}
+
+	if sum%11 != 0 {
+		return "", ErrChecksum
+	}
+
+	body := "978" + digits[:9]
+	return body + string(checkDigit13(body)), nil
+}
+
+func normalize13(digits string) (string, error) {
+	for _, r := range digits {
+		if r < '0' || r > '9' {
+			return "", ErrCharacter
+		}
+	}
+
+	if checkDigit13(digits[:12]) != rune(digits[12]) {
+		return "", ErrChecksum
+	}
+
+	return digits, nil
+}
+
+// Returns the check digit for the first 12 digits of an ISBN-13.
+func checkDigit13(body string) rune {
+	sum := 0
+	for i, r := range body {
+		weight := 1
+		if i%2 == 1 {
+			weight = 3
+		}
+
+		sum += int(r-'0') * weight
+	}
+
+	return rune('0' + (10-sum%10)%10)
+}
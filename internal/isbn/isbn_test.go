@@ -0,0 +1,59 @@
+package isbn_test
+
+import (
+	"testing"
+
+	"github.com/marcos-brito/booklist/internal/isbn"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNormalize(t *testing.T) {
+	t.Run("should keep valid isbn-13", func(t *testing.T) {
+		got, err := isbn.Normalize("9788551002933")
+
+		assert.Nil(t, err)
+		assert.Equal(t, "9788551002933", got)
+	})
+
+	t.Run("should strip hyphens and spaces", func(t *testing.T) {
+		got, err := isbn.Normalize(" 978-85-510-0293 3 ")
+
+		assert.Nil(t, err)
+		assert.Equal(t, "9788551002933", got)
+	})
+
+	t.Run("should convert isbn-10 to isbn-13", func(t *testing.T) {
+		got, err := isbn.Normalize("0-306-40615-2")
+
+		assert.Nil(t, err)
+		assert.Equal(t, "9780306406157", got)
+	})
+
+	t.Run("should accept X as isbn-10 check digit", func(t *testing.T) {
+		got, err := isbn.Normalize("080442957x")
+
+		assert.Nil(t, err)
+		assert.Equal(t, "9780804429573", got)
+	})
+
+	t.Run("should fail if check digit is wrong", func(t *testing.T) {
+		_, err := isbn.Normalize("9788551002934")
+		assert.ErrorIs(t, err, isbn.ErrChecksum)
+
+		_, err = isbn.Normalize("0306406153")
+		assert.ErrorIs(t, err, isbn.ErrChecksum)
+	})
+
+	t.Run("should fail if length is wrong", func(t *testing.T) {
+		_, err := isbn.Normalize("978855100293")
+		assert.ErrorIs(t, err, isbn.ErrLength)
+	})
+
+	t.Run("should fail if there are invalid characters", func(t *testing.T) {
+		_, err := isbn.Normalize("97885510A2933")
+		assert.ErrorIs(t, err, isbn.ErrCharacter)
+
+		_, err = isbn.Normalize("03064X6152")
+		assert.ErrorIs(t, err, isbn.ErrCharacter)
+	})
+}
@@ -0,0 +1,119 @@
+package loaders
+
+import (
+	"context"
+	"sync"
+	"time"
+)
+
+// Keys loaded at once at most, so the IN lists stay reasonable.
+const maxBatch = 500
+
+// Collects the keys loaded within a short wait into a single fetch, and
+// caches what was fetched. Keys the fetch leaves out load as the zero
+// value.
+type Loader[K comparable, V any] struct {
+	fetch func(keys []K) (map[K]V, error)
+	wait  time.Duration
+
+	mu    sync.Mutex
+	cache map[K]*result[V]
+	batch *batch[K, V]
+}
+
+type batch[K comparable, V any] struct {
+	results    map[K]*result[V]
+	dispatched bool
+}
+
+type result[V any] struct {
+	done  chan struct{}
+	value V
+	err   error
+}
+
+func NewLoader[K comparable, V any](wait time.Duration, fetch func(keys []K) (map[K]V, error)) *Loader[K, V] {
+	return &Loader[K, V]{
+		fetch: fetch,
+		wait:  wait,
+		cache: map[K]*result[V]{},
+	}
+}
+
+func (l *Loader[K, V]) Load(ctx context.Context, key K) (V, error) {
+	l.mu.Lock()
+	r, ok := l.cache[key]
+	if !ok {
+		r = &result[V]{done: make(chan struct{})}
+		l.cache[key] = r
+		l.enqueue(key, r)
+	}
+	l.mu.Unlock()
+
+	select {
+	case <-r.done:
+		return r.value, r.err
+	case <-ctx.Done():
+		var zero V
+		return zero, ctx.Err()
+	}
+}
+
+// Caches the value for the key, replacing what was loaded for it.
+func (l *Loader[K, V]) Prime(key K, value V) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	r := &result[V]{done: make(chan struct{}), value: value}
+	close(r.done)
+	l.cache[key] = r
+}
+
+// Adds the key to the batch waiting to be fetched, starting a new one if
+// there's none. Must be called with the lock held.
+func (l *Loader[K, V]) enqueue(key K, r *result[V]) {
+	if l.batch == nil {
+		b := &batch[K, V]{results: map[K]*result[V]{}}
+		l.batch = b
+		time.AfterFunc(l.wait, func() { l.dispatch(b) })
+	}
+
+	l.batch.results[key] = r
+	if len(l.batch.results) >= maxBatch {
+		go l.dispatch(l.batch)
+		l.batch = nil
+	}
+}
+
+// Fetches the batch, unless it's been fetched already because it got
+// full before the wait was over.
+func (l *Loader[K, V]) dispatch(b *batch[K, V]) {
+	l.mu.Lock()
+	if l.batch == b {
+		l.batch = nil
+	}
+
+	if b.dispatched {
+		l.mu.Unlock()
+		return
+	}
+
+	b.dispatched = true
+	l.mu.Unlock()
+
+	keys := make([]K, 0, len(b.results))
+	for key := range b.results {
+		keys = append(keys, key)
+	}
+
+	values, err := l.fetch(keys)
+	for key, r := range b.results {
+		if err != nil {
+			r.err = err
+		} else {
+			r.value = values[key]
+		}
+
+		close(r.done)
+	}
+}
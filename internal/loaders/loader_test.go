@@ -0,0 +1,67 @@
+package loaders_test
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/marcos-brito/booklist/internal/loaders"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestLoader(t *testing.T) {
+	var mu sync.Mutex
+	fetched := [][]int{}
+	loader := loaders.NewLoader(10*time.Millisecond, func(keys []int) (map[int]string, error) {
+		mu.Lock()
+		defer mu.Unlock()
+
+		sorted := slices.Clone(keys)
+		slices.Sort(sorted)
+		fetched = append(fetched, sorted)
+
+		values := map[int]string{}
+		for _, key := range keys {
+			if key != 0 {
+				values[key] = string(rune('a' + key - 1))
+			}
+		}
+
+		return values, nil
+	})
+
+	t.Run("should fetch keys loaded together at once", func(t *testing.T) {
+		var wg sync.WaitGroup
+		got := make([]string, 4)
+		for i := range got {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				value, err := loader.Load(context.Background(), i)
+				assert.Nil(t, err)
+				got[i] = value
+			}()
+		}
+
+		wg.Wait()
+		assert.Equal(t, []string{"", "a", "b", "c"}, got)
+		assert.Equal(t, [][]int{{0, 1, 2, 3}}, fetched)
+	})
+
+	t.Run("should not fetch keys again", func(t *testing.T) {
+		value, err := loader.Load(context.Background(), 2)
+		assert.Nil(t, err)
+		assert.Equal(t, "b", value)
+		assert.Len(t, fetched, 1)
+	})
+
+	t.Run("should use primed values", func(t *testing.T) {
+		loader.Prime(0, "primed")
+		value, err := loader.Load(context.Background(), 0)
+		assert.Nil(t, err)
+		assert.Equal(t, "primed", value)
+		assert.Len(t, fetched, 1)
+	})
+}
@@ -0,0 +1,93 @@
+package loaders
+
+import (
+	"context"
+	"net/http"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/marcos-brito/booklist/internal/models"
+	"github.com/marcos-brito/booklist/internal/store"
+)
+
+// How long loaders wait for more keys before fetching. Resolvers for the
+// objects in a list all start at about the same time, so this only has to
+// cover the time it takes to start them.
+const Wait = 2 * time.Millisecond
+
+type LoadersContextKey string
+
+const loaders_context_key LoadersContextKey = "req.loaders"
+
+// The loaders for what resolvers look up for each object in a list. They
+// load everything regardless of who can see it, so resolvers still have
+// to check visibility.
+type Loaders struct {
+	Books          *Loader[uint, *models.Book]
+	Publishers     *Loader[uint, *models.Publisher]
+	Profiles       *Loader[uint, *models.Profile]
+	ProfilesByUuid *Loader[uuid.UUID, *models.Profile]
+	// By profile ID
+	Settings *Loader[uint, *models.Settings]
+	// By book ID
+	BookAuthors *Loader[uint, []*models.Author]
+	// By list ID
+	ListBooks *Loader[uint, []*models.Book]
+}
+
+func New(repos *store.Repositories, wait time.Duration) *Loaders {
+	return &Loaders{
+		Books: NewLoader(wait, keyed(repos.Books.FindByIds, func(book *models.Book) uint {
+			return book.ID
+		})),
+		Publishers: NewLoader(wait, keyed(repos.Publishers.FindByIds, func(publisher *models.Publisher) uint {
+			return publisher.ID
+		})),
+		Profiles: NewLoader(wait, keyed(repos.Users.FindProfilesByIds, func(profile *models.Profile) uint {
+			return profile.ID
+		})),
+		ProfilesByUuid: NewLoader(wait, keyed(repos.Users.FindProfilesByUserUuids, func(profile *models.Profile) uuid.UUID {
+			return profile.UUID
+		})),
+		Settings: NewLoader(wait, keyed(repos.Users.FindSettingsByProfileIds, func(settings *models.Settings) uint {
+			return settings.ProfileID
+		})),
+		BookAuthors: NewLoader(wait, repos.Books.FindAuthorsByBooks),
+		ListBooks:   NewLoader(wait, repos.Lists.FindBooksByLists),
+	}
+}
+
+// Turns a lookup of many rows into a fetch that keys them.
+func keyed[K comparable, V any](find func([]K) ([]V, error), key func(V) K) func([]K) (map[K]V, error) {
+	return func(keys []K) (map[K]V, error) {
+		rows, err := find(keys)
+		if err != nil {
+			return nil, err
+		}
+
+		keyed := make(map[K]V, len(rows))
+		for _, row := range rows {
+			keyed[key(row)] = row
+		}
+
+		return keyed, nil
+	}
+}
+
+// Gives each request its own loaders, so nothing is cached for longer
+// than a request.
+func Middleware(next http.Handler, repos *store.Repositories) http.HandlerFunc {
+	return func(writer http.ResponseWriter, request *http.Request) {
+		ctx := AddLoadersToContext(request.Context(), New(repos, Wait))
+		next.ServeHTTP(writer, request.WithContext(ctx))
+	}
+}
+
+func AddLoadersToContext(ctx context.Context, loaders *Loaders) context.Context {
+	return context.WithValue(ctx, loaders_context_key, loaders)
+}
+
+func GetLoaders(ctx context.Context) (*Loaders, bool) {
+	loaders, ok := ctx.Value(loaders_context_key).(*Loaders)
+	return loaders, ok
+}
@@ -0,0 +1,77 @@
+package migrate_test
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/marcos-brito/booklist/internal/models"
+	"gorm.io/gorm"
+)
+
+// The models as they were in the last release that used AutoMigrate,
+// to build the schema databases are adopted from.
+
+type Profile struct {
+	gorm.Model
+	UUID       uuid.UUID `gorm:"uniqueIndex;type:uuid"`
+	Settings   Settings
+	Lists      []List
+	Collection []CollectionItem
+}
+
+type Settings struct {
+	gorm.Model
+	ProfileID          uint
+	Private            bool
+	ShowName           bool
+	ShowStats          bool
+	ShowCollection     bool
+	ShowListsFollows   bool
+	ShowAuthorsFollows bool
+}
+
+type List struct {
+	gorm.Model
+	ProfileID   uint
+	Name        string
+	Description *string
+	Published   bool
+	Books       []Book `gorm:"many2many:list_books;"`
+}
+
+type CollectionItem struct {
+	gorm.Model
+	ProfileID  uint
+	BookID     uint
+	Book       Book
+	Status     models.Status
+	StartedAt  *time.Time
+	FinishedAt *time.Time
+}
+
+type Book struct {
+	gorm.Model
+	Title         string
+	ISBN          string
+	PublishedAt   *time.Time
+	PageCount     *int
+	Edition       *int
+	NeedsApproval bool
+	Authors       []*Author `gorm:"many2many:book_authors;"`
+	PublisherID   *uint
+	Publisher     Publisher
+	ProfileID     uint
+	Profile       Profile
+}
+
+type Author struct {
+	gorm.Model
+	Name     string
+	BirthDay time.Time
+	Books    []*Book `gorm:"many2many:book_authors;"`
+}
+
+type Publisher struct {
+	gorm.Model
+	Name string
+}
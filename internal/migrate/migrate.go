@@ -0,0 +1,350 @@
+package migrate
+
+import (
+	"cmp"
+	"embed"
+	"errors"
+	"fmt"
+	"io/fs"
+	"maps"
+	"os"
+	"path"
+	"path/filepath"
+	"regexp"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+)
+
+var (
+	ErrUnknownVersion = errors.New("the database has a schema version this build doesn't know")
+	ErrNoMigrations   = errors.New("there are no migrations for this database")
+	ErrBadName        = errors.New("migration names can only have letters, digits and underscores")
+)
+
+// The SQL of every migration, in a directory for each dialect. Files are
+// named like 0001_initial.up.sql and each up has a matching down.
+//
+//go:embed migrations
+var embedded embed.FS
+
+// Where the migrations are in the repository, for create to write to.
+const Dir = "internal/migrate/migrations"
+
+var (
+	fileName = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+	name     = regexp.MustCompile(`^\w+$`)
+)
+
+type Migration struct {
+	Version uint
+	Name    string
+	Up      string
+	Down    string
+	// Runs before Up in the same transaction, for what can't be done in
+	// SQL. Only set on the migrations this build ships with.
+	Prepare func(tx *gorm.DB) error
+}
+
+// A migration as the database sees it. Unknown migrations were applied by
+// a build that has migrations this one doesn't.
+type Status struct {
+	Version   uint
+	Name      string
+	AppliedAt *time.Time
+	Unknown   bool
+}
+
+// A row in schema_migrations.
+type applied struct {
+	Version   uint `gorm:"primaryKey;autoIncrement:false"`
+	Name      string
+	AppliedAt time.Time
+}
+
+func (applied) TableName() string {
+	return "schema_migrations"
+}
+
+// Reads the migrations of the dialect from the directory, ordered by
+// version.
+func Load(fsys fs.FS, dialect string) ([]*Migration, error) {
+	entries, err := fs.ReadDir(fsys, dialect)
+	if err != nil {
+		return nil, err
+	}
+
+	migrations := map[uint]*Migration{}
+	for _, entry := range entries {
+		match := fileName.FindStringSubmatch(entry.Name())
+		if match == nil {
+			continue
+		}
+
+		version, err := strconv.ParseUint(match[1], 10, 0)
+		if err != nil {
+			return nil, err
+		}
+
+		migration, ok := migrations[uint(version)]
+		if !ok {
+			migration = &Migration{Version: uint(version), Name: match[2]}
+			migrations[migration.Version] = migration
+		}
+
+		if migration.Name != match[2] {
+			return nil, fmt.Errorf("migration %d is named both %s and %s", version, migration.Name, match[2])
+		}
+
+		sql, err := fs.ReadFile(fsys, path.Join(dialect, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+
+		if match[3] == "up" {
+			migration.Up = string(sql)
+		} else {
+			migration.Down = string(sql)
+		}
+	}
+
+	return slices.SortedFunc(maps.Values(migrations), func(a, b *Migration) int {
+		return cmp.Compare(a.Version, b.Version)
+	}), nil
+}
+
+// Writes empty up and down files for a new migration in every dialect,
+// numbered after the latest one in any of them. Returns the paths of
+// the files.
+func Create(dir, migration string) ([]string, error) {
+	migration = strings.ReplaceAll(strings.ToLower(strings.TrimSpace(migration)), " ", "_")
+	if !name.MatchString(migration) {
+		return nil, ErrBadName
+	}
+
+	dialects := []string{}
+	entries, err := os.ReadDir(dir)
+	if err != nil {
+		return nil, err
+	}
+
+	var version uint
+	for _, entry := range entries {
+		if !entry.IsDir() {
+			continue
+		}
+
+		migrations, err := Load(os.DirFS(dir), entry.Name())
+		if err != nil {
+			return nil, err
+		}
+
+		if len(migrations) > 0 {
+			version = max(version, migrations[len(migrations)-1].Version)
+		}
+
+		dialects = append(dialects, entry.Name())
+	}
+
+	paths := []string{}
+	for _, dialect := range dialects {
+		for _, direction := range []string{"up", "down"} {
+			file := filepath.Join(dir, dialect, fmt.Sprintf("%04d_%s.%s.sql", version+1, migration, direction))
+			err := os.WriteFile(file, nil, 0o644)
+			if err != nil {
+				return nil, err
+			}
+
+			paths = append(paths, file)
+		}
+	}
+
+	return paths, nil
+}
+
+// Applies and reverts the migrations of the database's dialect, keeping
+// track of them in schema_migrations.
+type Migrator struct {
+	db         *gorm.DB
+	migrations []*Migration
+}
+
+// Creates schema_migrations if the database doesn't have it yet.
+func New(db *gorm.DB) (*Migrator, error) {
+	dir, err := fs.Sub(embedded, "migrations")
+	if err != nil {
+		return nil, err
+	}
+
+	migrations, err := Load(dir, db.Dialector.Name())
+	if err != nil && !errors.Is(err, fs.ErrNotExist) {
+		return nil, err
+	}
+
+	if len(migrations) == 0 {
+		return nil, fmt.Errorf("%w: %s", ErrNoMigrations, db.Dialector.Name())
+	}
+
+	for _, migration := range migrations {
+		migration.Prepare = prepares[migration.Version]
+	}
+
+	if !db.Migrator().HasTable(&applied{}) {
+		err = db.Migrator().CreateTable(&applied{})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	return &Migrator{db, migrations}, nil
+}
+
+// Returns every migration this build knows along with the ones the
+// database has that it doesn't, ordered by version.
+func (m *Migrator) Status() ([]*Status, error) {
+	rows := []*applied{}
+	err := m.db.Order("version").Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	statuses := []*Status{}
+	for _, migration := range m.migrations {
+		statuses = append(statuses, &Status{Version: migration.Version, Name: migration.Name})
+	}
+
+	for _, row := range rows {
+		i := slices.IndexFunc(statuses, func(status *Status) bool { return status.Version == row.Version })
+		if i < 0 {
+			statuses = append(statuses, &Status{Version: row.Version, Name: row.Name, Unknown: true})
+			i = len(statuses) - 1
+		}
+
+		statuses[i].AppliedAt = &row.AppliedAt
+	}
+
+	slices.SortFunc(statuses, func(a, b *Status) int {
+		return cmp.Compare(a.Version, b.Version)
+	})
+
+	return statuses, nil
+}
+
+// Fails with ErrUnknownVersion if the database was migrated by a build
+// with migrations this one doesn't have. Running against such a schema
+// could break it, so it has to be migrated down by that build first.
+func (m *Migrator) Check() error {
+	_, err := m.checkedStatus()
+	return err
+}
+
+// Applies every migration that wasn't applied yet, in order, and returns
+// them. Each migration runs in a transaction of its own, so a failure
+// leaves the ones before it applied.
+func (m *Migrator) Up() ([]*Migration, error) {
+	statuses, err := m.checkedStatus()
+	if err != nil {
+		return nil, err
+	}
+
+	done := []*Migration{}
+	for i, migration := range m.migrations {
+		if statuses[i].AppliedAt != nil {
+			continue
+		}
+
+		err := m.db.Transaction(func(tx *gorm.DB) error {
+			if migration.Prepare != nil {
+				err := migration.Prepare(tx)
+				if err != nil {
+					return err
+				}
+			}
+
+			err := exec(tx, migration.Up)
+			if err != nil {
+				return err
+			}
+
+			return tx.Create(&applied{Version: migration.Version, Name: migration.Name, AppliedAt: time.Now()}).Error
+		})
+
+		if err != nil {
+			return done, fmt.Errorf("couldn't apply migration %d: %w", migration.Version, err)
+		}
+
+		done = append(done, migration)
+	}
+
+	return done, nil
+}
+
+// Reverts the latest migration applied and returns it, or nil if there
+// is none.
+func (m *Migrator) Down() (*Migration, error) {
+	statuses, err := m.checkedStatus()
+	if err != nil {
+		return nil, err
+	}
+
+	var migration *Migration
+	for i := len(m.migrations) - 1; i >= 0 && migration == nil; i-- {
+		if statuses[i].AppliedAt != nil {
+			migration = m.migrations[i]
+		}
+	}
+
+	if migration == nil {
+		return nil, nil
+	}
+
+	err = m.db.Transaction(func(tx *gorm.DB) error {
+		err := exec(tx, migration.Down)
+		if err != nil {
+			return err
+		}
+
+		return tx.Delete(&applied{Version: migration.Version}).Error
+	})
+
+	if err != nil {
+		return nil, fmt.Errorf("couldn't revert migration %d: %w", migration.Version, err)
+	}
+
+	return migration, nil
+}
+
+// Returns the status of the known migrations, in the same order as
+// m.migrations, once the database is known to have no others.
+func (m *Migrator) checkedStatus() ([]*Status, error) {
+	statuses, err := m.Status()
+	if err != nil {
+		return nil, err
+	}
+
+	unknown := []string{}
+	for _, status := range statuses {
+		if status.Unknown {
+			unknown = append(unknown, strconv.FormatUint(uint64(status.Version), 10))
+		}
+	}
+
+	if len(unknown) > 0 {
+		return nil, fmt.Errorf("%w: %s", ErrUnknownVersion, strings.Join(unknown, ", "))
+	}
+
+	return statuses, nil
+}
+
+// Runs every statement in the SQL. Both drivers take several statements
+// at once as long as there are no arguments.
+func exec(tx *gorm.DB, sql string) error {
+	if strings.TrimSpace(sql) == "" {
+		return nil
+	}
+
+	return tx.Exec(sql).Error
+}
@@ -0,0 +1,283 @@
+package migrate_test
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/google/uuid"
+	"github.com/marcos-brito/booklist/internal/conn"
+	"github.com/marcos-brito/booklist/internal/migrate"
+	"github.com/marcos-brito/booklist/internal/models"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+func NewMigrator(t *testing.T) (*migrate.Migrator, *gorm.DB) {
+	db, err := conn.NewSQLiteConnection(filepath.Join(t.TempDir(), "booklist.db"))
+	assert.Nil(t, err)
+
+	migrator, err := migrate.New(db)
+	assert.Nil(t, err)
+
+	return migrator, db
+}
+
+func TestUpAndDown(t *testing.T) {
+	migrator, db := NewMigrator(t)
+
+	t.Run("should apply every migration in order", func(t *testing.T) {
+		applied, err := migrator.Up()
+		assert.Nil(t, err)
+		assert.NotEmpty(t, applied)
+		assert.Equal(t, uint(1), applied[0].Version)
+		assert.True(t, db.Migrator().HasTable(&models.Book{}))
+
+		statuses, err := migrator.Status()
+		assert.Nil(t, err)
+		for _, status := range statuses {
+			assert.NotNil(t, status.AppliedAt)
+		}
+	})
+
+	t.Run("should do nothing when there is nothing pending", func(t *testing.T) {
+		applied, err := migrator.Up()
+		assert.Nil(t, err)
+		assert.Empty(t, applied)
+	})
+
+	t.Run("should revert the latest migration", func(t *testing.T) {
+		statuses, err := migrator.Status()
+		assert.Nil(t, err)
+
+		reverted, err := migrator.Down()
+		assert.Nil(t, err)
+		assert.Equal(t, statuses[len(statuses)-1].Version, reverted.Version)
+
+		statuses, err = migrator.Status()
+		assert.Nil(t, err)
+		assert.Nil(t, statuses[len(statuses)-1].AppliedAt)
+	})
+
+	t.Run("should revert everything and apply it again", func(t *testing.T) {
+		for {
+			reverted, err := migrator.Down()
+			assert.Nil(t, err)
+
+			if reverted == nil {
+				break
+			}
+		}
+
+		assert.False(t, db.Migrator().HasTable(&models.Book{}))
+
+		_, err := migrator.Up()
+		assert.Nil(t, err)
+		assert.True(t, db.Migrator().HasTable(&models.Book{}))
+	})
+}
+
+func TestCheck(t *testing.T) {
+	migrator, db := NewMigrator(t)
+	_, err := migrator.Up()
+	assert.Nil(t, err)
+
+	t.Run("should accept a schema it knows", func(t *testing.T) {
+		assert.Nil(t, migrator.Check())
+	})
+
+	t.Run("should refuse a schema from a newer build", func(t *testing.T) {
+		err := db.Exec("INSERT INTO schema_migrations (version, name, applied_at) VALUES (9999, 'newer', CURRENT_TIMESTAMP)").Error
+		assert.Nil(t, err)
+
+		assert.ErrorIs(t, migrator.Check(), migrate.ErrUnknownVersion)
+
+		_, err = migrator.Up()
+		assert.ErrorIs(t, err, migrate.ErrUnknownVersion)
+
+		_, err = migrator.Down()
+		assert.ErrorIs(t, err, migrate.ErrUnknownVersion)
+
+		statuses, err := migrator.Status()
+		assert.Nil(t, err)
+		assert.True(t, statuses[len(statuses)-1].Unknown)
+	})
+}
+
+func TestCreate(t *testing.T) {
+	dir := t.TempDir()
+	for _, dialect := range []string{"postgres", "sqlite"} {
+		assert.Nil(t, os.Mkdir(filepath.Join(dir, dialect), 0o755))
+	}
+
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "sqlite", "0003_old.up.sql"), nil, 0o644))
+	assert.Nil(t, os.WriteFile(filepath.Join(dir, "sqlite", "0003_old.down.sql"), nil, 0o644))
+
+	t.Run("should number the migration after the latest in any dialect", func(t *testing.T) {
+		paths, err := migrate.Create(dir, "Add reading notes")
+		assert.Nil(t, err)
+		assert.ElementsMatch(t, []string{
+			filepath.Join(dir, "postgres", "0004_add_reading_notes.up.sql"),
+			filepath.Join(dir, "postgres", "0004_add_reading_notes.down.sql"),
+			filepath.Join(dir, "sqlite", "0004_add_reading_notes.up.sql"),
+			filepath.Join(dir, "sqlite", "0004_add_reading_notes.down.sql"),
+		}, paths)
+
+		migrations, err := migrate.Load(os.DirFS(dir), "postgres")
+		assert.Nil(t, err)
+		assert.Len(t, migrations, 1)
+		assert.Equal(t, "add_reading_notes", migrations[0].Name)
+	})
+
+	t.Run("should fail if the name isn't a single word", func(t *testing.T) {
+		_, err := migrate.Create(dir, "add-notes!")
+		assert.ErrorIs(t, err, migrate.ErrBadName)
+	})
+}
+
+func TestApprovalDefaults(t *testing.T) {
+	migrator, db := NewMigrator(t)
+	_, err := migrator.Up()
+	assert.Nil(t, err)
+
+	t.Run("should approve authors and publishers from before submissions", func(t *testing.T) {
+		for {
+			reverted, err := migrator.Down()
+			assert.Nil(t, err)
+
+			if reverted == nil || reverted.Name == "approval_defaults" {
+				break
+			}
+		}
+
+		err := db.Exec("INSERT INTO authors (name) VALUES ('Ursula K. Le Guin')").Error
+		assert.Nil(t, err)
+		err = db.Exec("INSERT INTO publishers (name) VALUES ('Ace')").Error
+		assert.Nil(t, err)
+
+		_, err = migrator.Up()
+		assert.Nil(t, err)
+
+		author := &models.Author{}
+		assert.Nil(t, db.First(author).Error)
+		assert.False(t, author.NeedsApproval)
+
+		publisher := &models.Publisher{}
+		assert.Nil(t, db.First(publisher).Error)
+		assert.False(t, publisher.NeedsApproval)
+	})
+
+	t.Run("should not take NULL anymore", func(t *testing.T) {
+		err := db.Exec("INSERT INTO authors (name, needs_approval) VALUES ('Octavia E. Butler', NULL)").Error
+		assert.NotNil(t, err)
+	})
+}
+
+func TestRatingDefaults(t *testing.T) {
+	migrator, db := NewMigrator(t)
+	_, err := migrator.Up()
+	assert.Nil(t, err)
+
+	t.Run("should zero the ratings of books from before ratings", func(t *testing.T) {
+		for {
+			reverted, err := migrator.Down()
+			assert.Nil(t, err)
+
+			if reverted == nil || reverted.Name == "rating_defaults" {
+				break
+			}
+		}
+
+		err := db.Exec("INSERT INTO books (title, isbn) VALUES ('The Dispossessed', '9780061054884')").Error
+		assert.Nil(t, err)
+
+		_, err = migrator.Up()
+		assert.Nil(t, err)
+
+		book := &models.Book{}
+		assert.Nil(t, db.First(book).Error)
+		assert.Equal(t, 0, book.RatingCount)
+		assert.Equal(t, 0.0, book.RatingTotal)
+
+		err = db.Model(book).Update("rating_count", gorm.Expr("rating_count + ?", 1)).Error
+		assert.Nil(t, err)
+		assert.Nil(t, db.First(book).Error)
+		assert.Equal(t, 1, book.RatingCount)
+	})
+
+	t.Run("should not take NULL anymore", func(t *testing.T) {
+		err := db.Exec("INSERT INTO books (title, isbn, rating_count) VALUES ('Kindred', '9780807083697', NULL)").Error
+		assert.NotNil(t, err)
+	})
+}
+
+func TestAdoptAutoMigrate(t *testing.T) {
+	migrator, db := NewMigrator(t)
+	err := db.AutoMigrate(&Book{}, &Author{}, &Publisher{}, &Profile{}, &Settings{}, &List{}, &CollectionItem{})
+	assert.Nil(t, err)
+
+	profile := &Profile{UUID: uuid.New()}
+	assert.Nil(t, db.Create(profile).Error)
+	book := &Book{
+		Title:     "The Hunger Games",
+		ISBN:      "0-439-02348-3",
+		Authors:   []*Author{{Name: "Suzanne Collins"}},
+		Publisher: Publisher{Name: "Scholastic"},
+		ProfileID: profile.ID,
+	}
+	assert.Nil(t, db.Create(book).Error)
+	item := &CollectionItem{ProfileID: profile.ID, BookID: book.ID, Status: models.StatusReading}
+	assert.Nil(t, db.Create(item).Error)
+
+	t.Run("should add what the tables are missing", func(t *testing.T) {
+		_, err := migrator.Up()
+		assert.Nil(t, err)
+
+		for _, model := range []any{&models.Book{}, &models.Author{}, &models.Publisher{}, &models.Profile{}, &models.CollectionItem{}} {
+			columns, err := db.Migrator().ColumnTypes(model)
+			assert.Nil(t, err)
+
+			names := []string{}
+			for _, column := range columns {
+				names = append(names, column.Name())
+			}
+
+			stmt := &gorm.Statement{DB: db}
+			assert.Nil(t, stmt.Parse(model))
+			for _, field := range stmt.Schema.Fields {
+				if field.DBName != "" {
+					assert.Contains(t, names, field.DBName, stmt.Schema.Table)
+				}
+			}
+		}
+	})
+
+	t.Run("should normalize ISBNs", func(t *testing.T) {
+		adopted := &models.Book{}
+		assert.Nil(t, db.First(adopted, book.ID).Error)
+		assert.Equal(t, "9780439023481", adopted.ISBN)
+		assert.Equal(t, 0, adopted.RatingCount)
+	})
+
+	t.Run("should approve authors and publishers", func(t *testing.T) {
+		author := &models.Author{}
+		assert.Nil(t, db.First(author).Error)
+		assert.False(t, author.NeedsApproval)
+
+		publisher := &models.Publisher{}
+		assert.Nil(t, db.First(publisher).Error)
+		assert.False(t, publisher.NeedsApproval)
+	})
+
+	t.Run("should start the history of items", func(t *testing.T) {
+		events := []*models.ReadingEvent{}
+		assert.Nil(t, db.Where("collection_item_id = ?", item.ID).Find(&events).Error)
+		assert.Len(t, events, 1)
+		assert.Equal(t, models.StatusReading, events[0].Status)
+		assert.Equal(t, 0, events[0].ReadThrough)
+
+		adopted := &models.CollectionItem{}
+		assert.Nil(t, db.First(adopted, item.ID).Error)
+		assert.Equal(t, 0, adopted.Rereads)
+	})
+}
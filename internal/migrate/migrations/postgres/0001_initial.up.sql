@@ -0,0 +1,263 @@
+-- The schema as AutoMigrate left it before migrations were versioned.
+-- Everything is created only if missing, so databases migrated by a
+-- release that used AutoMigrate are adopted, with the columns added
+-- since then added at the end. Their ISBNs are normalized by the
+-- migrator before this runs, so the unique index can be created.
+
+CREATE TABLE IF NOT EXISTS "publishers" (
+	"id" bigserial,
+	"created_at" timestamptz,
+	"updated_at" timestamptz,
+	"deleted_at" timestamptz,
+	"name" text,
+	"needs_approval" boolean,
+	"rejection_reason" text,
+	"profile_id" bigint,
+	PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_publishers_deleted_at" ON "publishers" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "profiles" (
+	"id" bigserial,
+	"created_at" timestamptz,
+	"updated_at" timestamptz,
+	"deleted_at" timestamptz,
+	"uuid" uuid,
+	"moderator" boolean,
+	PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_profiles_uuid" ON "profiles" ("uuid");
+CREATE INDEX IF NOT EXISTS "idx_profiles_deleted_at" ON "profiles" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "books" (
+	"id" bigserial,
+	"created_at" timestamptz,
+	"updated_at" timestamptz,
+	"deleted_at" timestamptz,
+	"title" text,
+	"isbn" text,
+	"published_at" timestamptz,
+	"page_count" bigint,
+	"edition" bigint,
+	"needs_approval" boolean,
+	"rejection_reason" text,
+	"publisher_id" bigint,
+	"profile_id" bigint,
+	"rating_count" bigint,
+	"rating_total" decimal,
+	PRIMARY KEY ("id"),
+	CONSTRAINT "fk_books_publisher" FOREIGN KEY ("publisher_id") REFERENCES "publishers"("id"),
+	CONSTRAINT "fk_books_profile" FOREIGN KEY ("profile_id") REFERENCES "profiles"("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_books_isbn" ON "books" ("isbn") WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS "idx_books_deleted_at" ON "books" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "authors" (
+	"id" bigserial,
+	"created_at" timestamptz,
+	"updated_at" timestamptz,
+	"deleted_at" timestamptz,
+	"name" text,
+	"birth_day" timestamptz,
+	"needs_approval" boolean,
+	"rejection_reason" text,
+	"profile_id" bigint,
+	PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_authors_deleted_at" ON "authors" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "book_authors" (
+	"author_id" bigint,
+	"book_id" bigint,
+	PRIMARY KEY ("author_id","book_id"),
+	CONSTRAINT "fk_book_authors_author" FOREIGN KEY ("author_id") REFERENCES "authors"("id"),
+	CONSTRAINT "fk_book_authors_book" FOREIGN KEY ("book_id") REFERENCES "books"("id")
+);
+
+CREATE TABLE IF NOT EXISTS "lists" (
+	"id" bigserial,
+	"created_at" timestamptz,
+	"updated_at" timestamptz,
+	"deleted_at" timestamptz,
+	"profile_id" bigint,
+	"name" text,
+	"description" text,
+	"published" boolean,
+	PRIMARY KEY ("id"),
+	CONSTRAINT "fk_profiles_lists" FOREIGN KEY ("profile_id") REFERENCES "profiles"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_lists_deleted_at" ON "lists" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "list_books" (
+	"list_id" bigint,
+	"book_id" bigint,
+	PRIMARY KEY ("list_id","book_id"),
+	CONSTRAINT "fk_list_books_list" FOREIGN KEY ("list_id") REFERENCES "lists"("id"),
+	CONSTRAINT "fk_list_books_book" FOREIGN KEY ("book_id") REFERENCES "books"("id")
+);
+
+CREATE TABLE IF NOT EXISTS "list_follows" (
+	"profile_id" bigint,
+	"list_id" bigint,
+	"created_at" timestamptz,
+	PRIMARY KEY ("profile_id","list_id"),
+	CONSTRAINT "fk_list_follows_profile" FOREIGN KEY ("profile_id") REFERENCES "profiles"("id"),
+	CONSTRAINT "fk_list_follows_list" FOREIGN KEY ("list_id") REFERENCES "lists"("id")
+);
+
+CREATE TABLE IF NOT EXISTS "settings" (
+	"id" bigserial,
+	"created_at" timestamptz,
+	"updated_at" timestamptz,
+	"deleted_at" timestamptz,
+	"profile_id" bigint,
+	"private" boolean,
+	"show_name" boolean,
+	"show_stats" boolean,
+	"show_collection" boolean,
+	"show_lists_follows" boolean,
+	"show_authors_follows" boolean,
+	PRIMARY KEY ("id"),
+	CONSTRAINT "fk_profiles_settings" FOREIGN KEY ("profile_id") REFERENCES "profiles"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_settings_deleted_at" ON "settings" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "collection_items" (
+	"id" bigserial,
+	"created_at" timestamptz,
+	"updated_at" timestamptz,
+	"deleted_at" timestamptz,
+	"profile_id" bigint,
+	"book_id" bigint,
+	"status" text,
+	"started_at" timestamptz,
+	"finished_at" timestamptz,
+	"rereads" bigint,
+	"progress" bigint,
+	"percent_complete" decimal,
+	"rating" decimal,
+	"review" text,
+	"spoiler" boolean,
+	"reviewed_at" timestamptz,
+	PRIMARY KEY ("id"),
+	CONSTRAINT "fk_collection_items_book" FOREIGN KEY ("book_id") REFERENCES "books"("id"),
+	CONSTRAINT "fk_profiles_collection" FOREIGN KEY ("profile_id") REFERENCES "profiles"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_collection_items_deleted_at" ON "collection_items" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "reading_events" (
+	"id" bigserial,
+	"collection_item_id" bigint,
+	"status" text,
+	"started_at" timestamptz,
+	"finished_at" timestamptz,
+	"read_through" bigint,
+	"created_at" timestamptz,
+	PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_reading_events_collection_item_id" ON "reading_events" ("collection_item_id");
+
+CREATE TABLE IF NOT EXISTS "reading_sessions" (
+	"id" bigserial,
+	"collection_item_id" bigint,
+	"start_page" bigint,
+	"end_page" bigint,
+	"pages_read" bigint,
+	"minutes" bigint,
+	"created_at" timestamptz,
+	PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_reading_sessions_collection_item_id" ON "reading_sessions" ("collection_item_id");
+
+CREATE TABLE IF NOT EXISTS "reading_goals" (
+	"id" bigserial,
+	"created_at" timestamptz,
+	"updated_at" timestamptz,
+	"deleted_at" timestamptz,
+	"profile_id" bigint,
+	"year" bigint,
+	"books" bigint,
+	"pages" bigint,
+	"completed_at" timestamptz,
+	PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_reading_goals_profile_year" ON "reading_goals" ("profile_id","year");
+CREATE INDEX IF NOT EXISTS "idx_reading_goals_deleted_at" ON "reading_goals" ("deleted_at");
+
+CREATE TABLE IF NOT EXISTS "import_jobs" (
+	"id" bigserial,
+	"created_at" timestamptz,
+	"updated_at" timestamptz,
+	"deleted_at" timestamptz,
+	"profile_id" bigint,
+	"format" text,
+	"status" text,
+	"total" bigint,
+	"processed" bigint,
+	"imported" bigint,
+	"finished_at" timestamptz,
+	PRIMARY KEY ("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_import_jobs_deleted_at" ON "import_jobs" ("deleted_at");
+CREATE INDEX IF NOT EXISTS "idx_import_jobs_profile_id" ON "import_jobs" ("profile_id");
+
+CREATE TABLE IF NOT EXISTS "import_errors" (
+	"id" bigserial,
+	"import_job_id" bigint,
+	"line" bigint,
+	"title" text,
+	"message" text,
+	PRIMARY KEY ("id"),
+	CONSTRAINT "fk_import_jobs_errors" FOREIGN KEY ("import_job_id") REFERENCES "import_jobs"("id")
+);
+CREATE INDEX IF NOT EXISTS "idx_import_errors_import_job_id" ON "import_errors" ("import_job_id");
+
+CREATE TABLE IF NOT EXISTS "data_exports" (
+	"id" bigserial,
+	"created_at" timestamptz,
+	"updated_at" timestamptz,
+	"deleted_at" timestamptz,
+	"profile_id" bigint,
+	"token" text,
+	"format" text,
+	"expires_at" timestamptz,
+	PRIMARY KEY ("id")
+);
+CREATE UNIQUE INDEX IF NOT EXISTS "idx_data_exports_token" ON "data_exports" ("token");
+CREATE INDEX IF NOT EXISTS "idx_data_exports_profile_id" ON "data_exports" ("profile_id");
+CREATE INDEX IF NOT EXISTS "idx_data_exports_deleted_at" ON "data_exports" ("deleted_at");
+
+-- Columns added since the last release that used AutoMigrate, which
+-- tables created by it don't have.
+ALTER TABLE "publishers" ADD COLUMN IF NOT EXISTS "needs_approval" boolean,
+	ADD COLUMN IF NOT EXISTS "rejection_reason" text,
+	ADD COLUMN IF NOT EXISTS "profile_id" bigint;
+ALTER TABLE "profiles" ADD COLUMN IF NOT EXISTS "moderator" boolean;
+ALTER TABLE "books" ADD COLUMN IF NOT EXISTS "rejection_reason" text,
+	ADD COLUMN IF NOT EXISTS "profile_id" bigint,
+	ADD COLUMN IF NOT EXISTS "rating_count" bigint,
+	ADD COLUMN IF NOT EXISTS "rating_total" decimal;
+ALTER TABLE "authors" ADD COLUMN IF NOT EXISTS "needs_approval" boolean,
+	ADD COLUMN IF NOT EXISTS "rejection_reason" text,
+	ADD COLUMN IF NOT EXISTS "profile_id" bigint;
+ALTER TABLE "collection_items" ADD COLUMN IF NOT EXISTS "rereads" bigint,
+	ADD COLUMN IF NOT EXISTS "progress" bigint,
+	ADD COLUMN IF NOT EXISTS "percent_complete" decimal,
+	ADD COLUMN IF NOT EXISTS "rating" decimal,
+	ADD COLUMN IF NOT EXISTS "review" text,
+	ADD COLUMN IF NOT EXISTS "spoiler" boolean,
+	ADD COLUMN IF NOT EXISTS "reviewed_at" timestamptz;
+
+-- Rows from before these columns were added get what new rows get.
+-- Approval and ratings are left to the migrations that set their
+-- defaults.
+UPDATE profiles SET moderator = false WHERE moderator IS NULL;
+UPDATE collection_items SET rereads = 0 WHERE rereads IS NULL;
+UPDATE collection_items SET progress = 0 WHERE progress IS NULL;
+UPDATE collection_items SET spoiler = false WHERE spoiler IS NULL;
+
+-- Items added before reading events existed start their history with a
+-- single event holding their current state.
+INSERT INTO reading_events (collection_item_id, status, started_at, finished_at, read_through, created_at)
+SELECT id, status, started_at, finished_at, rereads, updated_at FROM collection_items
+WHERE deleted_at IS NULL AND id NOT IN (SELECT collection_item_id FROM reading_events);
@@ -0,0 +1,16 @@
+DROP TRIGGER IF EXISTS publishers_search_vector ON publishers;
+DROP TRIGGER IF EXISTS authors_search_vector ON authors;
+DROP TRIGGER IF EXISTS book_authors_search_vector ON book_authors;
+DROP TRIGGER IF EXISTS books_search_vector ON books;
+
+DROP FUNCTION IF EXISTS publishers_search_vector_trigger();
+DROP FUNCTION IF EXISTS authors_search_vector_trigger();
+DROP FUNCTION IF EXISTS book_authors_search_vector_trigger();
+DROP FUNCTION IF EXISTS books_search_vector_trigger();
+DROP FUNCTION IF EXISTS book_search_document(text, bigint, bigint);
+DROP FUNCTION IF EXISTS book_search_text(text, bigint, bigint);
+DROP FUNCTION IF EXISTS book_search_publisher(bigint);
+DROP FUNCTION IF EXISTS book_search_authors(bigint);
+
+DROP INDEX IF EXISTS idx_books_search_vector;
+ALTER TABLE books DROP COLUMN IF EXISTS search_vector;
@@ -0,0 +1,80 @@
+-- The search document of a book is built from its title, its authors'
+-- names and its publisher's name, weighted in that order. Triggers keep
+-- books.search_vector in sync whenever any of those change.
+
+ALTER TABLE books ADD COLUMN IF NOT EXISTS search_vector tsvector;
+
+CREATE OR REPLACE FUNCTION book_search_authors(book bigint) RETURNS text AS $$
+	SELECT coalesce(string_agg(authors.name, ' ' ORDER BY authors.name), '')
+	FROM book_authors JOIN authors ON authors.id = book_authors.author_id
+	WHERE book_authors.book_id = book AND authors.deleted_at IS NULL
+$$ LANGUAGE sql STABLE;
+
+CREATE OR REPLACE FUNCTION book_search_publisher(publisher bigint) RETURNS text AS $$
+	SELECT coalesce((SELECT name FROM publishers WHERE id = publisher AND deleted_at IS NULL), '')
+$$ LANGUAGE sql STABLE;
+
+CREATE OR REPLACE FUNCTION book_search_text(title text, publisher bigint, book bigint) RETURNS text AS $$
+	SELECT concat_ws(' · ', title, nullif(book_search_authors(book), ''), nullif(book_search_publisher(publisher), ''))
+$$ LANGUAGE sql STABLE;
+
+CREATE OR REPLACE FUNCTION book_search_document(title text, publisher bigint, book bigint) RETURNS tsvector AS $$
+	SELECT setweight(to_tsvector('simple', coalesce(title, '')), 'A') ||
+		setweight(to_tsvector('simple', book_search_authors(book)), 'B') ||
+		setweight(to_tsvector('simple', book_search_publisher(publisher)), 'C')
+$$ LANGUAGE sql STABLE;
+
+CREATE OR REPLACE FUNCTION books_search_vector_trigger() RETURNS trigger AS $$
+BEGIN
+	NEW.search_vector := book_search_document(NEW.title, NEW.publisher_id, NEW.id);
+	RETURN NEW;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION book_authors_search_vector_trigger() RETURNS trigger AS $$
+BEGIN
+	UPDATE books SET search_vector = book_search_document(title, publisher_id, id)
+	WHERE id = coalesce(NEW.book_id, OLD.book_id);
+	RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION authors_search_vector_trigger() RETURNS trigger AS $$
+BEGIN
+	UPDATE books SET search_vector = book_search_document(title, publisher_id, id)
+	WHERE id IN (SELECT book_id FROM book_authors WHERE author_id = NEW.id);
+	RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+CREATE OR REPLACE FUNCTION publishers_search_vector_trigger() RETURNS trigger AS $$
+BEGIN
+	UPDATE books SET search_vector = book_search_document(title, publisher_id, id)
+	WHERE publisher_id = NEW.id;
+	RETURN NULL;
+END
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS books_search_vector ON books;
+
+CREATE TRIGGER books_search_vector BEFORE INSERT OR UPDATE OF title, publisher_id ON books
+	FOR EACH ROW EXECUTE FUNCTION books_search_vector_trigger();
+
+DROP TRIGGER IF EXISTS book_authors_search_vector ON book_authors;
+
+CREATE TRIGGER book_authors_search_vector AFTER INSERT OR DELETE ON book_authors
+	FOR EACH ROW EXECUTE FUNCTION book_authors_search_vector_trigger();
+
+DROP TRIGGER IF EXISTS authors_search_vector ON authors;
+
+CREATE TRIGGER authors_search_vector AFTER UPDATE OF name, deleted_at ON authors
+	FOR EACH ROW EXECUTE FUNCTION authors_search_vector_trigger();
+
+DROP TRIGGER IF EXISTS publishers_search_vector ON publishers;
+
+CREATE TRIGGER publishers_search_vector AFTER UPDATE OF name, deleted_at ON publishers
+	FOR EACH ROW EXECUTE FUNCTION publishers_search_vector_trigger();
+
+UPDATE books SET search_vector = book_search_document(title, publisher_id, id) WHERE search_vector IS NULL;
+
+CREATE INDEX IF NOT EXISTS idx_books_search_vector ON books USING GIN (search_vector);
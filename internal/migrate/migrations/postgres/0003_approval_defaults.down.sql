@@ -0,0 +1,2 @@
+ALTER TABLE publishers ALTER COLUMN needs_approval DROP NOT NULL, ALTER COLUMN needs_approval DROP DEFAULT;
+ALTER TABLE authors ALTER COLUMN needs_approval DROP NOT NULL, ALTER COLUMN needs_approval DROP DEFAULT;
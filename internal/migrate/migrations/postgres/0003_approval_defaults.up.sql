@@ -0,0 +1,8 @@
+-- Authors and publishers from before submissions had no approval state,
+-- which the visibility rules read as waiting for approval.
+
+UPDATE authors SET needs_approval = false WHERE needs_approval IS NULL;
+ALTER TABLE authors ALTER COLUMN needs_approval SET DEFAULT false, ALTER COLUMN needs_approval SET NOT NULL;
+
+UPDATE publishers SET needs_approval = false WHERE needs_approval IS NULL;
+ALTER TABLE publishers ALTER COLUMN needs_approval SET DEFAULT false, ALTER COLUMN needs_approval SET NOT NULL;
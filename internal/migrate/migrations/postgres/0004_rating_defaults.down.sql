@@ -0,0 +1,2 @@
+ALTER TABLE books ALTER COLUMN rating_total DROP NOT NULL, ALTER COLUMN rating_total DROP DEFAULT,
+	ALTER COLUMN rating_count DROP NOT NULL, ALTER COLUMN rating_count DROP DEFAULT;
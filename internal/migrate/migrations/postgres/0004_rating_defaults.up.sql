@@ -0,0 +1,7 @@
+-- Books from before ratings had no counters, and adding to a NULL
+-- counter leaves it NULL.
+
+UPDATE books SET rating_count = 0 WHERE rating_count IS NULL;
+UPDATE books SET rating_total = 0 WHERE rating_total IS NULL;
+ALTER TABLE books ALTER COLUMN rating_count SET DEFAULT 0, ALTER COLUMN rating_count SET NOT NULL,
+	ALTER COLUMN rating_total SET DEFAULT 0, ALTER COLUMN rating_total SET NOT NULL;
@@ -0,0 +1,16 @@
+DROP TABLE IF EXISTS data_exports;
+DROP TABLE IF EXISTS import_errors;
+DROP TABLE IF EXISTS import_jobs;
+DROP TABLE IF EXISTS reading_goals;
+DROP TABLE IF EXISTS reading_sessions;
+DROP TABLE IF EXISTS reading_events;
+DROP TABLE IF EXISTS collection_items;
+DROP TABLE IF EXISTS settings;
+DROP TABLE IF EXISTS list_follows;
+DROP TABLE IF EXISTS list_books;
+DROP TABLE IF EXISTS lists;
+DROP TABLE IF EXISTS book_authors;
+DROP TABLE IF EXISTS authors;
+DROP TABLE IF EXISTS books;
+DROP TABLE IF EXISTS profiles;
+DROP TABLE IF EXISTS publishers;
@@ -0,0 +1,229 @@
+-- The schema as AutoMigrate left it before migrations were versioned.
+-- Everything is created only if missing, so databases migrated by a
+-- release that used AutoMigrate are adopted. The migrator adds the
+-- columns added since then and normalizes their ISBNs before this
+-- runs, so the unique index can be created.
+
+CREATE TABLE IF NOT EXISTS `publishers` (
+	`id` integer PRIMARY KEY AUTOINCREMENT,
+	`created_at` datetime,
+	`updated_at` datetime,
+	`deleted_at` datetime,
+	`name` text,
+	`needs_approval` numeric,
+	`rejection_reason` text,
+	`profile_id` integer
+);
+CREATE INDEX IF NOT EXISTS `idx_publishers_deleted_at` ON `publishers`(`deleted_at`);
+
+CREATE TABLE IF NOT EXISTS `profiles` (
+	`id` integer PRIMARY KEY AUTOINCREMENT,
+	`created_at` datetime,
+	`updated_at` datetime,
+	`deleted_at` datetime,
+	`uuid` uuid,
+	`moderator` numeric
+);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_profiles_uuid` ON `profiles`(`uuid`);
+CREATE INDEX IF NOT EXISTS `idx_profiles_deleted_at` ON `profiles`(`deleted_at`);
+
+CREATE TABLE IF NOT EXISTS `books` (
+	`id` integer PRIMARY KEY AUTOINCREMENT,
+	`created_at` datetime,
+	`updated_at` datetime,
+	`deleted_at` datetime,
+	`title` text,
+	`isbn` text,
+	`published_at` datetime,
+	`page_count` integer,
+	`edition` integer,
+	`needs_approval` numeric,
+	`rejection_reason` text,
+	`publisher_id` integer,
+	`profile_id` integer,
+	`rating_count` integer,
+	`rating_total` real,
+	CONSTRAINT `fk_books_publisher` FOREIGN KEY (`publisher_id`) REFERENCES `publishers`(`id`),
+	CONSTRAINT `fk_books_profile` FOREIGN KEY (`profile_id`) REFERENCES `profiles`(`id`)
+);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_books_isbn` ON `books`(`isbn`) WHERE deleted_at IS NULL;
+CREATE INDEX IF NOT EXISTS `idx_books_deleted_at` ON `books`(`deleted_at`);
+
+CREATE TABLE IF NOT EXISTS `authors` (
+	`id` integer PRIMARY KEY AUTOINCREMENT,
+	`created_at` datetime,
+	`updated_at` datetime,
+	`deleted_at` datetime,
+	`name` text,
+	`birth_day` datetime,
+	`needs_approval` numeric,
+	`rejection_reason` text,
+	`profile_id` integer
+);
+CREATE INDEX IF NOT EXISTS `idx_authors_deleted_at` ON `authors`(`deleted_at`);
+
+CREATE TABLE IF NOT EXISTS `book_authors` (
+	`author_id` integer,
+	`book_id` integer,
+	PRIMARY KEY (`author_id`,`book_id`),
+	CONSTRAINT `fk_book_authors_author` FOREIGN KEY (`author_id`) REFERENCES `authors`(`id`),
+	CONSTRAINT `fk_book_authors_book` FOREIGN KEY (`book_id`) REFERENCES `books`(`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `lists` (
+	`id` integer PRIMARY KEY AUTOINCREMENT,
+	`created_at` datetime,
+	`updated_at` datetime,
+	`deleted_at` datetime,
+	`profile_id` integer,
+	`name` text,
+	`description` text,
+	`published` numeric,
+	CONSTRAINT `fk_profiles_lists` FOREIGN KEY (`profile_id`) REFERENCES `profiles`(`id`)
+);
+CREATE INDEX IF NOT EXISTS `idx_lists_deleted_at` ON `lists`(`deleted_at`);
+
+CREATE TABLE IF NOT EXISTS `list_books` (
+	`list_id` integer,
+	`book_id` integer,
+	PRIMARY KEY (`list_id`,`book_id`),
+	CONSTRAINT `fk_list_books_list` FOREIGN KEY (`list_id`) REFERENCES `lists`(`id`),
+	CONSTRAINT `fk_list_books_book` FOREIGN KEY (`book_id`) REFERENCES `books`(`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `list_follows` (
+	`profile_id` integer,
+	`list_id` integer,
+	`created_at` datetime,
+	PRIMARY KEY (`profile_id`,`list_id`),
+	CONSTRAINT `fk_list_follows_profile` FOREIGN KEY (`profile_id`) REFERENCES `profiles`(`id`),
+	CONSTRAINT `fk_list_follows_list` FOREIGN KEY (`list_id`) REFERENCES `lists`(`id`)
+);
+
+CREATE TABLE IF NOT EXISTS `settings` (
+	`id` integer PRIMARY KEY AUTOINCREMENT,
+	`created_at` datetime,
+	`updated_at` datetime,
+	`deleted_at` datetime,
+	`profile_id` integer,
+	`private` numeric,
+	`show_name` numeric,
+	`show_stats` numeric,
+	`show_collection` numeric,
+	`show_lists_follows` numeric,
+	`show_authors_follows` numeric,
+	CONSTRAINT `fk_profiles_settings` FOREIGN KEY (`profile_id`) REFERENCES `profiles`(`id`)
+);
+CREATE INDEX IF NOT EXISTS `idx_settings_deleted_at` ON `settings`(`deleted_at`);
+
+CREATE TABLE IF NOT EXISTS `collection_items` (
+	`id` integer PRIMARY KEY AUTOINCREMENT,
+	`created_at` datetime,
+	`updated_at` datetime,
+	`deleted_at` datetime,
+	`profile_id` integer,
+	`book_id` integer,
+	`status` text,
+	`started_at` datetime,
+	`finished_at` datetime,
+	`rereads` integer,
+	`progress` integer,
+	`percent_complete` real,
+	`rating` real,
+	`review` text,
+	`spoiler` numeric,
+	`reviewed_at` datetime,
+	CONSTRAINT `fk_profiles_collection` FOREIGN KEY (`profile_id`) REFERENCES `profiles`(`id`),
+	CONSTRAINT `fk_collection_items_book` FOREIGN KEY (`book_id`) REFERENCES `books`(`id`)
+);
+CREATE INDEX IF NOT EXISTS `idx_collection_items_deleted_at` ON `collection_items`(`deleted_at`);
+
+CREATE TABLE IF NOT EXISTS `reading_events` (
+	`id` integer PRIMARY KEY AUTOINCREMENT,
+	`collection_item_id` integer,
+	`status` text,
+	`started_at` datetime,
+	`finished_at` datetime,
+	`read_through` integer,
+	`created_at` datetime
+);
+CREATE INDEX IF NOT EXISTS `idx_reading_events_collection_item_id` ON `reading_events`(`collection_item_id`);
+
+CREATE TABLE IF NOT EXISTS `reading_sessions` (
+	`id` integer PRIMARY KEY AUTOINCREMENT,
+	`collection_item_id` integer,
+	`start_page` integer,
+	`end_page` integer,
+	`pages_read` integer,
+	`minutes` integer,
+	`created_at` datetime
+);
+CREATE INDEX IF NOT EXISTS `idx_reading_sessions_collection_item_id` ON `reading_sessions`(`collection_item_id`);
+
+CREATE TABLE IF NOT EXISTS `reading_goals` (
+	`id` integer PRIMARY KEY AUTOINCREMENT,
+	`created_at` datetime,
+	`updated_at` datetime,
+	`deleted_at` datetime,
+	`profile_id` integer,
+	`year` integer,
+	`books` integer,
+	`pages` integer,
+	`completed_at` datetime
+);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_reading_goals_profile_year` ON `reading_goals`(`profile_id`,`year`);
+CREATE INDEX IF NOT EXISTS `idx_reading_goals_deleted_at` ON `reading_goals`(`deleted_at`);
+
+CREATE TABLE IF NOT EXISTS `import_jobs` (
+	`id` integer PRIMARY KEY AUTOINCREMENT,
+	`created_at` datetime,
+	`updated_at` datetime,
+	`deleted_at` datetime,
+	`profile_id` integer,
+	`format` text,
+	`status` text,
+	`total` integer,
+	`processed` integer,
+	`imported` integer,
+	`finished_at` datetime
+);
+CREATE INDEX IF NOT EXISTS `idx_import_jobs_profile_id` ON `import_jobs`(`profile_id`);
+CREATE INDEX IF NOT EXISTS `idx_import_jobs_deleted_at` ON `import_jobs`(`deleted_at`);
+
+CREATE TABLE IF NOT EXISTS `import_errors` (
+	`id` integer PRIMARY KEY AUTOINCREMENT,
+	`import_job_id` integer,
+	`line` integer,
+	`title` text,
+	`message` text,
+	CONSTRAINT `fk_import_jobs_errors` FOREIGN KEY (`import_job_id`) REFERENCES `import_jobs`(`id`)
+);
+CREATE INDEX IF NOT EXISTS `idx_import_errors_import_job_id` ON `import_errors`(`import_job_id`);
+
+CREATE TABLE IF NOT EXISTS `data_exports` (
+	`id` integer PRIMARY KEY AUTOINCREMENT,
+	`created_at` datetime,
+	`updated_at` datetime,
+	`deleted_at` datetime,
+	`profile_id` integer,
+	`token` text,
+	`format` text,
+	`expires_at` datetime
+);
+CREATE UNIQUE INDEX IF NOT EXISTS `idx_data_exports_token` ON `data_exports`(`token`);
+CREATE INDEX IF NOT EXISTS `idx_data_exports_profile_id` ON `data_exports`(`profile_id`);
+CREATE INDEX IF NOT EXISTS `idx_data_exports_deleted_at` ON `data_exports`(`deleted_at`);
+
+-- Rows from before these columns were added get what new rows get.
+-- Approval and ratings are left to the migrations that set their
+-- defaults.
+UPDATE profiles SET moderator = false WHERE moderator IS NULL;
+UPDATE collection_items SET rereads = 0 WHERE rereads IS NULL;
+UPDATE collection_items SET progress = 0 WHERE progress IS NULL;
+UPDATE collection_items SET spoiler = false WHERE spoiler IS NULL;
+
+-- Items added before reading events existed start their history with a
+-- single event holding their current state.
+INSERT INTO reading_events (collection_item_id, status, started_at, finished_at, read_through, created_at)
+SELECT id, status, started_at, finished_at, rereads, updated_at FROM collection_items
+WHERE deleted_at IS NULL AND id NOT IN (SELECT collection_item_id FROM reading_events);
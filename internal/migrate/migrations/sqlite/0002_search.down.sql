@@ -0,0 +1,10 @@
+DROP TRIGGER IF EXISTS publishers_search_update;
+DROP TRIGGER IF EXISTS authors_search_update;
+DROP TRIGGER IF EXISTS book_authors_search_delete;
+DROP TRIGGER IF EXISTS book_authors_search_insert;
+DROP TRIGGER IF EXISTS books_search_delete;
+DROP TRIGGER IF EXISTS books_search_update;
+DROP TRIGGER IF EXISTS books_search_insert;
+
+DROP TABLE IF EXISTS book_search;
+DROP VIEW IF EXISTS book_search_documents;
@@ -0,0 +1,59 @@
+-- SQLite keeps the search document of a book in the book_search full-text
+-- table, one row per book, with the title, the authors' names and the
+-- publisher's name as its columns so they can be weighted when ranking.
+-- Triggers rewrite the row of a book whenever any of those change.
+
+CREATE VIEW IF NOT EXISTS book_search_documents AS
+SELECT books.id,
+	coalesce(books.title, '') AS title,
+	coalesce((SELECT group_concat(name, ' ') FROM (
+		SELECT authors.name FROM book_authors JOIN authors ON authors.id = book_authors.author_id
+		WHERE book_authors.book_id = books.id AND authors.deleted_at IS NULL
+		ORDER BY authors.name)), '') AS authors,
+	coalesce((SELECT name FROM publishers WHERE id = books.publisher_id AND deleted_at IS NULL), '') AS publisher
+FROM books;
+
+CREATE VIRTUAL TABLE IF NOT EXISTS book_search USING fts5(title, authors, publisher,
+	tokenize = 'unicode61 remove_diacritics 0');
+
+CREATE TRIGGER IF NOT EXISTS books_search_insert AFTER INSERT ON books BEGIN
+	INSERT INTO book_search (rowid, title, authors, publisher)
+	SELECT * FROM book_search_documents WHERE id = NEW.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS books_search_update AFTER UPDATE OF title, publisher_id ON books BEGIN
+	DELETE FROM book_search WHERE rowid = NEW.id;
+	INSERT INTO book_search (rowid, title, authors, publisher)
+	SELECT * FROM book_search_documents WHERE id = NEW.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS books_search_delete AFTER DELETE ON books BEGIN
+	DELETE FROM book_search WHERE rowid = OLD.id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS book_authors_search_insert AFTER INSERT ON book_authors BEGIN
+	DELETE FROM book_search WHERE rowid = NEW.book_id;
+	INSERT INTO book_search (rowid, title, authors, publisher)
+	SELECT * FROM book_search_documents WHERE id = NEW.book_id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS book_authors_search_delete AFTER DELETE ON book_authors BEGIN
+	DELETE FROM book_search WHERE rowid = OLD.book_id;
+	INSERT INTO book_search (rowid, title, authors, publisher)
+	SELECT * FROM book_search_documents WHERE id = OLD.book_id;
+END;
+
+CREATE TRIGGER IF NOT EXISTS authors_search_update AFTER UPDATE OF name, deleted_at ON authors BEGIN
+	DELETE FROM book_search WHERE rowid IN (SELECT book_id FROM book_authors WHERE author_id = NEW.id);
+	INSERT INTO book_search (rowid, title, authors, publisher)
+	SELECT * FROM book_search_documents WHERE id IN (SELECT book_id FROM book_authors WHERE author_id = NEW.id);
+END;
+
+CREATE TRIGGER IF NOT EXISTS publishers_search_update AFTER UPDATE OF name, deleted_at ON publishers BEGIN
+	DELETE FROM book_search WHERE rowid IN (SELECT id FROM books WHERE publisher_id = NEW.id);
+	INSERT INTO book_search (rowid, title, authors, publisher)
+	SELECT * FROM book_search_documents WHERE id IN (SELECT id FROM books WHERE publisher_id = NEW.id);
+END;
+
+INSERT INTO book_search (rowid, title, authors, publisher)
+SELECT * FROM book_search_documents WHERE id NOT IN (SELECT rowid FROM book_search);
@@ -0,0 +1,9 @@
+ALTER TABLE publishers ADD COLUMN needs_approval_old numeric;
+UPDATE publishers SET needs_approval_old = needs_approval;
+ALTER TABLE publishers DROP COLUMN needs_approval;
+ALTER TABLE publishers RENAME COLUMN needs_approval_old TO needs_approval;
+
+ALTER TABLE authors ADD COLUMN needs_approval_old numeric;
+UPDATE authors SET needs_approval_old = needs_approval;
+ALTER TABLE authors DROP COLUMN needs_approval;
+ALTER TABLE authors RENAME COLUMN needs_approval_old TO needs_approval;
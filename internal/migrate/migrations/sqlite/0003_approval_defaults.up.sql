@@ -0,0 +1,13 @@
+-- Authors and publishers from before submissions had no approval state,
+-- which the visibility rules read as waiting for approval. SQLite can't
+-- change a column, so it's replaced by one that can't be NULL.
+
+ALTER TABLE authors ADD COLUMN needs_approval_new numeric NOT NULL DEFAULT false;
+UPDATE authors SET needs_approval_new = coalesce(needs_approval, false);
+ALTER TABLE authors DROP COLUMN needs_approval;
+ALTER TABLE authors RENAME COLUMN needs_approval_new TO needs_approval;
+
+ALTER TABLE publishers ADD COLUMN needs_approval_new numeric NOT NULL DEFAULT false;
+UPDATE publishers SET needs_approval_new = coalesce(needs_approval, false);
+ALTER TABLE publishers DROP COLUMN needs_approval;
+ALTER TABLE publishers RENAME COLUMN needs_approval_new TO needs_approval;
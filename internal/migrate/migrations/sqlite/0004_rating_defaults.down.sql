@@ -0,0 +1,9 @@
+ALTER TABLE books ADD COLUMN rating_total_old real;
+UPDATE books SET rating_total_old = rating_total;
+ALTER TABLE books DROP COLUMN rating_total;
+ALTER TABLE books RENAME COLUMN rating_total_old TO rating_total;
+
+ALTER TABLE books ADD COLUMN rating_count_old integer;
+UPDATE books SET rating_count_old = rating_count;
+ALTER TABLE books DROP COLUMN rating_count;
+ALTER TABLE books RENAME COLUMN rating_count_old TO rating_count;
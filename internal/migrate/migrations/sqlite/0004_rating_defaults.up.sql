@@ -0,0 +1,13 @@
+-- Books from before ratings had no counters, and adding to a NULL
+-- counter leaves it NULL. SQLite can't change a column, so they're
+-- replaced by ones that can't be NULL.
+
+ALTER TABLE books ADD COLUMN rating_count_new integer NOT NULL DEFAULT 0;
+UPDATE books SET rating_count_new = coalesce(rating_count, 0);
+ALTER TABLE books DROP COLUMN rating_count;
+ALTER TABLE books RENAME COLUMN rating_count_new TO rating_count;
+
+ALTER TABLE books ADD COLUMN rating_total_new real NOT NULL DEFAULT 0;
+UPDATE books SET rating_total_new = coalesce(rating_total, 0);
+ALTER TABLE books DROP COLUMN rating_total;
+ALTER TABLE books RENAME COLUMN rating_total_new TO rating_total;
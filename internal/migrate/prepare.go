@@ -0,0 +1,107 @@
+package migrate
+
+import (
+	"fmt"
+	"slices"
+
+	"github.com/marcos-brito/booklist/internal/isbn"
+	"github.com/marcos-brito/booklist/internal/models"
+	"gorm.io/gorm"
+)
+
+// What the migrations need done in Go, by version.
+var prepares = map[uint]func(tx *gorm.DB) error{
+	1: adoptAutoMigrate,
+}
+
+// Columns added since the last release that used AutoMigrate, with
+// their SQLite types. Postgres adds them in 0001_initial itself, but
+// SQLite has no ADD COLUMN IF NOT EXISTS.
+var sqliteColumns = []struct{ table, column, kind string }{
+	{"publishers", "needs_approval", "numeric"},
+	{"publishers", "rejection_reason", "text"},
+	{"publishers", "profile_id", "integer"},
+	{"profiles", "moderator", "numeric"},
+	{"books", "rejection_reason", "text"},
+	{"books", "profile_id", "integer"},
+	{"books", "rating_count", "integer"},
+	{"books", "rating_total", "real"},
+	{"authors", "needs_approval", "numeric"},
+	{"authors", "rejection_reason", "text"},
+	{"authors", "profile_id", "integer"},
+	{"collection_items", "rereads", "integer"},
+	{"collection_items", "progress", "integer"},
+	{"collection_items", "percent_complete", "real"},
+	{"collection_items", "rating", "real"},
+	{"collection_items", "review", "text"},
+	{"collection_items", "spoiler", "numeric"},
+	{"collection_items", "reviewed_at", "datetime"},
+}
+
+// Gets databases migrated by AutoMigrate ready for 0001_initial, which
+// only creates the tables they don't have. Fresh databases have nothing
+// to adopt.
+func adoptAutoMigrate(tx *gorm.DB) error {
+	if !tx.Migrator().HasTable(&models.Book{}) {
+		return nil
+	}
+
+	if tx.Dialector.Name() == "sqlite" {
+		err := addSQLiteColumns(tx)
+		if err != nil {
+			return err
+		}
+	}
+
+	return normalizeIsbns(tx)
+}
+
+func addSQLiteColumns(tx *gorm.DB) error {
+	for _, column := range sqliteColumns {
+		if !tx.Migrator().HasTable(column.table) {
+			continue
+		}
+
+		names := []string{}
+		err := tx.Raw("SELECT name FROM pragma_table_info(?)", column.table).Scan(&names).Error
+		if err != nil {
+			return err
+		}
+
+		if slices.Contains(names, column.column) {
+			continue
+		}
+
+		err = tx.Exec(fmt.Sprintf("ALTER TABLE `%s` ADD COLUMN `%s` %s", column.table, column.column, column.kind)).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// Rewrites the ISBNs stored before they were normalized, so the unique
+// index on books.isbn can be created. Invalid ISBNs are left untouched
+// and duplicates have to be merged by hand before migrating.
+func normalizeIsbns(tx *gorm.DB) error {
+	books := []*models.Book{}
+	err := tx.Unscoped().Select("id", "isbn").Find(&books).Error
+	if err != nil {
+		return err
+	}
+
+	for _, book := range books {
+		normalized, err := isbn.Normalize(book.ISBN)
+		if err != nil || normalized == book.ISBN {
+			continue
+		}
+
+		err = tx.Unscoped().Model(book).UpdateColumn("isbn", normalized).Error
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,29 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// A data export that can be downloaded until it expires. The archive is
+// built when it's downloaded, so it always has the latest data.
+type DataExport struct {
+	gorm.Model
+	ProfileID uint   `gorm:"index"`
+	Token     string `gorm:"uniqueIndex"`
+	Format    ExportFormat
+	ExpiresAt time.Time
+}
+
+func (e *DataExport) URL() string {
+	return "/export/" + e.Token
+}
+
+// Everything a user has, as exported.
+type ExportData struct {
+	Settings       *Settings
+	Collection     []*CollectionItem
+	Lists          []*List
+	SubmittedBooks []*Book
+}
@@ -11,6 +11,76 @@ import (
 	"github.com/google/uuid"
 )
 
+type AuthorCount struct {
+	Author *Author `json:"author"`
+	Count  int     `json:"count"`
+}
+
+type BookConnection struct {
+	Edges      []*BookEdge `json:"edges"`
+	PageInfo   *PageInfo   `json:"pageInfo"`
+	TotalCount int         `json:"totalCount"`
+}
+
+type BookEdge struct {
+	Cursor string `json:"cursor"`
+	Node   *Book  `json:"node"`
+}
+
+type BookFilter struct {
+	Author        *uint `json:"author,omitempty"`
+	Publisher     *uint `json:"publisher,omitempty"`
+	PublishedFrom *int  `json:"publishedFrom,omitempty"`
+	PublishedTo   *int  `json:"publishedTo,omitempty"`
+	NeedsApproval *bool `json:"needsApproval,omitempty"`
+}
+
+type BookPage struct {
+	Books      []*Book `json:"books"`
+	TotalCount int     `json:"totalCount"`
+}
+
+type BookSearchResult struct {
+	Book      *Book   `json:"book"`
+	Score     float64 `json:"score"`
+	Highlight string  `json:"highlight"`
+}
+
+// All the given conditions must hold. Ranges include both ends.
+type CollectionFilter struct {
+	Statuses     []Status   `json:"statuses,omitempty"`
+	Author       *uint      `json:"author,omitempty"`
+	Publisher    *uint      `json:"publisher,omitempty"`
+	AddedFrom    *time.Time `json:"addedFrom,omitempty"`
+	AddedTo      *time.Time `json:"addedTo,omitempty"`
+	FinishedFrom *time.Time `json:"finishedFrom,omitempty"`
+	FinishedTo   *time.Time `json:"finishedTo,omitempty"`
+	MinRating    *float64   `json:"minRating,omitempty"`
+	MaxRating    *float64   `json:"maxRating,omitempty"`
+}
+
+type CollectionItemConnection struct {
+	Edges      []*CollectionItemEdge `json:"edges"`
+	PageInfo   *PageInfo             `json:"pageInfo"`
+	TotalCount int                   `json:"totalCount"`
+}
+
+type CollectionItemEdge struct {
+	Cursor string          `json:"cursor"`
+	Node   *CollectionItem `json:"node"`
+}
+
+// Items without a finish date or a rating always come last. Items are in the order they were added by default.
+type CollectionSort struct {
+	Field     CollectionSortField `json:"field"`
+	Direction *SortDirection      `json:"direction,omitempty"`
+}
+
+type CreateAuthor struct {
+	Name     string     `json:"name"`
+	BirthDay *time.Time `json:"birthDay,omitempty"`
+}
+
 type CreateBook struct {
 	Title       string     `json:"title"`
 	Isbn        string     `json:"isbn"`
@@ -21,21 +91,108 @@ type CreateBook struct {
 	Publisher   *uint      `json:"publisher,omitempty"`
 }
 
+type CreatePublisher struct {
+	Name string `json:"name"`
+}
+
 type CurrentUser struct {
-	UUID       uuid.UUID         `json:"uuid"`
-	Name       string            `json:"name"`
-	Email      string            `json:"email"`
-	Settings   *Settings         `json:"settings"`
-	Lists      []*List           `json:"lists"`
-	Collection []*CollectionItem `json:"collection"`
+	UUID                 uuid.UUID                 `json:"uuid"`
+	Name                 string                    `json:"name"`
+	Email                string                    `json:"email"`
+	Moderator            bool                      `json:"moderator"`
+	Settings             *Settings                 `json:"settings"`
+	Lists                []*List                   `json:"lists"`
+	ListsConnection      *ListConnection           `json:"listsConnection"`
+	Collection           []*CollectionItem         `json:"collection"`
+	CollectionConnection *CollectionItemConnection `json:"collectionConnection"`
+	FollowedLists        []*List                   `json:"followedLists"`
+	SubmittedBooks       *BookPage                 `json:"submittedBooks"`
+	Stats                *Stats                    `json:"stats"`
+	ReadingGoal          *ReadingGoal              `json:"readingGoal,omitempty"`
+}
+
+type ListConnection struct {
+	Edges      []*ListEdge `json:"edges"`
+	PageInfo   *PageInfo   `json:"pageInfo"`
+	TotalCount int         `json:"totalCount"`
+}
+
+type ListEdge struct {
+	Cursor string `json:"cursor"`
+	Node   *List  `json:"node"`
 }
 
 type Mutation struct {
 }
 
+// Where a page of a connection is. Cursors are opaque and only valid for the connection they came from.
+type PageInfo struct {
+	HasNextPage     bool    `json:"hasNextPage"`
+	HasPreviousPage bool    `json:"hasPreviousPage"`
+	StartCursor     *string `json:"startCursor,omitempty"`
+	EndCursor       *string `json:"endCursor,omitempty"`
+}
+
+type PeriodStats struct {
+	Period string `json:"period"`
+	Books  int    `json:"books"`
+	Pages  int    `json:"pages"`
+}
+
+type ProgressUpdate struct {
+	Page    *int     `json:"page,omitempty"`
+	Percent *float64 `json:"percent,omitempty"`
+	Minutes *int     `json:"minutes,omitempty"`
+}
+
+type PublisherCount struct {
+	Publisher *Publisher `json:"publisher"`
+	Count     int        `json:"count"`
+}
+
 type Query struct {
 }
 
+type ReadThrough struct {
+	Status       Status     `json:"status"`
+	StartedAt    *time.Time `json:"startedAt,omitempty"`
+	FinishedAt   *time.Time `json:"finishedAt,omitempty"`
+	DurationDays *int       `json:"durationDays,omitempty"`
+}
+
+type ReviewPage struct {
+	Reviews    []*Review `json:"reviews"`
+	TotalCount int       `json:"totalCount"`
+}
+
+type Stats struct {
+	FinishedPerMonth    []*PeriodStats    `json:"finishedPerMonth"`
+	FinishedPerYear     []*PeriodStats    `json:"finishedPerYear"`
+	AverageDaysToFinish *float64          `json:"averageDaysToFinish,omitempty"`
+	StatusDistribution  []*StatusCount    `json:"statusDistribution"`
+	TopAuthors          []*AuthorCount    `json:"topAuthors"`
+	TopPublishers       []*PublisherCount `json:"topPublishers"`
+}
+
+type StatsRange struct {
+	From *time.Time `json:"from,omitempty"`
+	To   *time.Time `json:"to,omitempty"`
+}
+
+type StatusCount struct {
+	Status Status `json:"status"`
+	Count  int    `json:"count"`
+}
+
+type UpdateAuthor struct {
+	Name     *string    `json:"name,omitempty"`
+	BirthDay *time.Time `json:"birthDay,omitempty"`
+}
+
+type UpdatePublisher struct {
+	Name *string `json:"name,omitempty"`
+}
+
 type UpdateSettings struct {
 	Private            bool `json:"private"`
 	ShowName           bool `json:"showName"`
@@ -46,10 +203,318 @@ type UpdateSettings struct {
 }
 
 type User struct {
-	UUID       uuid.UUID         `json:"uuid"`
-	Name       *string           `json:"name,omitempty"`
-	Lists      []*List           `json:"lists,omitempty"`
-	Collection []*CollectionItem `json:"collection,omitempty"`
+	UUID            uuid.UUID         `json:"uuid"`
+	Name            *string           `json:"name,omitempty"`
+	Lists           []*List           `json:"lists,omitempty"`
+	ListsConnection *ListConnection   `json:"listsConnection"`
+	Collection      []*CollectionItem `json:"collection,omitempty"`
+	// Null unless the user shows their collection.
+	CollectionConnection *CollectionItemConnection `json:"collectionConnection,omitempty"`
+	FollowedLists        []*List                   `json:"followedLists,omitempty"`
+	Stats                *Stats                    `json:"stats,omitempty"`
+}
+
+type ApprovalState string
+
+const (
+	ApprovalStatePending  ApprovalState = "PENDING"
+	ApprovalStateApproved ApprovalState = "APPROVED"
+	ApprovalStateRejected ApprovalState = "REJECTED"
+)
+
+var AllApprovalState = []ApprovalState{
+	ApprovalStatePending,
+	ApprovalStateApproved,
+	ApprovalStateRejected,
+}
+
+func (e ApprovalState) IsValid() bool {
+	switch e {
+	case ApprovalStatePending, ApprovalStateApproved, ApprovalStateRejected:
+		return true
+	}
+	return false
+}
+
+func (e ApprovalState) String() string {
+	return string(e)
+}
+
+func (e *ApprovalState) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ApprovalState(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ApprovalState", str)
+	}
+	return nil
+}
+
+func (e ApprovalState) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type CollectionSortField string
+
+const (
+	CollectionSortFieldTitle        CollectionSortField = "TITLE"
+	CollectionSortFieldDateAdded    CollectionSortField = "DATE_ADDED"
+	CollectionSortFieldDateFinished CollectionSortField = "DATE_FINISHED"
+	CollectionSortFieldRating       CollectionSortField = "RATING"
+)
+
+var AllCollectionSortField = []CollectionSortField{
+	CollectionSortFieldTitle,
+	CollectionSortFieldDateAdded,
+	CollectionSortFieldDateFinished,
+	CollectionSortFieldRating,
+}
+
+func (e CollectionSortField) IsValid() bool {
+	switch e {
+	case CollectionSortFieldTitle, CollectionSortFieldDateAdded, CollectionSortFieldDateFinished, CollectionSortFieldRating:
+		return true
+	}
+	return false
+}
+
+func (e CollectionSortField) String() string {
+	return string(e)
+}
+
+func (e *CollectionSortField) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = CollectionSortField(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid CollectionSortField", str)
+	}
+	return nil
+}
+
+func (e CollectionSortField) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type ExportFormat string
+
+const (
+	ExportFormatZip   ExportFormat = "ZIP"
+	ExportFormatTarGz ExportFormat = "TAR_GZ"
+)
+
+var AllExportFormat = []ExportFormat{
+	ExportFormatZip,
+	ExportFormatTarGz,
+}
+
+func (e ExportFormat) IsValid() bool {
+	switch e {
+	case ExportFormatZip, ExportFormatTarGz:
+		return true
+	}
+	return false
+}
+
+func (e ExportFormat) String() string {
+	return string(e)
+}
+
+func (e *ExportFormat) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ExportFormat(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ExportFormat", str)
+	}
+	return nil
+}
+
+func (e ExportFormat) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type GoalPace string
+
+const (
+	GoalPaceAhead   GoalPace = "AHEAD"
+	GoalPaceOnTrack GoalPace = "ON_TRACK"
+	GoalPaceBehind  GoalPace = "BEHIND"
+)
+
+var AllGoalPace = []GoalPace{
+	GoalPaceAhead,
+	GoalPaceOnTrack,
+	GoalPaceBehind,
+}
+
+func (e GoalPace) IsValid() bool {
+	switch e {
+	case GoalPaceAhead, GoalPaceOnTrack, GoalPaceBehind:
+		return true
+	}
+	return false
+}
+
+func (e GoalPace) String() string {
+	return string(e)
+}
+
+func (e *GoalPace) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = GoalPace(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid GoalPace", str)
+	}
+	return nil
+}
+
+func (e GoalPace) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type ImportFormat string
+
+const (
+	ImportFormatGoodreads        ImportFormat = "GOODREADS"
+	ImportFormatStorygraph       ImportFormat = "STORYGRAPH"
+	ImportFormatLibrarythingTsv  ImportFormat = "LIBRARYTHING_TSV"
+	ImportFormatLibrarythingJSON ImportFormat = "LIBRARYTHING_JSON"
+)
+
+var AllImportFormat = []ImportFormat{
+	ImportFormatGoodreads,
+	ImportFormatStorygraph,
+	ImportFormatLibrarythingTsv,
+	ImportFormatLibrarythingJSON,
+}
+
+func (e ImportFormat) IsValid() bool {
+	switch e {
+	case ImportFormatGoodreads, ImportFormatStorygraph, ImportFormatLibrarythingTsv, ImportFormatLibrarythingJSON:
+		return true
+	}
+	return false
+}
+
+func (e ImportFormat) String() string {
+	return string(e)
+}
+
+func (e *ImportFormat) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ImportFormat(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ImportFormat", str)
+	}
+	return nil
+}
+
+func (e ImportFormat) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type ImportStatus string
+
+const (
+	ImportStatusPending ImportStatus = "PENDING"
+	ImportStatusRunning ImportStatus = "RUNNING"
+	ImportStatusDone    ImportStatus = "DONE"
+	ImportStatusFailed  ImportStatus = "FAILED"
+)
+
+var AllImportStatus = []ImportStatus{
+	ImportStatusPending,
+	ImportStatusRunning,
+	ImportStatusDone,
+	ImportStatusFailed,
+}
+
+func (e ImportStatus) IsValid() bool {
+	switch e {
+	case ImportStatusPending, ImportStatusRunning, ImportStatusDone, ImportStatusFailed:
+		return true
+	}
+	return false
+}
+
+func (e ImportStatus) String() string {
+	return string(e)
+}
+
+func (e *ImportStatus) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = ImportStatus(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid ImportStatus", str)
+	}
+	return nil
+}
+
+func (e ImportStatus) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
+}
+
+type SortDirection string
+
+const (
+	SortDirectionAsc  SortDirection = "ASC"
+	SortDirectionDesc SortDirection = "DESC"
+)
+
+var AllSortDirection = []SortDirection{
+	SortDirectionAsc,
+	SortDirectionDesc,
+}
+
+func (e SortDirection) IsValid() bool {
+	switch e {
+	case SortDirectionAsc, SortDirectionDesc:
+		return true
+	}
+	return false
+}
+
+func (e SortDirection) String() string {
+	return string(e)
+}
+
+func (e *SortDirection) UnmarshalGQL(v interface{}) error {
+	str, ok := v.(string)
+	if !ok {
+		return fmt.Errorf("enums must be strings")
+	}
+
+	*e = SortDirection(str)
+	if !e.IsValid() {
+		return fmt.Errorf("%s is not a valid SortDirection", str)
+	}
+	return nil
+}
+
+func (e SortDirection) MarshalGQL(w io.Writer) {
+	fmt.Fprint(w, strconv.Quote(e.String()))
 }
 
 type Status string
@@ -0,0 +1,81 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// A yearly reading goal. At least one of Books and Pages is set. The
+// counts of what was read come from the finished items in the collection
+// and are filled in by the store.
+type ReadingGoal struct {
+	gorm.Model
+	ProfileID   uint `gorm:"uniqueIndex:idx_reading_goals_profile_year"`
+	Year        int  `gorm:"uniqueIndex:idx_reading_goals_profile_year"`
+	Books       *int
+	Pages       *int
+	CompletedAt *time.Time
+	BooksRead   int `gorm:"-"`
+	PagesRead   int `gorm:"-"`
+}
+
+func (goal *ReadingGoal) Reached() bool {
+	return (goal.Books == nil || goal.BooksRead >= *goal.Books) &&
+		(goal.Pages == nil || goal.PagesRead >= *goal.Pages)
+}
+
+// How many books should have been read by now to stay on pace.
+func (goal *ReadingGoal) ExpectedBooks() *int {
+	return goal.expected(goal.Books, time.Now())
+}
+
+// How many pages should have been read by now to stay on pace.
+func (goal *ReadingGoal) ExpectedPages() *int {
+	return goal.expected(goal.Pages, time.Now())
+}
+
+// Compares what was read to what was expected by now. Being behind on
+// any of the targets means being behind, and being ahead means being
+// ahead on all of them.
+func (goal *ReadingGoal) Pace() GoalPace {
+	return goal.paceAt(time.Now())
+}
+
+func (goal *ReadingGoal) paceAt(now time.Time) GoalPace {
+	expected := []*int{goal.expected(goal.Books, now), goal.expected(goal.Pages, now)}
+	read := []int{goal.BooksRead, goal.PagesRead}
+
+	ahead := true
+	for i := range expected {
+		if expected[i] == nil {
+			continue
+		}
+
+		if read[i] < *expected[i] {
+			return GoalPaceBehind
+		}
+
+		ahead = ahead && read[i] > *expected[i]
+	}
+
+	if ahead {
+		return GoalPaceAhead
+	}
+
+	return GoalPaceOnTrack
+}
+
+func (goal *ReadingGoal) expected(target *int, now time.Time) *int {
+	if target == nil {
+		return nil
+	}
+
+	start := time.Date(goal.Year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	end := start.AddDate(1, 0, 0)
+	elapsed := float64(now.Sub(start)) / float64(end.Sub(start))
+	elapsed = max(0, min(1, elapsed))
+
+	expected := int(float64(*target) * elapsed)
+	return &expected
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"gorm.io/gorm"
+)
+
+// A collection import running in the background. Progress is saved as
+// rows are processed, so it can be polled while the import runs.
+type ImportJob struct {
+	gorm.Model
+	ProfileID  uint `gorm:"index"`
+	Format     ImportFormat
+	Status     ImportStatus
+	Total      int
+	Processed  int
+	Imported   int
+	Errors     []*ImportError
+	FinishedAt *time.Time
+}
+
+// A row of an import that couldn't be imported.
+type ImportError struct {
+	ID          uint `gorm:"primarykey"`
+	ImportJobID uint `gorm:"index"`
+	Line        int
+	Title       string
+	Message     string
+}
@@ -10,10 +10,12 @@ import (
 type Profile struct {
 	gorm.Model
 	// Comes from Ory
-	UUID       uuid.UUID `gorm:"uniqueIndex;type:uuid"`
-	Settings   Settings
-	Lists      []List
-	Collection []CollectionItem
+	UUID          uuid.UUID `gorm:"uniqueIndex;type:uuid"`
+	Moderator     bool
+	Settings      Settings
+	Lists         []List
+	Collection    []CollectionItem
+	FollowedLists []List `gorm:"many2many:list_follows;"`
 }
 
 type Settings struct {
@@ -36,29 +38,100 @@ type List struct {
 	Books       []Book `gorm:"many2many:list_books;"`
 }
 
+// Join table between profiles and the lists they follow.
+type ListFollow struct {
+	ProfileID uint `gorm:"primaryKey"`
+	ListID    uint `gorm:"primaryKey"`
+	CreatedAt time.Time
+}
+
 type CollectionItem struct {
 	gorm.Model
+	ProfileID       uint
+	BookID          uint
+	Book            Book
+	Status          Status
+	StartedAt       *time.Time
+	FinishedAt      *time.Time
+	Rereads         int
+	Progress        int
+	PercentComplete *float64
+	Rating          *float64
+	Review          *string
+	Spoiler         bool
+	ReviewedAt      *time.Time
+}
+
+// A review as seen from the reviewed book.
+type Review struct {
+	ID         uint
 	ProfileID  uint
-	BookID     uint
-	Book       Book
-	Status     Status
-	StartedAt  *time.Time
-	FinishedAt *time.Time
+	Rating     *float64
+	Body       string
+	Spoiler    bool
+	ReviewedAt time.Time
+}
+
+// A snapshot of a collection item taken every time its status changes.
+// ReadThrough is the number of rereads at that point, so events can be
+// grouped back into separate read-throughs.
+type ReadingEvent struct {
+	ID               uint `gorm:"primarykey"`
+	CollectionItemID uint `gorm:"index"`
+	Status           Status
+	StartedAt        *time.Time
+	FinishedAt       *time.Time
+	ReadThrough      int
+	CreatedAt        time.Time
+}
+
+// Created for every progress update. Pages are only known for books
+// with a page count.
+type ReadingSession struct {
+	ID               uint `gorm:"primarykey"`
+	CollectionItemID uint `gorm:"index"`
+	StartPage        int
+	EndPage          int
+	PagesRead        int
+	Minutes          int
+	CreatedAt        time.Time
 }
 
 type Book struct {
 	gorm.Model
-	Title         string
-	ISBN          string
-	PublishedAt   *time.Time
-	PageCount     *int
-	Edition       *int
-	NeedsApproval bool
-	Authors       []*Author `gorm:"many2many:book_authors;"`
-	PublisherID   *uint
-	Publisher     Publisher
-	ProfileID     uint
-	Profile       Profile
+	Title           string
+	ISBN            string `gorm:"uniqueIndex:idx_books_isbn,where:deleted_at IS NULL"`
+	PublishedAt     *time.Time
+	PageCount       *int
+	Edition         *int
+	NeedsApproval   bool
+	RejectionReason *string
+	Authors         []*Author `gorm:"many2many:book_authors;"`
+	PublisherID     *uint
+	Publisher       Publisher
+	// Books submitted by deleted accounts have no profile
+	ProfileID *uint
+	Profile   Profile
+	// Kept up to date as items are rated, so the average doesn't have
+	// to be computed on every query
+	RatingCount int     `gorm:"not null;default:0"`
+	RatingTotal float64 `gorm:"not null;default:0"`
+}
+
+// Reports whether the book can be seen by the given profile. Books
+// waiting for approval are only visible to who added them and to
+// moderators. A nil profile stands for an anonymous viewer.
+func (b *Book) VisibleTo(profile *Profile) bool {
+	return visibleTo(b.NeedsApproval, b.ProfileID, profile)
+}
+
+func (b *Book) AverageRating() *float64 {
+	if b.RatingCount == 0 {
+		return nil
+	}
+
+	average := b.RatingTotal / float64(b.RatingCount)
+	return &average
 }
 
 func NewBookFromInput(input *CreateBook, authors []*Author, profileId uint) *Book {
@@ -71,18 +144,65 @@ func NewBookFromInput(input *CreateBook, authors []*Author, profileId uint) *Boo
 		NeedsApproval: true,
 		Authors:       authors,
 		PublisherID:   input.Publisher,
-		ProfileID:     profileId,
+		ProfileID:     &profileId,
 	}
 }
 
 type Author struct {
 	gorm.Model
-	Name     string
-	BirthDay time.Time
-	Books    []*Book `gorm:"many2many:book_authors;"`
+	Name            string
+	BirthDay        *time.Time
+	NeedsApproval   bool `gorm:"not null;default:false"`
+	RejectionReason *string
+	// Authors inserted before submissions existed have no profile
+	ProfileID *uint
+	Books     []*Book `gorm:"many2many:book_authors;"`
+}
+
+func NewAuthorFromInput(input *CreateAuthor, profileId uint) *Author {
+	return &Author{
+		Name:          input.Name,
+		BirthDay:      input.BirthDay,
+		NeedsApproval: true,
+		ProfileID:     &profileId,
+	}
+}
+
+// Same as Book.VisibleTo.
+func (a *Author) VisibleTo(profile *Profile) bool {
+	return visibleTo(a.NeedsApproval, a.ProfileID, profile)
 }
 
 type Publisher struct {
 	gorm.Model
-	Name string
+	Name            string
+	NeedsApproval   bool `gorm:"not null;default:false"`
+	RejectionReason *string
+	// Publishers inserted before submissions existed have no profile
+	ProfileID *uint
+}
+
+func NewPublisherFromInput(input *CreatePublisher, profileId uint) *Publisher {
+	return &Publisher{
+		Name:          input.Name,
+		NeedsApproval: true,
+		ProfileID:     &profileId,
+	}
+}
+
+// Same as Book.VisibleTo.
+func (p *Publisher) VisibleTo(profile *Profile) bool {
+	return visibleTo(p.NeedsApproval, p.ProfileID, profile)
+}
+
+func visibleTo(needsApproval bool, submitter *uint, profile *Profile) bool {
+	if !needsApproval {
+		return true
+	}
+
+	if profile == nil {
+		return false
+	}
+
+	return profile.Moderator || (submitter != nil && *submitter == profile.ID)
 }
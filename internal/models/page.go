@@ -0,0 +1,11 @@
+package models
+
+// A page of rows ordered by id, from the first, after, last and before
+// arguments of a connection. Forward pages take the first rows after
+// After, backward ones the last rows before Before.
+type Page struct {
+	Limit    int
+	After    *uint
+	Before   *uint
+	Backward bool
+}
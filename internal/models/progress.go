@@ -0,0 +1,66 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+// Moves the item to the page or percentage in the update and returns the
+// session that got it there. Any progress means the book is being read
+// and reaching the end means it was read, so the status follows along.
+// Knowing the page count of the book allows converting between pages
+// and percentages. The status is left alone when it can't move there.
+func (item *CollectionItem) Advance(update ProgressUpdate, pageCount *int, now time.Time) *ReadingSession {
+	page := item.Progress
+	var percent *float64
+	finished := false
+
+	if update.Page != nil {
+		page = *update.Page
+		if pageCount != nil && *pageCount > 0 {
+			value := math.Min(100, float64(page)*100/float64(*pageCount))
+			percent = &value
+			finished = page >= *pageCount
+		}
+	}
+
+	// Whether the book is finished is decided before the page is rounded
+	if update.Percent != nil {
+		percent = update.Percent
+		finished = *update.Percent >= 100
+		if pageCount != nil {
+			page = int(math.Round(*update.Percent * float64(*pageCount) / 100))
+		}
+	}
+
+	status := StatusReading
+	if finished {
+		status = StatusRead
+	}
+
+	// Rereads start over, so the status goes first
+	if item.Status != status && CanTransition(item.Status, status) {
+		item.Transition(status, ReadingDates{}, now)
+	}
+
+	session := &ReadingSession{
+		CollectionItemID: item.ID,
+		StartPage:        item.Progress,
+		EndPage:          page,
+		PagesRead:        max(0, page-item.Progress),
+	}
+
+	if update.Minutes != nil {
+		session.Minutes = *update.Minutes
+	}
+
+	item.Progress = page
+	item.PercentComplete = percent
+
+	return session
+}
+
+func (item *CollectionItem) resetProgress() {
+	item.Progress = 0
+	item.PercentComplete = nil
+}
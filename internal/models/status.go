@@ -0,0 +1,138 @@
+package models
+
+import (
+	"slices"
+	"time"
+)
+
+// Dates for an item moving to a status. Undated is for books read on a
+// date that isn't known, like ones imported without it, so finishing
+// them doesn't default to now. It isn't part of the API.
+type ReadingDates struct {
+	StartedAt  *time.Time `json:"startedAt,omitempty"`
+	FinishedAt *time.Time `json:"finishedAt,omitempty"`
+	Undated    bool       `json:"-"`
+}
+
+// Statuses an item can move to from each status. Moving to the same
+// status is always allowed so dates can be corrected.
+var transitions = map[Status][]Status{
+	StatusToRead:  {StatusReading, StatusRead, StatusDropped},
+	StatusReading: {StatusToRead, StatusOnHold, StatusDropped, StatusRead},
+	StatusOnHold:  {StatusToRead, StatusReading, StatusDropped, StatusRead},
+	StatusDropped: {StatusToRead, StatusReading, StatusRead},
+	StatusRead:    {StatusToRead, StatusReading, StatusDropped},
+}
+
+func CanTransition(from, to Status) bool {
+	return from == to || slices.Contains(transitions[from], to)
+}
+
+func NewCollectionItem(profileId, bookId uint, status Status, dates ReadingDates, now time.Time) *CollectionItem {
+	item := &CollectionItem{
+		ProfileID: profileId,
+		BookID:    bookId,
+		Status:    StatusToRead,
+	}
+
+	item.Transition(status, dates, now)
+	return item
+}
+
+// Moves the item to the given status, keeping its dates in sync. Starting
+// to read sets StartedAt and finishing sets FinishedAt, to the given
+// dates if there are any or to now otherwise, unless they're undated.
+// Going from READ back to READING counts as a reread. Callers should
+// check CanTransition first.
+func (item *CollectionItem) Transition(to Status, dates ReadingDates, now time.Time) {
+	from := item.Status
+
+	switch to {
+	case StatusToRead:
+		item.StartedAt = nil
+		item.FinishedAt = nil
+		item.resetProgress()
+	case StatusReading:
+		if from == StatusRead {
+			item.Rereads++
+			item.resetProgress()
+		}
+
+		// Resuming a book keeps the date it was started
+		resuming := from == StatusReading || from == StatusOnHold
+		if dates.StartedAt != nil || !resuming {
+			item.StartedAt = dateOr(dates.StartedAt, now)
+		}
+
+		item.FinishedAt = nil
+	case StatusRead:
+		if dates.StartedAt != nil {
+			item.StartedAt = dates.StartedAt
+		}
+
+		if dates.FinishedAt != nil || (from != StatusRead && !dates.Undated) {
+			item.FinishedAt = dateOr(dates.FinishedAt, now)
+		}
+	case StatusOnHold, StatusDropped:
+		if dates.StartedAt != nil {
+			item.StartedAt = dates.StartedAt
+		}
+
+		item.FinishedAt = nil
+	}
+
+	item.Status = to
+}
+
+// Takes a snapshot of the current state of the item.
+func (item *CollectionItem) Event() *ReadingEvent {
+	return &ReadingEvent{
+		CollectionItemID: item.ID,
+		Status:           item.Status,
+		StartedAt:        item.StartedAt,
+		FinishedAt:       item.FinishedAt,
+		ReadThrough:      item.Rereads,
+	}
+}
+
+// Groups the history of an item into read-throughs. Each read-through
+// takes the dates of its latest event and lasts the whole days between
+// them. Events are expected to be in the order they happened and the
+// ones that never got a date, like adding a book to be read, don't
+// count as reads.
+func ReadThroughs(events []*ReadingEvent) []*ReadThrough {
+	reads := []*ReadThrough{}
+	byIndex := map[int]*ReadThrough{}
+
+	for _, event := range events {
+		read, ok := byIndex[event.ReadThrough]
+		if !ok {
+			read = &ReadThrough{}
+			byIndex[event.ReadThrough] = read
+			reads = append(reads, read)
+		}
+
+		read.Status = event.Status
+		read.StartedAt = event.StartedAt
+		read.FinishedAt = event.FinishedAt
+	}
+
+	for _, read := range reads {
+		if read.StartedAt != nil && read.FinishedAt != nil {
+			days := int(read.FinishedAt.Sub(*read.StartedAt).Hours() / 24)
+			read.DurationDays = &days
+		}
+	}
+
+	return slices.DeleteFunc(reads, func(read *ReadThrough) bool {
+		return read.StartedAt == nil && read.FinishedAt == nil
+	})
+}
+
+func dateOr(date *time.Time, fallback time.Time) *time.Time {
+	if date != nil {
+		return date
+	}
+
+	return &fallback
+}
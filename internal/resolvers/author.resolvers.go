@@ -0,0 +1,166 @@
+package resolvers
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.56
+
+import (
+	"context"
+	"strings"
+
+	"github.com/marcos-brito/booklist/internal/auth"
+	"github.com/marcos-brito/booklist/internal/models"
+	"gorm.io/gorm"
+)
+
+// ApprovalState is the resolver for the approvalState field.
+func (r *authorResolver) ApprovalState(ctx context.Context, obj *models.Author) (models.ApprovalState, error) {
+	return approvalState(obj.NeedsApproval, obj.RejectionReason), nil
+}
+
+// RejectionReason is the resolver for the rejectionReason field.
+func (r *authorResolver) RejectionReason(ctx context.Context, obj *models.Author) (*string, error) {
+	return r.visibleRejectionReason(ctx, obj.ProfileID, obj.RejectionReason)
+}
+
+// Books is the resolver for the books field.
+func (r *authorResolver) Books(ctx context.Context, obj *models.Author) ([]*models.Book, error) {
+	viewer, err := r.viewerProfile(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	books, err := r.Repos.Authors.FindBooks(obj.ID, viewer)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	return books, nil
+}
+
+// CreateAuthor is the resolver for the createAuthor field.
+func (r *mutationResolver) CreateAuthor(ctx context.Context, input models.CreateAuthor) (*models.Author, error) {
+	_, ident, ok := auth.GetSession(ctx)
+	if !ok {
+		return nil, ErrUnauthorized
+	}
+
+	input.Name = strings.TrimSpace(input.Name)
+	if input.Name == "" {
+		return nil, ErrBadArgument("name", "must not be empty")
+	}
+
+	author, err := r.Repos.Authors.Create(&input, ident.UUID)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	return author, nil
+}
+
+// UpdateAuthor is the resolver for the updateAuthor field.
+func (r *mutationResolver) UpdateAuthor(ctx context.Context, id uint, changes models.UpdateAuthor) (*models.Author, error) {
+	_, ident, ok := auth.GetSession(ctx)
+	if !ok {
+		return nil, ErrUnauthorized
+	}
+
+	author, err := r.findVisibleAuthor(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	profile, err := r.Repos.Users.FindProfileByUserUuid(ident.UUID)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	if !canEdit(profile, author.NeedsApproval, author.ProfileID) {
+		return nil, ErrForbidden
+	}
+
+	if changes.Name != nil && strings.TrimSpace(*changes.Name) == "" {
+		return nil, ErrBadArgument("name", "must not be empty")
+	}
+
+	author, err = r.Repos.Authors.Update(id, &changes)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	return author, nil
+}
+
+// MergeAuthors is the resolver for the mergeAuthors field.
+func (r *mutationResolver) MergeAuthors(ctx context.Context, source uint, target uint) (*models.Author, error) {
+	_, ident, ok := auth.GetSession(ctx)
+	if !ok {
+		return nil, ErrUnauthorized
+	}
+
+	ok, err := r.isModerator(ident.UUID)
+	if !ok {
+		return nil, err
+	}
+
+	if source == target {
+		return nil, ErrBadArgument("source", "must be different from target")
+	}
+
+	authorStore := r.Repos.Authors
+	for _, id := range []uint{source, target} {
+		_, err = authorStore.FindById(id)
+		if err != nil {
+			return nil, ErrWithOrInternal(err, gorm.ErrRecordNotFound, ErrBadId(id, "author"))
+		}
+	}
+
+	author, err := authorStore.Merge(source, target)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	return author, nil
+}
+
+// Author is the resolver for the author field.
+func (r *queryResolver) Author(ctx context.Context, id uint) (*models.Author, error) {
+	return r.findVisibleAuthor(ctx, id)
+}
+
+// Authors is the resolver for the authors field.
+func (r *queryResolver) Authors(ctx context.Context, search *string, needsApproval *bool, limit *int, offset *int) ([]*models.Author, error) {
+	limitValue, offsetValue, err := checkPage(limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	viewer, err := r.viewerProfile(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if search == nil {
+		search = new(string)
+	}
+
+	authors, err := r.Repos.Authors.Search(*search, needsApproval, viewer, limitValue, offsetValue)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	return authors, nil
+}
+
+// Author returns AuthorResolver implementation.
+func (r *Resolver) Author() AuthorResolver { return &authorResolver{r} }
+
+// Mutation returns MutationResolver implementation.
+func (r *Resolver) Mutation() MutationResolver { return &mutationResolver{r} }
+
+// Query returns QueryResolver implementation.
+func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
+
+type authorResolver struct{ *Resolver }
+type mutationResolver struct{ *Resolver }
+type queryResolver struct{ *Resolver }
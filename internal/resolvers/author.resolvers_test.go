@@ -0,0 +1,173 @@
+package resolvers_test
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/marcos-brito/booklist/internal/auth"
+	"github.com/marcos-brito/booklist/internal/models"
+	"github.com/marcos-brito/booklist/internal/resolvers"
+	"github.com/stretchr/testify/assert"
+)
+
+func CreateAuthor(t *testing.T, ctx context.Context) *models.Author {
+	resolver := NewResolver()
+	author, err := resolver.Mutation().CreateAuthor(ctx, models.CreateAuthor{
+		Name: fmt.Sprintf("Author %d", rand.Int()),
+	})
+
+	assert.Nil(t, err)
+	return author
+}
+
+func ApproveAuthor(t *testing.T, author *models.Author) *models.Author {
+	resolver := NewResolver()
+	ctx, _ := NewModerator(t)
+	author, err := resolver.Mutation().ApproveAuthor(ctx, author.ID)
+
+	assert.Nil(t, err)
+	return author
+}
+
+func TestCreateAuthor(t *testing.T) {
+	resolver := NewResolver()
+
+	t.Run("should create author waiting for approval", func(t *testing.T) {
+		ctx, _ := NewUser(t)
+		author := CreateAuthor(t, ctx)
+		assert.True(t, author.NeedsApproval)
+
+		got, err := resolver.Query().Author(ctx, author.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, author.ID, got.ID)
+
+		other, _ := NewUser(t)
+		got, err = resolver.Query().Author(other, author.ID)
+		assert.Nil(t, got)
+		assert.ErrorAs(t, err, ErrAsPointer(resolvers.ErrBadId(author.ID, "author")))
+	})
+
+	t.Run("should fail if name is empty", func(t *testing.T) {
+		ctx, _ := NewUser(t)
+		got, err := resolver.Mutation().CreateAuthor(ctx, models.CreateAuthor{Name: " "})
+
+		assert.Nil(t, got)
+		assert.ErrorAs(t, err, ErrAsPointer(resolvers.ErrBadArgument("name", "")))
+	})
+
+	t.Run("should fail if there is no session", func(t *testing.T) {
+		ctx := auth.AddSessionToContext(context.Background(), nil)
+		got, err := resolver.Mutation().CreateAuthor(ctx, models.CreateAuthor{Name: "Author"})
+
+		assert.Nil(t, got)
+		assert.ErrorIs(t, err, resolvers.ErrUnauthorized)
+	})
+}
+
+func TestUpdateAuthor(t *testing.T) {
+	resolver := NewResolver()
+	name := "Renamed"
+
+	t.Run("should let the submitter change a pending author", func(t *testing.T) {
+		ctx, _ := NewUser(t)
+		author := CreateAuthor(t, ctx)
+		got, err := resolver.Mutation().UpdateAuthor(ctx, author.ID, models.UpdateAuthor{Name: &name})
+
+		assert.Nil(t, err)
+		assert.Equal(t, name, got.Name)
+	})
+
+	t.Run("should fail if author was already approved", func(t *testing.T) {
+		ctx, _ := NewUser(t)
+		author := ApproveAuthor(t, CreateAuthor(t, ctx))
+		got, err := resolver.Mutation().UpdateAuthor(ctx, author.ID, models.UpdateAuthor{Name: &name})
+
+		assert.Nil(t, got)
+		assert.ErrorIs(t, err, resolvers.ErrForbidden)
+	})
+}
+
+func TestMergeAuthors(t *testing.T) {
+	resolver := NewResolver()
+	ctx, _ := NewUser(t)
+	moderator, _ := NewModerator(t)
+
+	t.Run("should move books to the target author", func(t *testing.T) {
+		source := CreateAuthor(t, ctx)
+		target := CreateAuthor(t, ctx)
+		book, err := resolver.Mutation().CreateBook(ctx, models.CreateBook{
+			Title:   "Dom Casmurro",
+			Isbn:    RandomIsbn(),
+			Authors: []uint{source.ID},
+		})
+		assert.Nil(t, err)
+
+		merged, err := resolver.Mutation().MergeAuthors(moderator, source.ID, target.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, target.ID, merged.ID)
+
+		books, err := resolver.Author().Books(ctx, merged)
+		assert.Nil(t, err)
+		assert.Equal(t, book.ID, books[0].ID)
+
+		_, err = resolver.Query().Author(moderator, source.ID)
+		assert.ErrorAs(t, err, ErrAsPointer(resolvers.ErrBadId(source.ID, "author")))
+	})
+
+	t.Run("should fail if user is not a moderator", func(t *testing.T) {
+		source := CreateAuthor(t, ctx)
+		target := CreateAuthor(t, ctx)
+		got, err := resolver.Mutation().MergeAuthors(ctx, source.ID, target.ID)
+
+		assert.Nil(t, got)
+		assert.ErrorIs(t, err, resolvers.ErrForbidden)
+	})
+}
+
+func TestAuthors(t *testing.T) {
+	resolver := NewResolver()
+	ctx, _ := NewUser(t)
+	author := ApproveAuthor(t, CreateAuthor(t, ctx))
+
+	t.Run("should find authors by name", func(t *testing.T) {
+		ctx, _ := NewUser(t)
+		got, err := resolver.Query().Authors(ctx, &author.Name, nil, nil, nil)
+
+		assert.Nil(t, err)
+		assert.Len(t, got, 1)
+		assert.Equal(t, author.ID, got[0].ID)
+	})
+}
+
+func TestBookAuthors(t *testing.T) {
+	resolver := NewResolver()
+	ctx, _ := NewUser(t)
+	author := CreateAuthor(t, ctx)
+	book, err := resolver.Mutation().CreateBook(ctx, models.CreateBook{
+		Title:   "Memórias póstumas de Brás Cubas",
+		Isbn:    RandomIsbn(),
+		Authors: []uint{author.ID},
+	})
+	assert.Nil(t, err)
+
+	t.Run("should return the book authors", func(t *testing.T) {
+		got, err := resolver.Book().Authors(ctx, book)
+
+		assert.Nil(t, err)
+		assert.Equal(t, author.ID, got[0].ID)
+	})
+
+	t.Run("should not allow books with authors the user can't see", func(t *testing.T) {
+		other, _ := NewUser(t)
+		got, err := resolver.Mutation().CreateBook(other, models.CreateBook{
+			Title:   "Quincas Borba",
+			Isbn:    RandomIsbn(),
+			Authors: []uint{author.ID},
+		})
+
+		assert.Nil(t, got)
+		assert.ErrorAs(t, err, ErrAsPointer(resolvers.ErrBadId(author.ID, "author")))
+	})
+}
@@ -6,18 +6,107 @@ package resolvers
 
 import (
 	"context"
-	"fmt"
+	"errors"
+	"strings"
 
 	"github.com/marcos-brito/booklist/internal/auth"
-	"github.com/marcos-brito/booklist/internal/conn"
 	"github.com/marcos-brito/booklist/internal/models"
-	"github.com/marcos-brito/booklist/internal/store"
 	"gorm.io/gorm"
 )
 
+// ApprovalState is the resolver for the approvalState field.
+func (r *bookResolver) ApprovalState(ctx context.Context, obj *models.Book) (models.ApprovalState, error) {
+	return approvalState(obj.NeedsApproval, obj.RejectionReason), nil
+}
+
+// RejectionReason is the resolver for the rejectionReason field.
+func (r *bookResolver) RejectionReason(ctx context.Context, obj *models.Book) (*string, error) {
+	return r.visibleRejectionReason(ctx, obj.ProfileID, obj.RejectionReason)
+}
+
+// Authors is the resolver for the authors field.
+func (r *bookResolver) Authors(ctx context.Context, obj *models.Book) ([]*models.Author, error) {
+	viewer, err := r.viewerProfile(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	authors, err := r.loadersFor(ctx).BookAuthors.Load(ctx, obj.ID)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	visible := []*models.Author{}
+	for _, author := range authors {
+		if author.VisibleTo(viewer) {
+			visible = append(visible, author)
+		}
+	}
+
+	return visible, nil
+}
+
+// Publisher is the resolver for the publisher field.
+func (r *bookResolver) Publisher(ctx context.Context, obj *models.Book) (*models.Publisher, error) {
+	if obj.PublisherID == nil {
+		return nil, nil
+	}
+
+	publisher, err := r.loadersFor(ctx).Publishers.Load(ctx, *obj.PublisherID)
+	if err != nil || publisher == nil {
+		return nil, ErrInternal
+	}
+
+	viewer, err := r.viewerProfile(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if !publisher.VisibleTo(viewer) {
+		return nil, nil
+	}
+
+	return publisher, nil
+}
+
 // AddedBy is the resolver for the addedBy field.
 func (r *bookResolver) AddedBy(ctx context.Context, obj *models.Book) (*models.User, error) {
-	panic(fmt.Errorf("not implemented: AddedBy - addedBy"))
+	if obj.ProfileID == nil {
+		return nil, nil
+	}
+
+	return r.loadPublicUser(ctx, *obj.ProfileID)
+}
+
+// Reviews is the resolver for the reviews field.
+func (r *bookResolver) Reviews(ctx context.Context, obj *models.Book, limit *int, offset *int) (*models.ReviewPage, error) {
+	limitValue, offsetValue, err := checkPage(limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	viewer, err := r.viewerProfile(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	bookStore := r.Repos.Books
+	reviews, err := bookStore.FindReviews(obj.ID, viewer, limitValue, offsetValue)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	count, err := bookStore.CountReviews(obj.ID, viewer)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	page := &models.ReviewPage{
+		Reviews:    reviews,
+		TotalCount: int(count),
+	}
+
+	return page, nil
 }
 
 // CreateBook is the resolver for the createBook field.
@@ -27,31 +116,144 @@ func (r *mutationResolver) CreateBook(ctx context.Context, input models.CreateBo
 		return nil, ErrUnauthorized
 	}
 
-	_, err, badId := store.NewAuthorStore(conn.DB).FindManyById(input.Authors...)
+	normalized, err := normalizeIsbn(input.Isbn)
 	if err != nil {
-		return nil, ErrWithOrInternal(err, gorm.ErrRecordNotFound, ErrBadId(*badId, "author"))
+		return nil, err
+	}
+
+	for _, id := range input.Authors {
+		_, err = r.findVisibleAuthor(ctx, id)
+		if err != nil {
+			return nil, err
+		}
 	}
 
 	if input.Publisher != nil {
-		_, err = store.NewPublisherStore(conn.DB).FindById(*input.Publisher)
+		_, err = r.findVisiblePublisher(ctx, *input.Publisher)
 		if err != nil {
-			return nil, ErrWithOrInternal(err, gorm.ErrRecordNotFound, ErrBadId(*input.Publisher, "publisher"))
+			return nil, err
 		}
 	}
 
-	book, err := store.NewBookStore(conn.DB).Create(&input, ident.UUID)
+	input.Isbn = normalized
+	bookStore := r.Repos.Books
+	existing, err := bookStore.FindByIsbn(input.Isbn)
+	if err == nil {
+		return nil, r.duplicateIsbn(ctx, input.Isbn, existing)
+	}
+
+	if !errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, ErrInternal
+	}
+
+	book, err := bookStore.Create(&input, ident.UUID)
 	if err != nil {
+		// Someone else may have added the same ISBN in the meantime
+		existing, findErr := bookStore.FindByIsbn(input.Isbn)
+		if findErr == nil {
+			return nil, r.duplicateIsbn(ctx, input.Isbn, existing)
+		}
+
 		return nil, ErrInternal
 	}
 
 	return book, nil
 }
 
+// Book is the resolver for the book field.
+func (r *queryResolver) Book(ctx context.Context, id uint) (*models.Book, error) {
+	return r.findVisibleBook(ctx, id)
+}
+
+// BookByIsbn is the resolver for the bookByIsbn field.
+func (r *queryResolver) BookByIsbn(ctx context.Context, isbn string) (*models.Book, error) {
+	normalized, err := normalizeIsbn(isbn)
+	if err != nil {
+		return nil, err
+	}
+
+	book, err := r.Repos.Books.FindByIsbn(normalized)
+	if err != nil {
+		return nil, ErrWithOrInternal(err, gorm.ErrRecordNotFound, ErrBadIsbn(isbn))
+	}
+
+	viewer, err := r.viewerProfile(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if !book.VisibleTo(viewer) {
+		return nil, ErrBadIsbn(isbn)
+	}
+
+	return book, nil
+}
+
+// Books is the resolver for the books field.
+func (r *queryResolver) Books(ctx context.Context, filter *models.BookFilter, limit *int, offset *int) (*models.BookPage, error) {
+	limitValue, offsetValue, err := checkPage(limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	viewer, err := r.viewerProfile(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	bookStore := r.Repos.Books
+	books, err := bookStore.FindMany(filter, viewer, limitValue, offsetValue)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	count, err := bookStore.Count(filter, viewer)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	page := &models.BookPage{
+		Books:      books,
+		TotalCount: int(count),
+	}
+
+	return page, nil
+}
+
+// SearchBooks is the resolver for the searchBooks field.
+func (r *queryResolver) SearchBooks(ctx context.Context, query string, limit *int) ([]*models.BookSearchResult, error) {
+	if strings.TrimSpace(query) == "" {
+		return nil, ErrBadArgument("query", "must not be empty")
+	}
+
+	limitValue, _, err := checkPage(limit, nil)
+	if err != nil {
+		return nil, err
+	}
+
+	viewer, err := r.viewerProfile(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	results, err := r.Repos.Books.Search(query, viewer, limitValue)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	return results, nil
+}
+
+// User is the resolver for the user field.
+func (r *reviewResolver) User(ctx context.Context, obj *models.Review) (*models.User, error) {
+	return r.loadPublicUser(ctx, obj.ProfileID)
+}
+
 // Book returns BookResolver implementation.
 func (r *Resolver) Book() BookResolver { return &bookResolver{r} }
 
-// Mutation returns MutationResolver implementation.
-func (r *Resolver) Mutation() MutationResolver { return &mutationResolver{r} }
+// Review returns ReviewResolver implementation.
+func (r *Resolver) Review() ReviewResolver { return &reviewResolver{r} }
 
 type bookResolver struct{ *Resolver }
-type mutationResolver struct{ *Resolver }
+type reviewResolver struct{ *Resolver }
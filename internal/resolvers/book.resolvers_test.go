@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"math/rand/v2"
 	"testing"
+	"time"
 
 	"github.com/marcos-brito/booklist/internal/auth"
 	"github.com/marcos-brito/booklist/internal/models"
@@ -12,11 +13,22 @@ import (
 	"github.com/stretchr/testify/assert"
 )
 
+// Returns a random ISBN-13 with a valid check digit.
+func RandomIsbn() string {
+	body := fmt.Sprintf("979%09d", rand.IntN(1e9))
+	sum := 0
+	for i, r := range body {
+		sum += int(r-'0') * (1 + 2*(i%2))
+	}
+
+	return fmt.Sprintf("%s%d", body, (10-sum%10)%10)
+}
+
 func CreateBook(t *testing.T, ctx context.Context) *models.Book {
-	resolver := resolvers.Resolver{}
+	resolver := NewResolver()
 	input := models.CreateBook{
 		Title: fmt.Sprintf("Book:%d", rand.Int()),
-		Isbn:  fmt.Sprintf("%013d", rand.Int64N(1e13)),
+		Isbn:  RandomIsbn(),
 	}
 
 	book, err := resolver.Mutation().CreateBook(ctx, input)
@@ -26,10 +38,11 @@ func CreateBook(t *testing.T, ctx context.Context) *models.Book {
 }
 
 func TestCreateBook(t *testing.T) {
-	resolver := resolvers.Resolver{}
+	resolver := NewResolver()
 
 	t.Run("should allow create with missing fields", func(t *testing.T) {
-		ctx, _ := NewUser(t)
+		ctx, user := NewUser(t)
+		UpdateSettings(t, ctx, &models.UpdateSettings{Private: false})
 		input := models.CreateBook{
 			Title: "A maldição da casa das flores",
 			Isbn:  "9786555664973",
@@ -38,12 +51,71 @@ func TestCreateBook(t *testing.T) {
 		got, err := resolver.Mutation().CreateBook(ctx, input)
 		assert.Nil(t, err)
 
-		// addedBy, err := resolver.Book().AddedBy(ctx, got)
+		addedBy, err := resolver.Book().AddedBy(ctx, got)
 		assert.Nil(t, err)
 		assert.True(t, got.NeedsApproval)
 		assert.Equal(t, got.ISBN, input.Isbn)
 		assert.Equal(t, got.Title, input.Title)
-		// assert.Equal(t, addedBy.UUID, user.UUID)
+		assert.Equal(t, addedBy.UUID, user.UUID)
+	})
+
+	t.Run("should store the isbn as isbn-13 without hyphens", func(t *testing.T) {
+		ctx, _ := NewUser(t)
+		input := models.CreateBook{
+			Title: "The Go Programming Language",
+			Isbn:  "0-13-419044-0",
+		}
+
+		got, err := resolver.Mutation().CreateBook(ctx, input)
+		assert.Nil(t, err)
+		assert.Equal(t, "9780134190440", got.ISBN)
+	})
+
+	t.Run("should fail if isbn is invalid", func(t *testing.T) {
+		ctx, _ := NewUser(t)
+		input := models.CreateBook{
+			Title: "A maldição da casa das flores",
+			Isbn:  "9786555664974",
+		}
+
+		got, err := resolver.Mutation().CreateBook(ctx, input)
+		assert.ErrorAs(t, err, ErrAsPointer(resolvers.ErrBadArgument("isbn", "")))
+		assert.Nil(t, got)
+	})
+
+	t.Run("should fail if isbn already exists", func(t *testing.T) {
+		ctx, _ := NewUser(t)
+		existing := CreateBook(t, ctx)
+		input := models.CreateBook{
+			Title: "A maldição da casa das flores",
+			Isbn:  existing.ISBN,
+		}
+
+		got, err := resolver.Mutation().CreateBook(ctx, input)
+		assert.Nil(t, got)
+
+		var duplicate *resolvers.DuplicateIsbn
+		assert.ErrorAs(t, err, &duplicate)
+		assert.Equal(t, existing.ID, duplicate.BookId())
+	})
+
+	t.Run("should not reveal a book the user can't see", func(t *testing.T) {
+		ctx, _ := NewUser(t)
+		existing := CreateBook(t, ctx)
+		input := models.CreateBook{
+			Title: "A maldição da casa das flores",
+			Isbn:  existing.ISBN,
+		}
+
+		ctx, _ = NewUser(t)
+		got, err := resolver.Mutation().CreateBook(ctx, input)
+		assert.Nil(t, got)
+
+		var duplicate *resolvers.DuplicateIsbn
+		assert.ErrorAs(t, err, &duplicate)
+		assert.Zero(t, duplicate.BookId())
+		assert.NotContains(t, duplicate.Error(), "with ID")
+		assert.NotContains(t, duplicate.Extensions(), "bookId")
 	})
 
 	t.Run("should fail if there is no session", func(t *testing.T) {
@@ -86,3 +158,161 @@ func TestCreateBook(t *testing.T) {
 		assert.Nil(t, got)
 	})
 }
+
+func TestAddedBy(t *testing.T) {
+	resolver := NewResolver()
+	ctx, user := NewUser(t)
+	book := CreateBook(t, ctx)
+
+	t.Run("should return who added the book", func(t *testing.T) {
+		UpdateSettings(t, ctx, &models.UpdateSettings{Private: false})
+		got, err := resolver.Book().AddedBy(ctx, book)
+
+		assert.Nil(t, err)
+		assert.Equal(t, user.UUID, got.UUID)
+	})
+
+	t.Run("should return nil if user profile is private", func(t *testing.T) {
+		UpdateSettings(t, ctx, &models.UpdateSettings{Private: true})
+		got, err := resolver.Book().AddedBy(ctx, book)
+
+		assert.Nil(t, err)
+		assert.Nil(t, got)
+	})
+}
+
+func TestBookQuery(t *testing.T) {
+	resolver := NewResolver()
+	ctx, _ := NewUser(t)
+	book := CreateBook(t, ctx)
+
+	t.Run("should return the book", func(t *testing.T) {
+		got, err := resolver.Query().Book(ctx, book.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, book.ID, got.ID)
+	})
+
+	t.Run("should fail if book id does not exist", func(t *testing.T) {
+		id := uint(rand.Uint32())
+		got, err := resolver.Query().Book(ctx, id)
+
+		assert.Nil(t, got)
+		assert.ErrorAs(t, err, ErrAsPointer(resolvers.ErrBadId(id, "book")))
+	})
+}
+
+func TestBookByIsbn(t *testing.T) {
+	resolver := NewResolver()
+	ctx, _ := NewUser(t)
+	book := CreateBook(t, ctx)
+
+	t.Run("should return the book with the isbn", func(t *testing.T) {
+		got, err := resolver.Query().BookByIsbn(ctx, book.ISBN)
+		assert.Nil(t, err)
+		assert.Equal(t, book.ID, got.ID)
+	})
+
+	t.Run("should find the book by isbn-10", func(t *testing.T) {
+		book, err := resolver.Mutation().CreateBook(ctx, models.CreateBook{
+			Title: "Structure and Interpretation of Computer Programs",
+			Isbn:  "9780262510875",
+		})
+		assert.Nil(t, err)
+
+		got, err := resolver.Query().BookByIsbn(ctx, "0-262-51087-1")
+		assert.Nil(t, err)
+		assert.Equal(t, book.ID, got.ID)
+	})
+
+	t.Run("should fail if isbn does not exist", func(t *testing.T) {
+		got, err := resolver.Query().BookByIsbn(ctx, "0000000000000")
+
+		assert.Nil(t, got)
+		assert.ErrorAs(t, err, ErrAsPointer(resolvers.ErrBadIsbn("0000000000000")))
+	})
+}
+
+func TestBooksQuery(t *testing.T) {
+	resolver := NewResolver()
+	ctx, _ := NewUser(t)
+	year := 1000 + rand.IntN(500)
+	books := []*models.Book{}
+
+	for i := range 3 {
+		publishedAt := time.Date(year, time.Month(i+1), 1, 0, 0, 0, 0, time.UTC)
+		book, err := resolver.Mutation().CreateBook(ctx, models.CreateBook{
+			Title:       fmt.Sprintf("Book:%d", rand.Int()),
+			Isbn:        RandomIsbn(),
+			PublishedAt: &publishedAt,
+		})
+
+		assert.Nil(t, err)
+		books = append(books, book)
+	}
+
+	t.Run("should filter by publication year", func(t *testing.T) {
+		filter := &models.BookFilter{PublishedFrom: &year, PublishedTo: &year}
+		got, err := resolver.Query().Books(ctx, filter, nil, nil)
+
+		assert.Nil(t, err)
+		assert.Equal(t, len(books), got.TotalCount)
+		for i, book := range got.Books {
+			assert.Equal(t, books[i].ID, book.ID)
+		}
+	})
+
+	t.Run("should paginate results", func(t *testing.T) {
+		filter := &models.BookFilter{PublishedFrom: &year, PublishedTo: &year}
+		limit, offset := 1, 1
+		got, err := resolver.Query().Books(ctx, filter, &limit, &offset)
+
+		assert.Nil(t, err)
+		assert.Equal(t, len(books), got.TotalCount)
+		assert.Len(t, got.Books, 1)
+		assert.Equal(t, books[1].ID, got.Books[0].ID)
+	})
+
+	t.Run("should fail if limit is out of range", func(t *testing.T) {
+		limit := 0
+		got, err := resolver.Query().Books(ctx, nil, &limit, nil)
+
+		assert.Nil(t, got)
+		assert.ErrorAs(t, err, ErrAsPointer(resolvers.ErrBadArgument("limit", "")))
+	})
+}
+
+func TestSearchBooks(t *testing.T) {
+	resolver := NewResolver()
+	ctx, _ := NewUser(t)
+	word := fmt.Sprintf("zq%d", rand.Int())
+	book, err := resolver.Mutation().CreateBook(ctx, models.CreateBook{
+		Title: fmt.Sprintf("The %s mystery", word),
+		Isbn:  RandomIsbn(),
+	})
+
+	assert.Nil(t, err)
+
+	t.Run("should find unapproved books added by the viewer", func(t *testing.T) {
+		got, err := resolver.Query().SearchBooks(ctx, word, nil)
+
+		assert.Nil(t, err)
+		assert.Len(t, got, 1)
+		assert.Equal(t, book.ID, got[0].Book.ID)
+		assert.Contains(t, got[0].Highlight, fmt.Sprintf("<b>%s</b>", word))
+	})
+
+	t.Run("should not find unapproved books added by others", func(t *testing.T) {
+		ctx, _ := NewUser(t)
+		got, err := resolver.Query().SearchBooks(ctx, word, nil)
+
+		assert.Nil(t, err)
+		assert.Empty(t, got)
+	})
+
+	t.Run("should fail if query is empty", func(t *testing.T) {
+		got, err := resolver.Query().SearchBooks(ctx, "  ", nil)
+
+		assert.Nil(t, got)
+		assert.ErrorAs(t, err, ErrAsPointer(resolvers.ErrBadArgument("query", "")))
+	})
+}
@@ -1,21 +1,29 @@
 package resolvers
 
 import (
+	"context"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+	"unicode/utf8"
+
 	"github.com/google/uuid"
-	"github.com/marcos-brito/booklist/internal/conn"
-	"github.com/marcos-brito/booklist/internal/store"
+	"github.com/marcos-brito/booklist/internal/auth"
+	"github.com/marcos-brito/booklist/internal/isbn"
+	"github.com/marcos-brito/booklist/internal/models"
 	"gorm.io/gorm"
 )
 
 // Reports whether the list is owned by ther user with the given
 // UUID. If it's not, a error describing the reason is also returned.
-func listIsOwned(listId uint, userUuid uuid.UUID) (bool, error) {
-	profile, err := store.NewUserStore(conn.DB).FindProfileByUserUuid(userUuid)
+func (r *Resolver) listIsOwned(listId uint, userUuid uuid.UUID) (bool, error) {
+	profile, err := r.Repos.Users.FindProfileByUserUuid(userUuid)
 	if err != nil {
 		return false, ErrInternal
 	}
 
-	list, err := store.NewListStore(conn.DB).FindById(listId)
+	list, err := r.Repos.Lists.FindById(listId)
 	if err != nil {
 		return false, ErrWithOrInternal(gorm.ErrRecordNotFound, err, ErrBadId(listId, "list"))
 	}
@@ -26,3 +34,365 @@ func listIsOwned(listId uint, userUuid uuid.UUID) (bool, error) {
 
 	return true, nil
 }
+
+// Returns the collection item if it's owned by the user with the given
+// UUID, or a error describing why it's not otherwise.
+func (r *Resolver) findOwnedItem(itemId uint, userUuid uuid.UUID) (*models.CollectionItem, error) {
+	userStore := r.Repos.Users
+	profile, err := userStore.FindProfileByUserUuid(userUuid)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	item, err := userStore.FindItemById(itemId)
+	if err != nil || item.ProfileID != profile.ID {
+		return nil, ErrBadId(itemId, "collectionItem")
+	}
+
+	return item, nil
+}
+
+// Reports whether the user with the given UUID is a moderator. If it's
+// not, a error describing the reason is also returned.
+func (r *Resolver) isModerator(userUuid uuid.UUID) (bool, error) {
+	profile, err := r.Repos.Users.FindProfileByUserUuid(userUuid)
+	if err != nil {
+		return false, ErrInternal
+	}
+
+	if !profile.Moderator {
+		return false, ErrForbidden
+	}
+
+	return true, nil
+}
+
+// Returns the profile of the user making the request, or nil if there
+// is no session.
+func (r *Resolver) viewerProfile(ctx context.Context) (*models.Profile, error) {
+	_, ident, ok := auth.GetSession(ctx)
+	if !ok {
+		return nil, nil
+	}
+
+	l := r.loadersFor(ctx)
+	profile, err := l.ProfilesByUuid.Load(ctx, ident.UUID)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	if profile != nil {
+		return profile, nil
+	}
+
+	// First request of the user, so the profile is created
+	profile, err = r.Repos.Users.FindProfileByUserUuid(ident.UUID)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	l.ProfilesByUuid.Prime(ident.UUID, profile)
+	return profile, nil
+}
+
+// Returns the book with the given ID if the user making the request
+// can see it. Books the user can't see are reported as missing.
+func (r *Resolver) findVisibleBook(ctx context.Context, id uint) (*models.Book, error) {
+	book, err := r.Repos.Books.FindById(id)
+	if err != nil {
+		return nil, ErrWithOrInternal(err, gorm.ErrRecordNotFound, ErrBadId(id, "book"))
+	}
+
+	viewer, err := r.viewerProfile(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if !book.VisibleTo(viewer) {
+		return nil, ErrBadId(id, "book")
+	}
+
+	return book, nil
+}
+
+// Resolves limit and offset pagination arguments, applying the defaults
+// for missing ones.
+func checkPage(limit, offset *int) (int, int, error) {
+	l, o := defaultPageSize, 0
+	if limit != nil {
+		l = *limit
+	}
+
+	if offset != nil {
+		o = *offset
+	}
+
+	if l < 1 || l > maxPageSize {
+		return 0, 0, ErrBadArgument("limit", fmt.Sprintf("must be between 1 and %d", maxPageSize))
+	}
+
+	if o < 0 {
+		return 0, 0, ErrBadArgument("offset", "must not be negative")
+	}
+
+	return l, o, nil
+}
+
+func approvalState(needsApproval bool, rejectionReason *string) models.ApprovalState {
+	if !needsApproval {
+		return models.ApprovalStateApproved
+	}
+
+	if rejectionReason != nil {
+		return models.ApprovalStateRejected
+	}
+
+	return models.ApprovalStatePending
+}
+
+// Returns the rejection reason of a submission if the user making the
+// request is its submitter or a moderator. Returns nil otherwise.
+func (r *Resolver) visibleRejectionReason(ctx context.Context, submitter *uint, rejectionReason *string) (*string, error) {
+	viewer, err := r.viewerProfile(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if viewer == nil {
+		return nil, nil
+	}
+
+	if !viewer.Moderator && (submitter == nil || *submitter != viewer.ID) {
+		return nil, nil
+	}
+
+	return rejectionReason, nil
+}
+
+// Reports whether the profile can change a submission. Moderators can
+// change anything, submitters only what is still waiting for approval.
+func canEdit(profile *models.Profile, needsApproval bool, submitter *uint) bool {
+	if profile.Moderator {
+		return true
+	}
+
+	return needsApproval && submitter != nil && *submitter == profile.ID
+}
+
+// Same as findVisibleBook, but for authors.
+func (r *Resolver) findVisibleAuthor(ctx context.Context, id uint) (*models.Author, error) {
+	author, err := r.Repos.Authors.FindById(id)
+	if err != nil {
+		return nil, ErrWithOrInternal(err, gorm.ErrRecordNotFound, ErrBadId(id, "author"))
+	}
+
+	viewer, err := r.viewerProfile(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if !author.VisibleTo(viewer) {
+		return nil, ErrBadId(id, "author")
+	}
+
+	return author, nil
+}
+
+// Same as findVisibleBook, but for publishers.
+func (r *Resolver) findVisiblePublisher(ctx context.Context, id uint) (*models.Publisher, error) {
+	publisher, err := r.Repos.Publishers.FindById(id)
+	if err != nil {
+		return nil, ErrWithOrInternal(err, gorm.ErrRecordNotFound, ErrBadId(id, "publisher"))
+	}
+
+	viewer, err := r.viewerProfile(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	if !publisher.VisibleTo(viewer) {
+		return nil, ErrBadId(id, "publisher")
+	}
+
+	return publisher, nil
+}
+
+// Returns the error for an ISBN that existing already has. The book's ID
+// is left out if the viewer can't see it, so submissions waiting for
+// approval aren't revealed.
+func (r *Resolver) duplicateIsbn(ctx context.Context, isbn string, existing *models.Book) error {
+	viewer, err := r.viewerProfile(ctx)
+	if err != nil {
+		return err
+	}
+
+	if !existing.VisibleTo(viewer) {
+		return ErrDuplicateIsbn(isbn, 0)
+	}
+
+	return ErrDuplicateIsbn(isbn, existing.ID)
+}
+
+// Same as isbn.Normalize, but reports invalid ISBNs as a bad argument.
+func normalizeIsbn(value string) (string, error) {
+	normalized, err := isbn.Normalize(value)
+	if err != nil {
+		return "", ErrBadArgument("isbn", err.Error())
+	}
+
+	return normalized, nil
+}
+
+// Checks the dates given when changing the status of an item. Dates
+// can't be in the future, a book can't be finished before it was started
+// and only books being read or already read can have dates at all.
+func checkReadingDates(item *models.CollectionItem, status models.Status, dates models.ReadingDates, now time.Time) error {
+	if dates.StartedAt != nil && status == models.StatusToRead {
+		return ErrBadArgument("startedAt", "books yet to be read can't have a start date")
+	}
+
+	if dates.FinishedAt != nil && status != models.StatusRead {
+		return ErrBadArgument("finishedAt", "only books already read can have a finish date")
+	}
+
+	if dates.StartedAt != nil && dates.StartedAt.After(now) {
+		return ErrBadArgument("startedAt", "must not be in the future")
+	}
+
+	if dates.FinishedAt != nil && dates.FinishedAt.After(now) {
+		return ErrBadArgument("finishedAt", "must not be in the future")
+	}
+
+	startedAt := dates.StartedAt
+	if startedAt == nil && status == models.StatusRead {
+		startedAt = item.StartedAt
+	}
+
+	if startedAt != nil && dates.FinishedAt != nil && dates.FinishedAt.Before(*startedAt) {
+		return ErrBadArgument("finishedAt", "must not be before the start date")
+	}
+
+	return nil
+}
+
+// Progress is either a page or a percentage, never both. Pages can't go
+// past the end of the book when its page count is known.
+func checkProgress(progress models.ProgressUpdate, pageCount *int) error {
+	if (progress.Page == nil) == (progress.Percent == nil) {
+		return ErrBadArgument("progress", "exactly one of page or percent must be given")
+	}
+
+	if progress.Page != nil && *progress.Page < 0 {
+		return ErrBadArgument("page", "must not be negative")
+	}
+
+	if progress.Page != nil && pageCount != nil && *progress.Page > *pageCount {
+		return ErrBadArgument("page", fmt.Sprintf("book only has %d pages", *pageCount))
+	}
+
+	if progress.Percent != nil && (*progress.Percent < 0 || *progress.Percent > 100) {
+		return ErrBadArgument("percent", "must be between 0 and 100")
+	}
+
+	if progress.Minutes != nil && *progress.Minutes < 0 {
+		return ErrBadArgument("minutes", "must not be negative")
+	}
+
+	return nil
+}
+
+// Ratings go from half a star to five stars in half steps.
+func checkRating(rating *float64) error {
+	if rating == nil {
+		return nil
+	}
+
+	halves := *rating * 2
+	if halves < 1 || halves > 10 || halves != math.Trunc(halves) {
+		return ErrBadArgument("rating", "must be between 0.5 and 5 in steps of 0.5")
+	}
+
+	return nil
+}
+
+const maxReviewLength = 20000
+
+func checkReview(review *string) error {
+	if review == nil {
+		return nil
+	}
+
+	if strings.TrimSpace(*review) == "" {
+		return ErrBadArgument("review", "must not be empty")
+	}
+
+	if utf8.RuneCountInString(*review) > maxReviewLength {
+		return ErrBadArgument("review", fmt.Sprintf("must be at most %d characters", maxReviewLength))
+	}
+
+	return nil
+}
+
+func checkStatsRange(statsRange *models.StatsRange) error {
+	if statsRange == nil || statsRange.From == nil || statsRange.To == nil {
+		return nil
+	}
+
+	if statsRange.To.Before(*statsRange.From) {
+		return ErrBadArgument("range", "must not end before it starts")
+	}
+
+	return nil
+}
+
+// Ranges in a collection filter can't end before they start, and ratings
+// are bounded like the ratings themselves.
+func checkCollectionFilter(filter *models.CollectionFilter) error {
+	if filter == nil {
+		return nil
+	}
+
+	if filter.AddedFrom != nil && filter.AddedTo != nil && filter.AddedTo.Before(*filter.AddedFrom) {
+		return ErrBadArgument("addedTo", "must not be before addedFrom")
+	}
+
+	if filter.FinishedFrom != nil && filter.FinishedTo != nil && filter.FinishedTo.Before(*filter.FinishedFrom) {
+		return ErrBadArgument("finishedTo", "must not be before finishedFrom")
+	}
+
+	for _, rating := range []struct {
+		name  string
+		value *float64
+	}{{"minRating", filter.MinRating}, {"maxRating", filter.MaxRating}} {
+		if rating.value != nil && (*rating.value < 0.5 || *rating.value > 5) {
+			return ErrBadArgument(rating.name, "must be between 0.5 and 5")
+		}
+	}
+
+	if filter.MinRating != nil && filter.MaxRating != nil && *filter.MaxRating < *filter.MinRating {
+		return ErrBadArgument("maxRating", "must not be less than minRating")
+	}
+
+	return nil
+}
+
+// Goals need at least one positive target and a year that makes sense.
+func checkReadingGoal(year int, books, pages *int) error {
+	if year < 1 || year > 9999 {
+		return ErrBadArgument("year", "must be between 1 and 9999")
+	}
+
+	if books == nil && pages == nil {
+		return ErrBadArgument("books", "either books or pages must be given")
+	}
+
+	if books != nil && *books < 1 {
+		return ErrBadArgument("books", "must be positive")
+	}
+
+	if pages != nil && *pages < 1 {
+		return ErrBadArgument("pages", "must be positive")
+	}
+
+	return nil
+}
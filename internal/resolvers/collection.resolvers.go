@@ -6,23 +6,50 @@ package resolvers
 
 import (
 	"context"
+	"time"
 
 	"github.com/marcos-brito/booklist/internal/auth"
-	"github.com/marcos-brito/booklist/internal/conn"
 	"github.com/marcos-brito/booklist/internal/models"
-	"github.com/marcos-brito/booklist/internal/store"
-	"gorm.io/gorm"
 )
 
 // Book is the resolver for the book field.
 func (r *collectionItemResolver) Book(ctx context.Context, obj *models.CollectionItem) (*models.Book, error) {
-	book, err := store.NewBookStore(conn.DB).FindById(obj.BookID)
+	book, err := r.loadersFor(ctx).Books.Load(ctx, obj.BookID)
+	if err != nil || book == nil {
+		return nil, ErrInternal
+	}
 
+	return book, nil
+}
+
+// History is the resolver for the history field.
+func (r *collectionItemResolver) History(ctx context.Context, obj *models.CollectionItem) ([]*models.ReadingEvent, error) {
+	history, err := r.Repos.Users.FindItemHistory(obj.ID)
 	if err != nil {
 		return nil, ErrInternal
 	}
 
-	return book, nil
+	return history, nil
+}
+
+// Reads is the resolver for the reads field.
+func (r *collectionItemResolver) Reads(ctx context.Context, obj *models.CollectionItem) ([]*models.ReadThrough, error) {
+	history, err := r.Repos.Users.FindItemHistory(obj.ID)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	return models.ReadThroughs(history), nil
+}
+
+// Sessions is the resolver for the sessions field.
+func (r *collectionItemResolver) Sessions(ctx context.Context, obj *models.CollectionItem) ([]*models.ReadingSession, error) {
+	sessions, err := r.Repos.Users.FindItemSessions(obj.ID)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	return sessions, nil
 }
 
 // AddToCollection is the resolver for the addToCollection field.
@@ -32,9 +59,9 @@ func (r *mutationResolver) AddToCollection(ctx context.Context, bookID uint, sta
 		return nil, ErrUnauthorized
 	}
 
-	_, err := store.NewBookStore(conn.DB).FindById(bookID)
+	_, err := r.findVisibleBook(ctx, bookID)
 	if err != nil {
-		return nil, ErrWithOrInternal(err, gorm.ErrRecordNotFound, ErrBadId(bookID, "book"))
+		return nil, err
 	}
 
 	if status == nil {
@@ -42,7 +69,18 @@ func (r *mutationResolver) AddToCollection(ctx context.Context, bookID uint, sta
 		*status = models.StatusToRead
 	}
 
-	item, err := store.NewUserStore(conn.DB).AddToCollection(ident.UUID, bookID, *status)
+	userStore := r.Repos.Users
+	profile, err := userStore.FindProfileByUserUuid(ident.UUID)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	item, err := userStore.AddToCollection(ident.UUID, bookID, *status, models.ReadingDates{})
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	err = r.syncGoals(profile, nil, item.FinishedAt)
 	if err != nil {
 		return nil, ErrInternal
 	}
@@ -57,7 +95,7 @@ func (r *mutationResolver) DeleteFromCollection(ctx context.Context, itemID uint
 		return nil, ErrUnauthorized
 	}
 
-	userStore := store.NewUserStore(conn.DB)
+	userStore := r.Repos.Users
 	profile, err := userStore.FindProfileByUserUuid(ident.UUID)
 	if err != nil {
 		return nil, ErrInternal
@@ -73,17 +111,22 @@ func (r *mutationResolver) DeleteFromCollection(ctx context.Context, itemID uint
 		return nil, ErrInternal
 	}
 
+	err = r.syncGoals(profile, item.FinishedAt, nil)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
 	return item, nil
 }
 
 // ChangeItemStatus is the resolver for the changeItemStatus field.
-func (r *mutationResolver) ChangeItemStatus(ctx context.Context, itemID uint, status models.Status) (*models.CollectionItem, error) {
+func (r *mutationResolver) ChangeItemStatus(ctx context.Context, itemID uint, status models.Status, dates *models.ReadingDates) (*models.CollectionItem, error) {
 	_, ident, ok := auth.GetSession(ctx)
 	if !ok {
 		return nil, ErrUnauthorized
 	}
 
-	userStore := store.NewUserStore(conn.DB)
+	userStore := r.Repos.Users
 	profile, err := userStore.FindProfileByUserUuid(ident.UUID)
 	if err != nil {
 		return nil, ErrInternal
@@ -94,7 +137,118 @@ func (r *mutationResolver) ChangeItemStatus(ctx context.Context, itemID uint, st
 		return nil, ErrBadId(itemID, "collectionItem")
 	}
 
-	item, err = userStore.ChangeItemStatus(itemID, status)
+	if !models.CanTransition(item.Status, status) {
+		return nil, ErrBadTransition(item.Status, status)
+	}
+
+	if dates == nil {
+		dates = &models.ReadingDates{}
+	}
+
+	err = checkReadingDates(item, status, *dates, time.Now())
+	if err != nil {
+		return nil, err
+	}
+
+	finishedAt := item.FinishedAt
+	item, err = userStore.ChangeItemStatus(itemID, status, *dates)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	err = r.syncGoals(profile, finishedAt, item.FinishedAt)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	return item, nil
+}
+
+// UpdateProgress is the resolver for the updateProgress field.
+func (r *mutationResolver) UpdateProgress(ctx context.Context, itemID uint, progress models.ProgressUpdate) (*models.CollectionItem, error) {
+	_, ident, ok := auth.GetSession(ctx)
+	if !ok {
+		return nil, ErrUnauthorized
+	}
+
+	userStore := r.Repos.Users
+	profile, err := userStore.FindProfileByUserUuid(ident.UUID)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	item, err := userStore.FindItemById(itemID)
+	if err != nil || item.ProfileID != profile.ID {
+		return nil, ErrBadId(itemID, "collectionItem")
+	}
+
+	book, err := r.Repos.Books.FindById(item.BookID)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	err = checkProgress(progress, book.PageCount)
+	if err != nil {
+		return nil, err
+	}
+
+	finishedAt := item.FinishedAt
+	item, err = userStore.UpdateProgress(itemID, progress)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	err = r.syncGoals(profile, finishedAt, item.FinishedAt)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	return item, nil
+}
+
+// RateItem is the resolver for the rateItem field.
+func (r *mutationResolver) RateItem(ctx context.Context, itemID uint, rating *float64) (*models.CollectionItem, error) {
+	_, ident, ok := auth.GetSession(ctx)
+	if !ok {
+		return nil, ErrUnauthorized
+	}
+
+	_, err := r.findOwnedItem(itemID, ident.UUID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkRating(rating)
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := r.Repos.Users.RateItem(itemID, rating)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	return item, nil
+}
+
+// ReviewItem is the resolver for the reviewItem field.
+func (r *mutationResolver) ReviewItem(ctx context.Context, itemID uint, review *string, spoiler *bool) (*models.CollectionItem, error) {
+	_, ident, ok := auth.GetSession(ctx)
+	if !ok {
+		return nil, ErrUnauthorized
+	}
+
+	_, err := r.findOwnedItem(itemID, ident.UUID)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkReview(review)
+	if err != nil {
+		return nil, err
+	}
+
+	item, err := r.Repos.Users.ReviewItem(itemID, review, spoiler != nil && *spoiler)
 	if err != nil {
 		return nil, ErrInternal
 	}
@@ -6,6 +6,7 @@ import (
 	"math/rand/v2"
 	"slices"
 	"testing"
+	"time"
 
 	"github.com/google/uuid"
 	"github.com/marcos-brito/booklist/internal/auth"
@@ -16,7 +17,7 @@ import (
 )
 
 func NewUser(t *testing.T) (context.Context, *models.CurrentUser) {
-	resolver := resolvers.Resolver{}
+	resolver := NewResolver()
 	ctx := auth.AddSessionToContext(context.Background(), NewRandomSession())
 
 	user, err := resolver.Query().Me(ctx)
@@ -37,7 +38,7 @@ func NewRandomSession() *ory.Session {
 }
 
 func AddItemToUserCollection(t *testing.T, ctx context.Context, bookId uint) *models.CollectionItem {
-	resolver := resolvers.Resolver{}
+	resolver := NewResolver()
 	status := models.StatusReading
 	item, err := resolver.Mutation().AddToCollection(ctx, bookId, &status)
 
@@ -53,7 +54,7 @@ func ErrAsPointer(err error) *error {
 }
 
 func TestBook(t *testing.T) {
-	resolver := &resolvers.Resolver{}
+	resolver := NewResolver()
 	ctx, _ := NewUser(t)
 	book, err := resolver.Mutation().CreateBook(ctx, models.CreateBook{
 		Title: "O homem de giz",
@@ -61,6 +62,7 @@ func TestBook(t *testing.T) {
 	})
 
 	assert.Nil(t, err)
+	book = ApproveBook(t, book)
 
 	t.Run("should return the book related to the item", func(t *testing.T) {
 		ctx, _ := NewUser(t)
@@ -73,7 +75,7 @@ func TestBook(t *testing.T) {
 }
 
 func TestAddToCollection(t *testing.T) {
-	resolver := resolvers.Resolver{}
+	resolver := NewResolver()
 	ctx, _ := NewUser(t)
 	book, err := resolver.Mutation().CreateBook(ctx, models.CreateBook{
 		Title: "Não conta a ninguém",
@@ -81,6 +83,7 @@ func TestAddToCollection(t *testing.T) {
 	})
 
 	assert.Nil(t, err)
+	book = ApproveBook(t, book)
 
 	t.Run("should add item to collection", func(t *testing.T) {
 		ctx, user := NewUser(t)
@@ -89,7 +92,7 @@ func TestAddToCollection(t *testing.T) {
 		created, err := resolver.Mutation().AddToCollection(ctx, book.ID, &status)
 		assert.Nil(t, err)
 
-		collection, err := resolver.CurrentUser().Collection(ctx, user)
+		collection, err := resolver.CurrentUser().Collection(ctx, user, nil, nil)
 		assert.Nil(t, err)
 		assert.True(t, slices.ContainsFunc(collection, func(item *models.CollectionItem) bool {
 			return item.ID == created.ID
@@ -120,7 +123,7 @@ func TestAddToCollection(t *testing.T) {
 }
 
 func TestDeleteFromCollection(t *testing.T) {
-	resolver := resolvers.Resolver{}
+	resolver := NewResolver()
 	ctx, _ := NewUser(t)
 	book, err := resolver.Mutation().CreateBook(ctx, models.CreateBook{
 		Title: "Seis anos depois",
@@ -128,6 +131,7 @@ func TestDeleteFromCollection(t *testing.T) {
 	})
 
 	assert.Nil(t, err)
+	book = ApproveBook(t, book)
 
 	t.Run("should delete item from collection", func(t *testing.T) {
 		ctx, user := NewUser(t)
@@ -136,7 +140,7 @@ func TestDeleteFromCollection(t *testing.T) {
 		deleted, err := resolver.Mutation().DeleteFromCollection(ctx, item.ID)
 		assert.Nil(t, err)
 
-		collection, err := resolver.CurrentUser().Collection(ctx, user)
+		collection, err := resolver.CurrentUser().Collection(ctx, user, nil, nil)
 		assert.Nil(t, err)
 		assert.False(t, slices.ContainsFunc(collection, func(item *models.CollectionItem) bool {
 			return item.ID == deleted.ID
@@ -165,7 +169,7 @@ func TestDeleteFromCollection(t *testing.T) {
 }
 
 func TestChangeItemStatus(t *testing.T) {
-	resolver := resolvers.Resolver{}
+	resolver := NewResolver()
 	ctx, _ := NewUser(t)
 	book, err := resolver.Mutation().CreateBook(ctx, models.CreateBook{
 		Title: "Contra todas as probabilidades do amor",
@@ -173,27 +177,123 @@ func TestChangeItemStatus(t *testing.T) {
 	})
 
 	assert.Nil(t, err)
+	book = ApproveBook(t, book)
 
 	t.Run("should change item status", func(t *testing.T) {
 		ctx, user := NewUser(t)
 		item := AddItemToUserCollection(t, ctx, book.ID)
 
-		changed, err := resolver.Mutation().ChangeItemStatus(ctx, item.ID, models.StatusDropped)
+		changed, err := resolver.Mutation().ChangeItemStatus(ctx, item.ID, models.StatusDropped, nil)
 		assert.Nil(t, err)
 		assert.Equal(t, changed.Status, models.StatusDropped)
 
-		collection, err := resolver.CurrentUser().Collection(ctx, user)
+		collection, err := resolver.CurrentUser().Collection(ctx, user, nil, nil)
 		assert.Nil(t, err)
 		assert.True(t, slices.ContainsFunc(collection, func(item *models.CollectionItem) bool {
 			return item.ID == changed.ID && changed.Status == models.StatusDropped
 		}))
 	})
 
+	t.Run("should track reading dates", func(t *testing.T) {
+		ctx, _ := NewUser(t)
+		item := AddItemToUserCollection(t, ctx, book.ID)
+
+		assert.NotNil(t, item.StartedAt)
+		assert.Nil(t, item.FinishedAt)
+
+		read, err := resolver.Mutation().ChangeItemStatus(ctx, item.ID, models.StatusRead, nil)
+		assert.Nil(t, err)
+		assert.NotNil(t, read.FinishedAt)
+		assert.True(t, read.StartedAt.Equal(*item.StartedAt))
+		assert.Equal(t, 0, read.Rereads)
+	})
+
+	t.Run("should count rereads", func(t *testing.T) {
+		ctx, _ := NewUser(t)
+		item := AddItemToUserCollection(t, ctx, book.ID)
+
+		_, err := resolver.Mutation().ChangeItemStatus(ctx, item.ID, models.StatusRead, nil)
+		assert.Nil(t, err)
+
+		reread, err := resolver.Mutation().ChangeItemStatus(ctx, item.ID, models.StatusReading, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, reread.Rereads)
+		assert.Nil(t, reread.FinishedAt)
+	})
+
+	t.Run("should let read books be queued again or dropped", func(t *testing.T) {
+		ctx, _ := NewUser(t)
+		item := AddItemToUserCollection(t, ctx, book.ID)
+
+		for _, status := range []models.Status{models.StatusToRead, models.StatusDropped} {
+			_, err := resolver.Mutation().ChangeItemStatus(ctx, item.ID, models.StatusRead, nil)
+			assert.Nil(t, err)
+
+			changed, err := resolver.Mutation().ChangeItemStatus(ctx, item.ID, status, nil)
+			assert.Nil(t, err)
+			assert.Equal(t, status, changed.Status)
+			assert.Nil(t, changed.FinishedAt)
+		}
+	})
+
+	t.Run("should accept explicit dates", func(t *testing.T) {
+		ctx, _ := NewUser(t)
+		item := AddItemToUserCollection(t, ctx, book.ID)
+		startedAt := time.Date(2019, time.March, 2, 0, 0, 0, 0, time.UTC)
+		finishedAt := time.Date(2019, time.April, 10, 0, 0, 0, 0, time.UTC)
+
+		read, err := resolver.Mutation().ChangeItemStatus(ctx, item.ID, models.StatusRead, &models.ReadingDates{
+			StartedAt:  &startedAt,
+			FinishedAt: &finishedAt,
+		})
+
+		assert.Nil(t, err)
+		assert.True(t, read.StartedAt.Equal(startedAt))
+		assert.True(t, read.FinishedAt.Equal(finishedAt))
+	})
+
+	t.Run("should fail if dates are invalid", func(t *testing.T) {
+		ctx, _ := NewUser(t)
+		item := AddItemToUserCollection(t, ctx, book.ID)
+		future := time.Now().Add(24 * time.Hour)
+		past := time.Date(2000, time.January, 1, 0, 0, 0, 0, time.UTC)
+
+		got, err := resolver.Mutation().ChangeItemStatus(ctx, item.ID, models.StatusRead, &models.ReadingDates{
+			FinishedAt: &future,
+		})
+		assert.Nil(t, got)
+		assert.ErrorAs(t, err, ErrAsPointer(resolvers.ErrBadArgument("finishedAt", "")))
+
+		got, err = resolver.Mutation().ChangeItemStatus(ctx, item.ID, models.StatusRead, &models.ReadingDates{
+			FinishedAt: &past,
+		})
+		assert.Nil(t, got)
+		assert.ErrorAs(t, err, ErrAsPointer(resolvers.ErrBadArgument("finishedAt", "")))
+
+		got, err = resolver.Mutation().ChangeItemStatus(ctx, item.ID, models.StatusDropped, &models.ReadingDates{
+			FinishedAt: &past,
+		})
+		assert.Nil(t, got)
+		assert.ErrorAs(t, err, ErrAsPointer(resolvers.ErrBadArgument("finishedAt", "")))
+	})
+
+	t.Run("should fail if transition is not allowed", func(t *testing.T) {
+		ctx, _ := NewUser(t)
+		item := AddItemToUserCollection(t, ctx, book.ID)
+
+		_, err := resolver.Mutation().ChangeItemStatus(ctx, item.ID, models.StatusRead, nil)
+		assert.Nil(t, err)
+
+		got, err := resolver.Mutation().ChangeItemStatus(ctx, item.ID, models.StatusOnHold, nil)
+		assert.Nil(t, got)
+		assert.ErrorAs(t, err, ErrAsPointer(resolvers.ErrBadTransition(models.StatusRead, models.StatusOnHold)))
+	})
+
 	t.Run("should fail it user does not own item id", func(t *testing.T) {
 		ctx1, _ := NewUser(t)
 		ctx2, _ := NewUser(t)
 		item := AddItemToUserCollection(t, ctx1, book.ID)
-		got, err := resolver.Mutation().ChangeItemStatus(ctx2, item.ID, models.StatusOnHold)
+		got, err := resolver.Mutation().ChangeItemStatus(ctx2, item.ID, models.StatusOnHold, nil)
 
 		assert.Nil(t, got)
 		assert.ErrorAs(t, err, ErrAsPointer(resolvers.ErrBadId(item.ID, "collectionItem")))
@@ -202,7 +302,7 @@ func TestChangeItemStatus(t *testing.T) {
 	t.Run("should fail if item id does not exist", func(t *testing.T) {
 		ctx, _ := NewUser(t)
 		id := uint(rand.Uint32())
-		got, err := resolver.Mutation().ChangeItemStatus(ctx, id, models.StatusOnHold)
+		got, err := resolver.Mutation().ChangeItemStatus(ctx, id, models.StatusOnHold, nil)
 
 		assert.Nil(t, got)
 		assert.ErrorAs(t, err, ErrAsPointer(resolvers.ErrBadId(id, "collectionItem")))
@@ -212,9 +312,317 @@ func TestChangeItemStatus(t *testing.T) {
 		ctx, _ := NewUser(t)
 		item := AddItemToUserCollection(t, ctx, book.ID)
 		ctx = auth.AddSessionToContext(ctx, nil)
-		got, err := resolver.Mutation().ChangeItemStatus(ctx, item.ID, models.StatusOnHold)
+		got, err := resolver.Mutation().ChangeItemStatus(ctx, item.ID, models.StatusOnHold, nil)
 
 		assert.Nil(t, got)
 		assert.ErrorIs(t, err, resolvers.ErrUnauthorized)
 	})
 }
+
+func TestCollectionItemHistory(t *testing.T) {
+	resolver := NewResolver()
+	ctx, _ := NewUser(t)
+	book := ApproveBook(t, CreateBook(t, ctx))
+
+	t.Run("should record every status change", func(t *testing.T) {
+		ctx, _ := NewUser(t)
+		item := AddItemToUserCollection(t, ctx, book.ID)
+
+		_, err := resolver.Mutation().ChangeItemStatus(ctx, item.ID, models.StatusOnHold, nil)
+		assert.Nil(t, err)
+		_, err = resolver.Mutation().ChangeItemStatus(ctx, item.ID, models.StatusRead, nil)
+		assert.Nil(t, err)
+
+		history, err := resolver.CollectionItem().History(ctx, item)
+		assert.Nil(t, err)
+		assert.Len(t, history, 3)
+		assert.Equal(t, models.StatusReading, history[0].Status)
+		assert.Equal(t, models.StatusOnHold, history[1].Status)
+		assert.Equal(t, models.StatusRead, history[2].Status)
+	})
+
+	t.Run("should group history into read-throughs", func(t *testing.T) {
+		ctx, _ := NewUser(t)
+		item := AddItemToUserCollection(t, ctx, book.ID)
+		startedAt := time.Date(2018, time.June, 1, 0, 0, 0, 0, time.UTC)
+		finishedAt := time.Date(2018, time.June, 11, 0, 0, 0, 0, time.UTC)
+
+		_, err := resolver.Mutation().ChangeItemStatus(ctx, item.ID, models.StatusRead, &models.ReadingDates{
+			StartedAt:  &startedAt,
+			FinishedAt: &finishedAt,
+		})
+		assert.Nil(t, err)
+		_, err = resolver.Mutation().ChangeItemStatus(ctx, item.ID, models.StatusReading, nil)
+		assert.Nil(t, err)
+
+		reads, err := resolver.CollectionItem().Reads(ctx, item)
+		assert.Nil(t, err)
+		assert.Len(t, reads, 2)
+
+		assert.Equal(t, models.StatusRead, reads[0].Status)
+		assert.True(t, reads[0].StartedAt.Equal(startedAt))
+		assert.True(t, reads[0].FinishedAt.Equal(finishedAt))
+		assert.Equal(t, 10, *reads[0].DurationDays)
+
+		assert.Equal(t, models.StatusReading, reads[1].Status)
+		assert.NotNil(t, reads[1].StartedAt)
+		assert.Nil(t, reads[1].FinishedAt)
+		assert.Nil(t, reads[1].DurationDays)
+	})
+
+	t.Run("should not count books yet to be read as reads", func(t *testing.T) {
+		ctx, _ := NewUser(t)
+		item, err := resolver.Mutation().AddToCollection(ctx, book.ID, nil)
+		assert.Nil(t, err)
+
+		reads, err := resolver.CollectionItem().Reads(ctx, item)
+		assert.Nil(t, err)
+		assert.Empty(t, reads)
+	})
+}
+
+func TestUpdateProgress(t *testing.T) {
+	resolver := NewResolver()
+	ctx, _ := NewUser(t)
+	pageCount := 200
+	book, err := resolver.Mutation().CreateBook(ctx, models.CreateBook{
+		Title:     "O cortiço",
+		Isbn:      RandomIsbn(),
+		PageCount: &pageCount,
+	})
+
+	assert.Nil(t, err)
+	book = ApproveBook(t, book)
+
+	t.Run("should update progress by page", func(t *testing.T) {
+		ctx, _ := NewUser(t)
+		item := AddItemToUserCollection(t, ctx, book.ID)
+		page := 50
+		minutes := 30
+
+		got, err := resolver.Mutation().UpdateProgress(ctx, item.ID, models.ProgressUpdate{Page: &page, Minutes: &minutes})
+		assert.Nil(t, err)
+		assert.Equal(t, 50, got.Progress)
+		assert.Equal(t, 25.0, *got.PercentComplete)
+		assert.Equal(t, models.StatusReading, got.Status)
+
+		sessions, err := resolver.CollectionItem().Sessions(ctx, got)
+		assert.Nil(t, err)
+		assert.Len(t, sessions, 1)
+		assert.Equal(t, 50, sessions[0].PagesRead)
+		assert.Equal(t, 30, sessions[0].Minutes)
+	})
+
+	t.Run("should update progress by percent", func(t *testing.T) {
+		ctx, _ := NewUser(t)
+		item := AddItemToUserCollection(t, ctx, book.ID)
+		percent := 40.0
+
+		got, err := resolver.Mutation().UpdateProgress(ctx, item.ID, models.ProgressUpdate{Percent: &percent})
+		assert.Nil(t, err)
+		assert.Equal(t, 80, got.Progress)
+		assert.Equal(t, 40.0, *got.PercentComplete)
+	})
+
+	t.Run("should mark item as read on the last page", func(t *testing.T) {
+		ctx, _ := NewUser(t)
+		item := AddItemToUserCollection(t, ctx, book.ID)
+
+		got, err := resolver.Mutation().UpdateProgress(ctx, item.ID, models.ProgressUpdate{Page: &pageCount})
+		assert.Nil(t, err)
+		assert.Equal(t, models.StatusRead, got.Status)
+		assert.NotNil(t, got.FinishedAt)
+
+		history, err := resolver.CollectionItem().History(ctx, got)
+		assert.Nil(t, err)
+		assert.Equal(t, models.StatusRead, history[len(history)-1].Status)
+	})
+
+	t.Run("should only mark item as read at 100 percent", func(t *testing.T) {
+		ctx, _ := NewUser(t)
+		item := AddItemToUserCollection(t, ctx, book.ID)
+		percent := 99.9
+
+		got, err := resolver.Mutation().UpdateProgress(ctx, item.ID, models.ProgressUpdate{Percent: &percent})
+		assert.Nil(t, err)
+		assert.Equal(t, pageCount, got.Progress)
+		assert.Equal(t, models.StatusReading, got.Status)
+		assert.Nil(t, got.FinishedAt)
+
+		percent = 100
+		got, err = resolver.Mutation().UpdateProgress(ctx, item.ID, models.ProgressUpdate{Percent: &percent})
+		assert.Nil(t, err)
+		assert.Equal(t, models.StatusRead, got.Status)
+	})
+
+	t.Run("should start reading books yet to be read", func(t *testing.T) {
+		ctx, _ := NewUser(t)
+		item, err := resolver.Mutation().AddToCollection(ctx, book.ID, nil)
+		assert.Nil(t, err)
+		page := 10
+
+		got, err := resolver.Mutation().UpdateProgress(ctx, item.ID, models.ProgressUpdate{Page: &page})
+		assert.Nil(t, err)
+		assert.Equal(t, models.StatusReading, got.Status)
+		assert.NotNil(t, got.StartedAt)
+	})
+
+	t.Run("should fail if progress is invalid", func(t *testing.T) {
+		ctx, _ := NewUser(t)
+		item := AddItemToUserCollection(t, ctx, book.ID)
+		page := pageCount + 1
+		percent := 10.0
+
+		got, err := resolver.Mutation().UpdateProgress(ctx, item.ID, models.ProgressUpdate{Page: &page})
+		assert.Nil(t, got)
+		assert.ErrorAs(t, err, ErrAsPointer(resolvers.ErrBadArgument("page", "")))
+
+		got, err = resolver.Mutation().UpdateProgress(ctx, item.ID, models.ProgressUpdate{})
+		assert.Nil(t, got)
+		assert.ErrorAs(t, err, ErrAsPointer(resolvers.ErrBadArgument("progress", "")))
+
+		got, err = resolver.Mutation().UpdateProgress(ctx, item.ID, models.ProgressUpdate{Page: &page, Percent: &percent})
+		assert.Nil(t, got)
+		assert.ErrorAs(t, err, ErrAsPointer(resolvers.ErrBadArgument("progress", "")))
+	})
+
+	t.Run("should fail if user does not own item id", func(t *testing.T) {
+		ctx1, _ := NewUser(t)
+		ctx2, _ := NewUser(t)
+		item := AddItemToUserCollection(t, ctx1, book.ID)
+		page := 10
+
+		got, err := resolver.Mutation().UpdateProgress(ctx2, item.ID, models.ProgressUpdate{Page: &page})
+		assert.Nil(t, got)
+		assert.ErrorAs(t, err, ErrAsPointer(resolvers.ErrBadId(item.ID, "collectionItem")))
+	})
+}
+
+func TestRateItem(t *testing.T) {
+	resolver := NewResolver()
+
+	t.Run("should keep book ratings up to date", func(t *testing.T) {
+		ctx1, _ := NewUser(t)
+		ctx2, _ := NewUser(t)
+		book := ApproveBook(t, CreateBook(t, ctx1))
+		item1 := AddItemToUserCollection(t, ctx1, book.ID)
+		item2 := AddItemToUserCollection(t, ctx2, book.ID)
+		four, five, two := 4.0, 5.0, 2.0
+
+		rated, err := resolver.Mutation().RateItem(ctx1, item1.ID, &four)
+		assert.Nil(t, err)
+		assert.Equal(t, 4.0, *rated.Rating)
+
+		_, err = resolver.Mutation().RateItem(ctx2, item2.ID, &five)
+		assert.Nil(t, err)
+
+		got, err := resolver.Query().Book(ctx1, book.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, 2, got.RatingCount)
+		assert.Equal(t, 4.5, *got.AverageRating())
+
+		_, err = resolver.Mutation().RateItem(ctx1, item1.ID, &two)
+		assert.Nil(t, err)
+		got, _ = resolver.Query().Book(ctx1, book.ID)
+		assert.Equal(t, 2, got.RatingCount)
+		assert.Equal(t, 3.5, *got.AverageRating())
+
+		_, err = resolver.Mutation().RateItem(ctx1, item1.ID, nil)
+		assert.Nil(t, err)
+		got, _ = resolver.Query().Book(ctx1, book.ID)
+		assert.Equal(t, 1, got.RatingCount)
+		assert.Equal(t, 5.0, *got.AverageRating())
+
+		_, err = resolver.Mutation().DeleteFromCollection(ctx2, item2.ID)
+		assert.Nil(t, err)
+		got, _ = resolver.Query().Book(ctx1, book.ID)
+		assert.Equal(t, 0, got.RatingCount)
+		assert.Nil(t, got.AverageRating())
+	})
+
+	t.Run("should fail if rating is not in half steps", func(t *testing.T) {
+		ctx, _ := NewUser(t)
+		book := ApproveBook(t, CreateBook(t, ctx))
+		item := AddItemToUserCollection(t, ctx, book.ID)
+
+		for _, rating := range []float64{0, 0.3, 5.5, -1} {
+			got, err := resolver.Mutation().RateItem(ctx, item.ID, &rating)
+			assert.Nil(t, got)
+			assert.ErrorAs(t, err, ErrAsPointer(resolvers.ErrBadArgument("rating", "")))
+		}
+	})
+
+	t.Run("should fail if user does not own item id", func(t *testing.T) {
+		ctx1, _ := NewUser(t)
+		ctx2, _ := NewUser(t)
+		book := ApproveBook(t, CreateBook(t, ctx1))
+		item := AddItemToUserCollection(t, ctx1, book.ID)
+		rating := 3.0
+
+		got, err := resolver.Mutation().RateItem(ctx2, item.ID, &rating)
+		assert.Nil(t, got)
+		assert.ErrorAs(t, err, ErrAsPointer(resolvers.ErrBadId(item.ID, "collectionItem")))
+	})
+}
+
+func TestReviewItem(t *testing.T) {
+	resolver := NewResolver()
+	spoiler := true
+
+	t.Run("should review item", func(t *testing.T) {
+		ctx, _ := NewUser(t)
+		book := ApproveBook(t, CreateBook(t, ctx))
+		item := AddItemToUserCollection(t, ctx, book.ID)
+		review := "The **ending** got me"
+
+		got, err := resolver.Mutation().ReviewItem(ctx, item.ID, &review, &spoiler)
+		assert.Nil(t, err)
+		assert.Equal(t, review, *got.Review)
+		assert.True(t, got.Spoiler)
+		assert.NotNil(t, got.ReviewedAt)
+
+		got, err = resolver.Mutation().ReviewItem(ctx, item.ID, nil, nil)
+		assert.Nil(t, err)
+		assert.Nil(t, got.Review)
+		assert.False(t, got.Spoiler)
+		assert.Nil(t, got.ReviewedAt)
+	})
+
+	t.Run("should follow collection visibility", func(t *testing.T) {
+		ctx1, _ := NewUser(t)
+		ctx2, _ := NewUser(t)
+		ctx3, _ := NewUser(t)
+		UpdateSettings(t, ctx1, &models.UpdateSettings{ShowCollection: true})
+		UpdateSettings(t, ctx2, &models.UpdateSettings{ShowCollection: false})
+		book := ApproveBook(t, CreateBook(t, ctx1))
+		item1 := AddItemToUserCollection(t, ctx1, book.ID)
+		item2 := AddItemToUserCollection(t, ctx2, book.ID)
+		review := "Great"
+
+		_, err := resolver.Mutation().ReviewItem(ctx1, item1.ID, &review, nil)
+		assert.Nil(t, err)
+		_, err = resolver.Mutation().ReviewItem(ctx2, item2.ID, &review, nil)
+		assert.Nil(t, err)
+
+		page, err := resolver.Book().Reviews(ctx3, book, nil, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, page.TotalCount)
+		assert.Equal(t, item1.ID, page.Reviews[0].ID)
+		assert.Equal(t, review, page.Reviews[0].Body)
+
+		page, err = resolver.Book().Reviews(ctx2, book, nil, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, 2, page.TotalCount)
+	})
+
+	t.Run("should fail if review is empty", func(t *testing.T) {
+		ctx, _ := NewUser(t)
+		book := ApproveBook(t, CreateBook(t, ctx))
+		item := AddItemToUserCollection(t, ctx, book.ID)
+		review := "  "
+
+		got, err := resolver.Mutation().ReviewItem(ctx, item.ID, &review, nil)
+		assert.Nil(t, got)
+		assert.ErrorAs(t, err, ErrAsPointer(resolvers.ErrBadArgument("review", "")))
+	})
+}
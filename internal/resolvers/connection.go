@@ -0,0 +1,130 @@
+package resolvers
+
+import (
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/marcos-brito/booklist/internal/models"
+)
+
+const cursorPrefix = "cursor:"
+
+// Cursors are the id of the row, encoded so clients don't rely on it.
+func encodeCursor(id uint) string {
+	return base64.RawURLEncoding.EncodeToString([]byte(cursorPrefix + strconv.FormatUint(uint64(id), 10)))
+}
+
+func decodeCursor(arg string, cursor *string) (*uint, error) {
+	if cursor == nil {
+		return nil, nil
+	}
+
+	decoded, err := base64.RawURLEncoding.DecodeString(*cursor)
+	if err != nil || !strings.HasPrefix(string(decoded), cursorPrefix) {
+		return nil, ErrBadArgument(arg, "must be a cursor from the same connection")
+	}
+
+	id, err := strconv.ParseUint(strings.TrimPrefix(string(decoded), cursorPrefix), 10, 0)
+	if err != nil {
+		return nil, ErrBadArgument(arg, "must be a cursor from the same connection")
+	}
+
+	value := uint(id)
+	return &value, nil
+}
+
+// Resolves the arguments of a connection field into a page. Without
+// first or last, the first page of the default size is taken.
+func checkConnection(first *int, after *string, last *int, before *string) (*models.Page, error) {
+	if first != nil && last != nil {
+		return nil, ErrBadArgument("last", "can't be used with first")
+	}
+
+	page := &models.Page{Limit: defaultPageSize}
+	if last != nil {
+		page.Limit = *last
+		page.Backward = true
+	} else if first != nil {
+		page.Limit = *first
+	}
+
+	if page.Limit < 0 || page.Limit > maxPageSize {
+		arg := "first"
+		if page.Backward {
+			arg = "last"
+		}
+
+		return nil, ErrBadArgument(arg, fmt.Sprintf("must be between 0 and %d", maxPageSize))
+	}
+
+	var err error
+	page.After, err = decodeCursor("after", after)
+	if err != nil {
+		return nil, err
+	}
+
+	page.Before, err = decodeCursor("before", before)
+	if err != nil {
+		return nil, err
+	}
+
+	return page, nil
+}
+
+// Describes a page given the ids of its rows. Whether there are rows on
+// the side the page wasn't taken from is only known from its cursor, as
+// the spec allows.
+func newPageInfo(ids []uint, page *models.Page, more bool) *models.PageInfo {
+	info := &models.PageInfo{
+		HasNextPage:     more,
+		HasPreviousPage: page.After != nil,
+	}
+
+	if page.Backward {
+		info.HasNextPage = page.Before != nil
+		info.HasPreviousPage = more
+	}
+
+	if len(ids) > 0 {
+		start, end := encodeCursor(ids[0]), encodeCursor(ids[len(ids)-1])
+		info.StartCursor = &start
+		info.EndCursor = &end
+	}
+
+	return info
+}
+
+func newItemConnection(items []*models.CollectionItem, page *models.Page, more bool, total int64) *models.CollectionItemConnection {
+	ids := []uint{}
+	edges := []*models.CollectionItemEdge{}
+	for _, item := range items {
+		ids = append(ids, item.ID)
+		edges = append(edges, &models.CollectionItemEdge{Cursor: encodeCursor(item.ID), Node: item})
+	}
+
+	return &models.CollectionItemConnection{Edges: edges, PageInfo: newPageInfo(ids, page, more), TotalCount: int(total)}
+}
+
+func newListConnection(lists []*models.List, page *models.Page, more bool, total int64) *models.ListConnection {
+	ids := []uint{}
+	edges := []*models.ListEdge{}
+	for _, list := range lists {
+		ids = append(ids, list.ID)
+		edges = append(edges, &models.ListEdge{Cursor: encodeCursor(list.ID), Node: list})
+	}
+
+	return &models.ListConnection{Edges: edges, PageInfo: newPageInfo(ids, page, more), TotalCount: int(total)}
+}
+
+func newBookConnection(books []*models.Book, page *models.Page, more bool, total int64) *models.BookConnection {
+	ids := []uint{}
+	edges := []*models.BookEdge{}
+	for _, book := range books {
+		ids = append(ids, book.ID)
+		edges = append(edges, &models.BookEdge{Cursor: encodeCursor(book.ID), Node: book})
+	}
+
+	return &models.BookConnection{Edges: edges, PageInfo: newPageInfo(ids, page, more), TotalCount: int(total)}
+}
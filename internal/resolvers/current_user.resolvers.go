@@ -6,16 +6,16 @@ package resolvers
 
 import (
 	"context"
+	"errors"
 
 	"github.com/marcos-brito/booklist/internal/auth"
-	"github.com/marcos-brito/booklist/internal/conn"
 	"github.com/marcos-brito/booklist/internal/models"
-	"github.com/marcos-brito/booklist/internal/store"
+	"gorm.io/gorm"
 )
 
 // Settings is the resolver for the settings field.
 func (r *currentUserResolver) Settings(ctx context.Context, obj *models.CurrentUser) (*models.Settings, error) {
-	settings, err := store.NewUserStore(conn.DB).FindSettingsByUserUuid(obj.UUID)
+	settings, err := r.Repos.Users.FindSettingsByUserUuid(obj.UUID)
 	if err != nil {
 		return nil, ErrInternal
 	}
@@ -25,7 +25,7 @@ func (r *currentUserResolver) Settings(ctx context.Context, obj *models.CurrentU
 
 // Lists is the resolver for the lists field.
 func (r *currentUserResolver) Lists(ctx context.Context, obj *models.CurrentUser) ([]*models.List, error) {
-	lists, err := store.NewUserStore(conn.DB).FindLists(obj.UUID)
+	lists, err := r.Repos.Users.FindLists(obj.UUID)
 	if err != nil {
 		return nil, ErrInternal
 	}
@@ -33,9 +33,35 @@ func (r *currentUserResolver) Lists(ctx context.Context, obj *models.CurrentUser
 	return lists, nil
 }
 
+// ListsConnection is the resolver for the listsConnection field.
+func (r *currentUserResolver) ListsConnection(ctx context.Context, obj *models.CurrentUser, first *int, after *string, last *int, before *string) (*models.ListConnection, error) {
+	page, err := checkConnection(first, after, last, before)
+	if err != nil {
+		return nil, err
+	}
+
+	userStore := r.Repos.Users
+	lists, more, err := userStore.FindListsPage(obj.UUID, true, page)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	total, err := userStore.CountLists(obj.UUID, true)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	return newListConnection(lists, page, more, total), nil
+}
+
 // Collection is the resolver for the collection field.
-func (r *currentUserResolver) Collection(ctx context.Context, obj *models.CurrentUser) ([]*models.CollectionItem, error) {
-	items, err := store.NewUserStore(conn.DB).FindItems(obj.UUID)
+func (r *currentUserResolver) Collection(ctx context.Context, obj *models.CurrentUser, filter *models.CollectionFilter, sort *models.CollectionSort) ([]*models.CollectionItem, error) {
+	err := checkCollectionFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	items, err := r.Repos.Users.FindItems(obj.UUID, filter, sort)
 	if err != nil {
 		return nil, ErrInternal
 	}
@@ -43,6 +69,107 @@ func (r *currentUserResolver) Collection(ctx context.Context, obj *models.Curren
 	return items, nil
 }
 
+// CollectionConnection is the resolver for the collectionConnection field.
+func (r *currentUserResolver) CollectionConnection(ctx context.Context, obj *models.CurrentUser, filter *models.CollectionFilter, sort *models.CollectionSort, first *int, after *string, last *int, before *string) (*models.CollectionItemConnection, error) {
+	page, err := checkConnection(first, after, last, before)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkCollectionFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	userStore := r.Repos.Users
+	items, more, err := userStore.FindItemsPage(obj.UUID, filter, sort, page)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	total, err := userStore.CountItems(obj.UUID, filter)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	return newItemConnection(items, page, more, total), nil
+}
+
+// FollowedLists is the resolver for the followedLists field.
+func (r *currentUserResolver) FollowedLists(ctx context.Context, obj *models.CurrentUser) ([]*models.List, error) {
+	lists, err := r.Repos.Users.FindFollowedLists(obj.UUID)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	return lists, nil
+}
+
+// SubmittedBooks is the resolver for the submittedBooks field.
+func (r *currentUserResolver) SubmittedBooks(ctx context.Context, obj *models.CurrentUser, limit *int, offset *int) (*models.BookPage, error) {
+	limitValue, offsetValue, err := checkPage(limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	bookStore := r.Repos.Books
+	books, err := bookStore.FindSubmitted(obj.UUID, limitValue, offsetValue)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	count, err := bookStore.CountSubmitted(obj.UUID)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	page := &models.BookPage{
+		Books:      books,
+		TotalCount: int(count),
+	}
+
+	return page, nil
+}
+
+// Stats is the resolver for the stats field.
+func (r *currentUserResolver) Stats(ctx context.Context, obj *models.CurrentUser, rangeArg *models.StatsRange) (*models.Stats, error) {
+	viewer, err := r.viewerProfile(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkStatsRange(rangeArg)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := r.Repos.Users.FindStats(obj.UUID, rangeArg, viewer)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	return stats, nil
+}
+
+// ReadingGoal is the resolver for the readingGoal field.
+func (r *currentUserResolver) ReadingGoal(ctx context.Context, obj *models.CurrentUser, year int) (*models.ReadingGoal, error) {
+	profile, err := r.Repos.Users.FindProfileByUserUuid(obj.UUID)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	goal, err := r.Repos.Goals.FindByYear(profile.ID, year)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil, nil
+	}
+
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	return goal, nil
+}
+
 // UpdateSettings is the resolver for the updateSettings field.
 func (r *mutationResolver) UpdateSettings(ctx context.Context, changes models.UpdateSettings) (*models.Settings, error) {
 	_, ident, ok := auth.GetSession(ctx)
@@ -50,7 +177,7 @@ func (r *mutationResolver) UpdateSettings(ctx context.Context, changes models.Up
 		return nil, ErrUnauthorized
 	}
 
-	settings, err := store.NewUserStore(conn.DB).UpdateSettings(ident.UUID, changes)
+	settings, err := r.Repos.Users.UpdateSettings(ident.UUID, changes)
 	if err != nil {
 		return nil, ErrInternal
 	}
@@ -58,6 +185,34 @@ func (r *mutationResolver) UpdateSettings(ctx context.Context, changes models.Up
 	return settings, nil
 }
 
+// DeleteAccount is the resolver for the deleteAccount field.
+func (r *mutationResolver) DeleteAccount(ctx context.Context, confirmation string) (bool, error) {
+	_, ident, ok := auth.GetSession(ctx)
+	if !ok {
+		return false, ErrUnauthorized
+	}
+
+	if confirmation != ident.Traits.Email {
+		return false, ErrBadArgument("confirmation", "must be the email of the account")
+	}
+
+	// The identity goes first, so nothing is lost if Ory fails. If the
+	// purge fails instead, the deletion hook of Ory runs it again.
+	if r.Identities != nil {
+		err := r.Identities.DeleteIdentity(ident.UUID)
+		if err != nil {
+			return false, ErrInternal
+		}
+	}
+
+	err := r.Repos.Users.DeleteAccount(ident.UUID)
+	if err != nil {
+		return false, ErrInternal
+	}
+
+	return true, nil
+}
+
 // Me is the resolver for the me field.
 func (r *queryResolver) Me(ctx context.Context) (*models.CurrentUser, error) {
 	_, ident, ok := auth.GetSession(ctx)
@@ -65,15 +220,16 @@ func (r *queryResolver) Me(ctx context.Context) (*models.CurrentUser, error) {
 		return nil, nil
 	}
 
-	_, err := store.NewUserStore(conn.DB).FindProfileByUserUuid(ident.UUID)
+	profile, err := r.Repos.Users.FindProfileByUserUuid(ident.UUID)
 	if err != nil {
 		return nil, ErrInternal
 	}
 
 	user := &models.CurrentUser{
-		UUID:  ident.UUID,
-		Name:  ident.Traits.Name,
-		Email: ident.Traits.Email,
+		UUID:      ident.UUID,
+		Name:      ident.Traits.Name,
+		Email:     ident.Traits.Email,
+		Moderator: profile.Moderator,
 	}
 
 	return user, nil
@@ -82,8 +238,4 @@ func (r *queryResolver) Me(ctx context.Context) (*models.CurrentUser, error) {
 // CurrentUser returns CurrentUserResolver implementation.
 func (r *Resolver) CurrentUser() CurrentUserResolver { return &currentUserResolver{r} }
 
-// Query returns QueryResolver implementation.
-func (r *Resolver) Query() QueryResolver { return &queryResolver{r} }
-
 type currentUserResolver struct{ *Resolver }
-type queryResolver struct{ *Resolver }
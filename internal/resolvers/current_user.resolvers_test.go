@@ -2,87 +2,80 @@ package resolvers_test
 
 import (
 	"context"
-	"log"
-	"os"
+	"fmt"
+	"math/rand/v2"
+	"net/http"
+	"net/http/httptest"
 	"slices"
+	"strings"
 	"testing"
 	"time"
 
-	"github.com/joho/godotenv"
+	"github.com/google/uuid"
 	"github.com/marcos-brito/booklist/internal/auth"
-	"github.com/marcos-brito/booklist/internal/conn"
+	"github.com/marcos-brito/booklist/internal/hooks"
+	"github.com/marcos-brito/booklist/internal/importer"
 	"github.com/marcos-brito/booklist/internal/models"
 	"github.com/marcos-brito/booklist/internal/resolvers"
+	"github.com/marcos-brito/booklist/internal/store"
+	"github.com/marcos-brito/booklist/internal/store/memory"
+	"github.com/marcos-brito/booklist/internal/testdb"
 	"github.com/stretchr/testify/assert"
-	"github.com/testcontainers/testcontainers-go"
-	"github.com/testcontainers/testcontainers-go/modules/postgres"
-	"github.com/testcontainers/testcontainers-go/wait"
+	"gorm.io/gorm"
 )
 
-func TestMain(m *testing.M) {
-	teardown := Setup()
-	defer teardown()
-	code := m.Run()
-	os.Exit(code)
-}
+// The backend that runs the tests over the memory stores instead of a
+// database.
+const memoryBackend = "memory"
 
-func Setup() func() {
-	err := godotenv.Load("../../.env")
-	if err != nil {
-		log.Fatal(err)
-	}
+// The database and repositories the tests run against, set up once for
+// each backend. There is no database for the memory stores.
+var (
+	db    *gorm.DB
+	repos *store.Repositories
+)
 
-	container := StartPostgres()
-	db, err := conn.NewPostgresConnection()
-	if err != nil {
-		log.Fatal(err)
-	}
+// The tests run once for every database the server supports and once
+// over the memory stores, or only against the one in TEST_DATABASE if
+// it's set.
+func TestMain(m *testing.M) {
+	testdb.Main(m, append(slices.Clone(testdb.Drivers), memoryBackend), Setup)
+}
 
-	conn.InitDatabase(db)
-	err = conn.Migrate(db)
-	if err != nil {
-		log.Fatal(err)
+func Setup(driver string) func() {
+	if driver == memoryBackend {
+		db = nil
+		repos = memory.NewRepositories(memory.NewDB())
+		return func() {}
 	}
 
-	return func() {
-		if err := testcontainers.TerminateContainer(container); err != nil {
-			log.Fatalf("failed to terminate container: %s", err)
-		}
-	}
+	var teardown func()
+	db, teardown = testdb.Open(driver)
+	repos = store.NewRepositories(db)
+	return teardown
 }
 
-func StartPostgres() testcontainers.Container {
-	ctx := context.Background()
-	container, err := postgres.Run(ctx,
-		"postgres:16-alpine",
-		postgres.WithDatabase(os.Getenv("POSTGRES_DB")),
-		postgres.WithUsername(os.Getenv("POSTGRES_USER")),
-		postgres.WithPassword(os.Getenv("POSTGRES_PASSWORD")),
-		testcontainers.WithWaitStrategy(
-			wait.ForLog("database system is ready to accept connections").
-				WithOccurrence(2).
-				WithStartupTimeout(5*time.Second)),
-	)
-
-	if err != nil {
-		log.Fatalf("failed to start container: %s", err)
-	}
-
-	port, err := container.MappedPort(ctx, "5432")
-	if err != nil {
-		log.Fatal(err)
+// Skips tests that need a database, like ones that look at its rows,
+// when running over the memory stores.
+func RequireDatabase(t *testing.T) {
+	if db == nil {
+		t.Skip("needs a database")
 	}
+}
 
-	err = os.Setenv("POSTGRES_PORT", port.Port())
-	if err != nil {
-		log.Fatal(err)
+// Returns a resolver over the test repositories. There is no Redis or
+// identity provider in tests, and imports only run against a database.
+func NewResolver() *resolvers.Resolver {
+	resolver := &resolvers.Resolver{Repos: repos}
+	if db != nil {
+		resolver.Importer = importer.NewDBRunner(db)
 	}
 
-	return container
+	return resolver
 }
 
 func TestSettings(t *testing.T) {
-	resolver := &resolvers.Resolver{}
+	resolver := NewResolver()
 	ctx, profile := NewUser(t)
 	_, err := resolver.CurrentUser().Settings(ctx, profile)
 
@@ -90,7 +83,7 @@ func TestSettings(t *testing.T) {
 }
 
 func TestLists(t *testing.T) {
-	resolver := &resolvers.Resolver{}
+	resolver := NewResolver()
 
 	t.Run("should return user lists", func(t *testing.T) {
 		ctx, user := NewUser(t)
@@ -108,12 +101,12 @@ func TestLists(t *testing.T) {
 }
 
 func TestCollection(t *testing.T) {
-	resolver := &resolvers.Resolver{}
+	resolver := NewResolver()
 	ctx, _ := NewUser(t)
 	inputs := []models.CreateBook{
 		{
 			Title: "O homem de giz",
-			Isbn:  "9788551002933",
+			Isbn:  RandomIsbn(),
 		},
 		{
 			Title: "Arquitetura limpa",
@@ -125,7 +118,7 @@ func TestCollection(t *testing.T) {
 	for _, input := range inputs {
 		book, err := resolver.Mutation().CreateBook(ctx, input)
 		assert.Nil(t, err)
-		books = append(books, book)
+		books = append(books, ApproveBook(t, book))
 	}
 
 	t.Run("should return the user's collection", func(t *testing.T) {
@@ -135,7 +128,7 @@ func TestCollection(t *testing.T) {
 			AddItemToUserCollection(t, ctx, book.ID)
 		}
 
-		got, err := resolver.CurrentUser().Collection(ctx, user)
+		got, err := resolver.CurrentUser().Collection(ctx, user, nil, nil)
 		assert.Nil(t, err)
 		for _, item := range got {
 			assert.True(t, slices.ContainsFunc(books, func(book *models.Book) bool {
@@ -145,8 +138,341 @@ func TestCollection(t *testing.T) {
 	})
 }
 
+func TestCollectionConnection(t *testing.T) {
+	resolver := NewResolver()
+	ctx, user := NewUser(t)
+	items := []*models.CollectionItem{}
+	for range 5 {
+		items = append(items, AddItemToUserCollection(t, ctx, ApproveBook(t, CreateBook(t, ctx)).ID))
+	}
+
+	nodes := func(connection *models.CollectionItemConnection) []uint {
+		ids := []uint{}
+		for _, edge := range connection.Edges {
+			ids = append(ids, edge.Node.ID)
+		}
+
+		return ids
+	}
+
+	two := 2
+	t.Run("should page forward", func(t *testing.T) {
+		first, err := resolver.CurrentUser().CollectionConnection(ctx, user, nil, nil, &two, nil, nil, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, 5, first.TotalCount)
+		assert.Equal(t, []uint{items[0].ID, items[1].ID}, nodes(first))
+		assert.True(t, first.PageInfo.HasNextPage)
+		assert.False(t, first.PageInfo.HasPreviousPage)
+		assert.Equal(t, first.Edges[1].Cursor, *first.PageInfo.EndCursor)
+
+		second, err := resolver.CurrentUser().CollectionConnection(ctx, user, nil, nil, &two, first.PageInfo.EndCursor, nil, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, []uint{items[2].ID, items[3].ID}, nodes(second))
+		assert.True(t, second.PageInfo.HasPreviousPage)
+
+		third, err := resolver.CurrentUser().CollectionConnection(ctx, user, nil, nil, &two, second.PageInfo.EndCursor, nil, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, []uint{items[4].ID}, nodes(third))
+		assert.False(t, third.PageInfo.HasNextPage)
+	})
+
+	t.Run("should page backward", func(t *testing.T) {
+		last, err := resolver.CurrentUser().CollectionConnection(ctx, user, nil, nil, nil, nil, &two, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, []uint{items[3].ID, items[4].ID}, nodes(last))
+		assert.True(t, last.PageInfo.HasPreviousPage)
+		assert.False(t, last.PageInfo.HasNextPage)
+
+		previous, err := resolver.CurrentUser().CollectionConnection(ctx, user, nil, nil, nil, nil, &two, last.PageInfo.StartCursor)
+		assert.Nil(t, err)
+		assert.Equal(t, []uint{items[1].ID, items[2].ID}, nodes(previous))
+		assert.True(t, previous.PageInfo.HasNextPage)
+	})
+
+	t.Run("should keep pages stable when items are removed", func(t *testing.T) {
+		first, err := resolver.CurrentUser().CollectionConnection(ctx, user, nil, nil, &two, nil, nil, nil)
+		assert.Nil(t, err)
+
+		_, err = resolver.Mutation().DeleteFromCollection(ctx, items[0].ID)
+		assert.Nil(t, err)
+
+		second, err := resolver.CurrentUser().CollectionConnection(ctx, user, nil, nil, &two, first.PageInfo.EndCursor, nil, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, 4, second.TotalCount)
+		assert.Equal(t, []uint{items[2].ID, items[3].ID}, nodes(second))
+	})
+
+	t.Run("should fail with both first and last", func(t *testing.T) {
+		connection, err := resolver.CurrentUser().CollectionConnection(ctx, user, nil, nil, &two, nil, &two, nil)
+		assert.Nil(t, connection)
+		assert.ErrorAs(t, err, ErrAsPointer(resolvers.ErrBadArgument("last", "")))
+	})
+
+	t.Run("should fail if cursor is invalid", func(t *testing.T) {
+		cursor := "not a cursor"
+		connection, err := resolver.CurrentUser().CollectionConnection(ctx, user, nil, nil, nil, &cursor, nil, nil)
+		assert.Nil(t, connection)
+		assert.ErrorAs(t, err, ErrAsPointer(resolvers.ErrBadArgument("after", "")))
+	})
+}
+
+func TestFilteredCollection(t *testing.T) {
+	resolver := NewResolver()
+	ctx, user := NewUser(t)
+	author := ApproveAuthor(t, CreateAuthor(t, ctx))
+	publisher := CreatePublisher(t, ctx)
+	march := time.Date(2023, time.March, 1, 0, 0, 0, 0, time.UTC)
+	may := time.Date(2023, time.May, 1, 0, 0, 0, 0, time.UTC)
+	june := time.Date(2023, time.June, 1, 0, 0, 0, 0, time.UTC)
+	january := time.Date(2024, time.January, 1, 0, 0, 0, 0, time.UTC)
+	two, four, five := 2.0, 4.0, 5.0
+	books := []struct {
+		input    models.CreateBook
+		finished *time.Time
+		rating   *float64
+	}{
+		{models.CreateBook{Title: "delta", Isbn: RandomIsbn(), Authors: []uint{author.ID}}, &march, &four},
+		{models.CreateBook{Title: "Alpha", Isbn: RandomIsbn(), Publisher: &publisher.ID}, &june, &two},
+		{models.CreateBook{Title: "charlie", Isbn: RandomIsbn()}, nil, nil},
+		{models.CreateBook{Title: "Bravo", Isbn: RandomIsbn()}, &january, &five},
+	}
+
+	items := []uint{}
+	for _, book := range books {
+		created, err := resolver.Mutation().CreateBook(ctx, book.input)
+		assert.Nil(t, err)
+		item := AddItemToUserCollection(t, ctx, ApproveBook(t, created).ID)
+		if book.finished != nil {
+			dates := &models.ReadingDates{StartedAt: &march, FinishedAt: book.finished}
+			_, err = resolver.Mutation().ChangeItemStatus(ctx, item.ID, models.StatusRead, dates)
+			assert.Nil(t, err)
+		}
+
+		if book.rating != nil {
+			_, err = resolver.Mutation().RateItem(ctx, item.ID, book.rating)
+			assert.Nil(t, err)
+		}
+
+		items = append(items, item.ID)
+	}
+
+	ids := func(items []*models.CollectionItem) []uint {
+		ids := []uint{}
+		for _, item := range items {
+			ids = append(ids, item.ID)
+		}
+
+		return ids
+	}
+
+	nodes := func(connection *models.CollectionItemConnection) []uint {
+		ids := []uint{}
+		for _, edge := range connection.Edges {
+			ids = append(ids, edge.Node.ID)
+		}
+
+		return ids
+	}
+
+	t.Run("should filter", func(t *testing.T) {
+		later := time.Now().Add(time.Hour)
+		filters := []struct {
+			filter *models.CollectionFilter
+			want   []uint
+		}{
+			{&models.CollectionFilter{Statuses: []models.Status{models.StatusReading}}, []uint{items[2]}},
+			{&models.CollectionFilter{Author: &author.ID}, []uint{items[0]}},
+			{&models.CollectionFilter{Publisher: &publisher.ID}, []uint{items[1]}},
+			{&models.CollectionFilter{FinishedFrom: &may}, []uint{items[1], items[3]}},
+			{&models.CollectionFilter{MinRating: &two, MaxRating: &four}, []uint{items[0], items[1]}},
+			{&models.CollectionFilter{AddedFrom: &later}, []uint{}},
+		}
+
+		for _, filter := range filters {
+			got, err := resolver.CurrentUser().Collection(ctx, user, filter.filter, nil)
+			assert.Nil(t, err)
+			assert.Equal(t, filter.want, ids(got))
+
+			connection, err := resolver.CurrentUser().CollectionConnection(ctx, user, filter.filter, nil, nil, nil, nil, nil)
+			assert.Nil(t, err)
+			assert.Equal(t, filter.want, nodes(connection))
+			assert.Equal(t, len(filter.want), connection.TotalCount)
+		}
+	})
+
+	t.Run("should sort titles ignoring case", func(t *testing.T) {
+		got, err := resolver.CurrentUser().Collection(ctx, user, nil, &models.CollectionSort{Field: models.CollectionSortFieldTitle})
+		assert.Nil(t, err)
+		assert.Equal(t, []uint{items[1], items[3], items[2], items[0]}, ids(got))
+	})
+
+	t.Run("should page forward with missing values last", func(t *testing.T) {
+		desc := models.SortDirectionDesc
+		sort := &models.CollectionSort{Field: models.CollectionSortFieldRating, Direction: &desc}
+		got := []uint{}
+		var cursor *string
+		for range books {
+			one := 1
+			connection, err := resolver.CurrentUser().CollectionConnection(ctx, user, nil, sort, &one, cursor, nil, nil)
+			assert.Nil(t, err)
+			got = append(got, nodes(connection)...)
+			cursor = connection.PageInfo.EndCursor
+		}
+
+		assert.Equal(t, []uint{items[3], items[0], items[1], items[2]}, got)
+	})
+
+	t.Run("should page backward with missing values last", func(t *testing.T) {
+		three := 3
+		sort := &models.CollectionSort{Field: models.CollectionSortFieldDateFinished}
+		last, err := resolver.CurrentUser().CollectionConnection(ctx, user, nil, sort, nil, nil, &three, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, []uint{items[1], items[3], items[2]}, nodes(last))
+
+		previous, err := resolver.CurrentUser().CollectionConnection(ctx, user, nil, sort, nil, nil, &three, last.PageInfo.StartCursor)
+		assert.Nil(t, err)
+		assert.Equal(t, []uint{items[0]}, nodes(previous))
+		assert.False(t, previous.PageInfo.HasPreviousPage)
+	})
+
+	t.Run("should fail if a range is reversed", func(t *testing.T) {
+		filter := &models.CollectionFilter{MinRating: &four, MaxRating: &two}
+		got, err := resolver.CurrentUser().Collection(ctx, user, filter, nil)
+		assert.Nil(t, got)
+		assert.ErrorAs(t, err, ErrAsPointer(resolvers.ErrBadArgument("maxRating", "")))
+	})
+}
+
+func TestFollowedLists(t *testing.T) {
+	resolver := NewResolver()
+	ctx, _ := NewUser(t)
+	list := CreateList(t, ctx, true)
+
+	t.Run("should leave out lists unpublished after being followed", func(t *testing.T) {
+		ctx2, user := NewUser(t)
+		FollowList(t, ctx2, list.ID)
+
+		_, err := resolver.Mutation().UnpublishList(ctx, list.ID)
+		assert.Nil(t, err)
+
+		lists, err := resolver.CurrentUser().FollowedLists(ctx2, user)
+		assert.Nil(t, err)
+		assert.Empty(t, lists)
+	})
+}
+
+func TestSubmittedBooks(t *testing.T) {
+	resolver := NewResolver()
+
+	t.Run("should return books added by the user newest first", func(t *testing.T) {
+		ctx, user := NewUser(t)
+		first := CreateBook(t, ctx)
+		second := ApproveBook(t, CreateBook(t, ctx))
+
+		got, err := resolver.CurrentUser().SubmittedBooks(ctx, user, nil, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, 2, got.TotalCount)
+		assert.Equal(t, second.ID, got.Books[0].ID)
+		assert.Equal(t, first.ID, got.Books[1].ID)
+
+		state, err := resolver.Book().ApprovalState(ctx, got.Books[0])
+		assert.Nil(t, err)
+		assert.Equal(t, models.ApprovalStateApproved, state)
+	})
+}
+
+func TestStats(t *testing.T) {
+	resolver := NewResolver()
+	ctx, user := NewUser(t)
+	author := ApproveAuthor(t, CreateAuthor(t, ctx))
+	publisher := CreatePublisher(t, ctx)
+
+	read := func(pageCount int, startedAt, finishedAt time.Time) {
+		book, err := resolver.Mutation().CreateBook(ctx, models.CreateBook{
+			Title:     fmt.Sprintf("Book:%d", rand.Int()),
+			Isbn:      RandomIsbn(),
+			PageCount: &pageCount,
+			Authors:   []uint{author.ID},
+			Publisher: &publisher.ID,
+		})
+		assert.Nil(t, err)
+
+		item := AddItemToUserCollection(t, ctx, book.ID)
+		_, err = resolver.Mutation().ChangeItemStatus(ctx, item.ID, models.StatusRead, &models.ReadingDates{
+			StartedAt:  &startedAt,
+			FinishedAt: &finishedAt,
+		})
+		assert.Nil(t, err)
+	}
+
+	read(100, time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC), time.Date(2021, time.January, 11, 0, 0, 0, 0, time.UTC))
+	read(200, time.Date(2021, time.January, 15, 0, 0, 0, 0, time.UTC), time.Date(2021, time.January, 25, 0, 0, 0, 0, time.UTC))
+	read(300, time.Date(2022, time.March, 1, 0, 0, 0, 0, time.UTC), time.Date(2022, time.March, 31, 0, 0, 0, 0, time.UTC))
+	AddItemToUserCollection(t, ctx, ApproveBook(t, CreateBook(t, ctx)).ID)
+
+	t.Run("should aggregate finished books", func(t *testing.T) {
+		stats, err := resolver.CurrentUser().Stats(ctx, user, nil)
+		assert.Nil(t, err)
+
+		assert.Equal(t, []*models.PeriodStats{
+			{Period: "2021-01", Books: 2, Pages: 300},
+			{Period: "2022-03", Books: 1, Pages: 300},
+		}, stats.FinishedPerMonth)
+		assert.Equal(t, []*models.PeriodStats{
+			{Period: "2021", Books: 2, Pages: 300},
+			{Period: "2022", Books: 1, Pages: 300},
+		}, stats.FinishedPerYear)
+		assert.InDelta(t, 50.0/3, *stats.AverageDaysToFinish, 0.01)
+
+		assert.Equal(t, []*models.StatusCount{
+			{Status: models.StatusRead, Count: 3},
+			{Status: models.StatusReading, Count: 1},
+		}, stats.StatusDistribution)
+
+		assert.Len(t, stats.TopAuthors, 1)
+		assert.Equal(t, author.ID, stats.TopAuthors[0].Author.ID)
+		assert.Equal(t, 3, stats.TopAuthors[0].Count)
+
+		// Publishers waiting for approval are only seen by who submitted them
+		assert.Len(t, stats.TopPublishers, 1)
+		assert.Equal(t, publisher.ID, stats.TopPublishers[0].Publisher.ID)
+	})
+
+	t.Run("should only count books finished within range", func(t *testing.T) {
+		from := time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC)
+		stats, err := resolver.CurrentUser().Stats(ctx, user, &models.StatsRange{From: &from})
+		assert.Nil(t, err)
+
+		assert.Equal(t, []*models.PeriodStats{{Period: "2022", Books: 1, Pages: 300}}, stats.FinishedPerYear)
+		assert.InDelta(t, 30.0, *stats.AverageDaysToFinish, 0.01)
+		assert.Equal(t, 1, stats.TopAuthors[0].Count)
+	})
+
+	t.Run("should hide stats from others unless shown", func(t *testing.T) {
+		other, _ := NewUser(t)
+		UpdateSettings(t, ctx, &models.UpdateSettings{ShowStats: false})
+		stats, err := resolver.User().Stats(other, &models.User{UUID: user.UUID}, nil)
+		assert.Nil(t, err)
+		assert.Nil(t, stats)
+
+		UpdateSettings(t, ctx, &models.UpdateSettings{ShowStats: true})
+		stats, err = resolver.User().Stats(other, &models.User{UUID: user.UUID}, nil)
+		assert.Nil(t, err)
+		assert.Len(t, stats.FinishedPerYear, 2)
+		assert.Empty(t, stats.TopPublishers)
+	})
+
+	t.Run("should fail if range ends before it starts", func(t *testing.T) {
+		from := time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC)
+		to := from.Add(-time.Hour)
+		stats, err := resolver.CurrentUser().Stats(ctx, user, &models.StatsRange{From: &from, To: &to})
+		assert.Nil(t, stats)
+		assert.ErrorAs(t, err, ErrAsPointer(resolvers.ErrBadArgument("range", "")))
+	})
+}
+
 func TestUpdateSettings(t *testing.T) {
-	resolver := resolvers.Resolver{}
+	resolver := NewResolver()
 
 	t.Run("should changes user settings", func(t *testing.T) {
 		ctx, user := NewUser(t)
@@ -174,8 +500,108 @@ func TestUpdateSettings(t *testing.T) {
 	})
 }
 
+func TestDeleteAccount(t *testing.T) {
+	resolver := NewResolver()
+
+	// Gives the user a bit of everything that can be deleted
+	populate := func(t *testing.T, ctx context.Context) *models.Book {
+		book := ApproveBook(t, CreateBook(t, ctx))
+		item := AddItemToUserCollection(t, ctx, book.ID)
+		rating := 5.0
+		_, err := resolver.Mutation().RateItem(ctx, item.ID, &rating)
+		assert.Nil(t, err)
+
+		list := CreateList(t, ctx, true)
+		_, err = resolver.Mutation().AddToList(ctx, list.ID, book.ID)
+		assert.Nil(t, err)
+
+		books := 10
+		_, err = resolver.Mutation().SetReadingGoal(ctx, 2024, &books, nil)
+		assert.Nil(t, err)
+
+		follower, _ := NewUser(t)
+		FollowList(t, follower, list.ID)
+
+		return book
+	}
+
+	t.Run("should delete everything but submitted books", func(t *testing.T) {
+		RequireDatabase(t)
+		ctx, user := NewUser(t)
+		book := populate(t, ctx)
+		profile, err := store.NewUserStore(db).FindProfileByUserUuid(user.UUID)
+		assert.Nil(t, err)
+
+		deleted, err := resolver.Mutation().DeleteAccount(ctx, user.Email)
+		assert.Nil(t, err)
+		assert.True(t, deleted)
+
+		owned := []any{&models.CollectionItem{}, &models.List{}, &models.ListFollow{}, &models.Settings{}, &models.ReadingGoal{}}
+		for _, model := range owned {
+			var count int64
+			err = db.Unscoped().Model(model).Where("profile_id = ?", profile.ID).Count(&count).Error
+			assert.Nil(t, err)
+			assert.Zero(t, count, "%T", model)
+		}
+
+		var count int64
+		err = db.Unscoped().Model(&models.Profile{}).Where("id = ?", profile.ID).Count(&count).Error
+		assert.Nil(t, err)
+		assert.Zero(t, count)
+
+		got, err := store.NewBookStore(db).FindById(book.ID)
+		assert.Nil(t, err)
+		assert.Nil(t, got.ProfileID)
+		assert.Zero(t, got.RatingCount)
+
+		addedBy, err := resolver.Book().AddedBy(ctx, got)
+		assert.Nil(t, err)
+		assert.Nil(t, addedBy)
+	})
+
+	t.Run("should fail if confirmation isn't the email", func(t *testing.T) {
+		ctx, user := NewUser(t)
+		deleted, err := resolver.Mutation().DeleteAccount(ctx, "someone@email.com")
+		assert.False(t, deleted)
+		assert.ErrorAs(t, err, ErrAsPointer(resolvers.ErrBadArgument("confirmation", "")))
+
+		_, err = resolver.CurrentUser().Settings(ctx, user)
+		assert.Nil(t, err)
+	})
+
+	t.Run("should delete accounts of identities deleted in Ory", func(t *testing.T) {
+		ctx, user := NewUser(t)
+		populate(t, ctx)
+		hook := hooks.IdentityDeleted(repos.Users, "secret")
+		body := fmt.Sprintf(`{"identity_id": "%s"}`, user.UUID)
+
+		request := httptest.NewRequest(http.MethodPost, "/hooks/ory/identity-deleted", strings.NewReader(body))
+		request.Header.Set(hooks.SecretHeader, "wrong")
+		recorder := httptest.NewRecorder()
+		hook(recorder, request)
+		assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+
+		request = httptest.NewRequest(http.MethodPost, "/hooks/ory/identity-deleted", strings.NewReader(body))
+		request.Header.Set(hooks.SecretHeader, "secret")
+		recorder = httptest.NewRecorder()
+		hook(recorder, request)
+		assert.Equal(t, http.StatusNoContent, recorder.Code)
+
+		profiles, err := repos.Users.FindProfilesByUserUuids([]uuid.UUID{user.UUID})
+		assert.Nil(t, err)
+		assert.Empty(t, profiles)
+
+		// Deleting again does nothing
+		request = httptest.NewRequest(http.MethodPost, "/hooks/ory/identity-deleted", strings.NewReader(body))
+		request.Header.Set(hooks.SecretHeader, "secret")
+		recorder = httptest.NewRecorder()
+		hook(recorder, request)
+		assert.Equal(t, http.StatusNoContent, recorder.Code)
+	})
+}
+
 func TestMe(t *testing.T) {
-	resolver := resolvers.Resolver{}
+	resolver := NewResolver()
 
 	t.Run("should return current user", func(t *testing.T) {
 		session := NewRandomSession()
@@ -1,15 +1,21 @@
 package resolvers
 
 import (
+	"context"
 	"errors"
 	"fmt"
 
+	"github.com/99designs/gqlgen/graphql"
 	"github.com/google/uuid"
+	"github.com/marcos-brito/booklist/internal/models"
+	"github.com/vektah/gqlparser/v2/gqlerror"
 )
 
 var (
-	ErrInternal     = errors.New("InternalServerError")
-	ErrUnauthorized = errors.New("Unauthorized")
+	ErrInternal      = errors.New("InternalServerError")
+	ErrUnauthorized  = errors.New("Unauthorized")
+	ErrForbidden     = errors.New("Forbidden")
+	ErrFollowOwnList = errors.New("CannotFollowOwnList")
 )
 
 type BadId struct {
@@ -38,6 +44,94 @@ func (b *BadUuid) Error() string {
 	return fmt.Sprintf("No %s was found with ID \"%s\"", b.entity, b.id.String())
 }
 
+type BadIsbn struct {
+	isbn string
+}
+
+func ErrBadIsbn(isbn string) *BadIsbn {
+	return &BadIsbn{isbn}
+}
+
+func (b *BadIsbn) Error() string {
+	return fmt.Sprintf("No book was found with ISBN \"%s\"", b.isbn)
+}
+
+type BadArgument struct {
+	name   string
+	reason string
+}
+
+func ErrBadArgument(name, reason string) *BadArgument {
+	return &BadArgument{name, reason}
+}
+
+func (b *BadArgument) Error() string {
+	return fmt.Sprintf("Invalid value for \"%s\": %s", b.name, b.reason)
+}
+
+type BadTransition struct {
+	from models.Status
+	to   models.Status
+}
+
+func ErrBadTransition(from, to models.Status) *BadTransition {
+	return &BadTransition{from, to}
+}
+
+func (b *BadTransition) Error() string {
+	return fmt.Sprintf("Can't change status from \"%s\" to \"%s\"", b.from, b.to)
+}
+
+type DuplicateIsbn struct {
+	isbn     string
+	existing uint
+}
+
+func ErrDuplicateIsbn(isbn string, existing uint) *DuplicateIsbn {
+	return &DuplicateIsbn{isbn, existing}
+}
+
+func (d *DuplicateIsbn) Error() string {
+	if d.existing == 0 {
+		return fmt.Sprintf("A book with ISBN \"%s\" already exists", d.isbn)
+	}
+
+	return fmt.Sprintf("A book with ISBN \"%s\" already exists with ID \"%d\"", d.isbn, d.existing)
+}
+
+// The ID of the book that already has the ISBN, or 0 if the viewer
+// can't see it.
+func (d *DuplicateIsbn) BookId() uint {
+	return d.existing
+}
+
+func (d *DuplicateIsbn) Extensions() map[string]interface{} {
+	extensions := map[string]interface{}{"code": "DUPLICATE_ISBN"}
+	if d.existing != 0 {
+		extensions["bookId"] = d.existing
+	}
+
+	return extensions
+}
+
+// Errors with extensions have them added to the GraphQL error.
+type extendedError interface {
+	Extensions() map[string]interface{}
+}
+
+// Same as graphql.DefaultErrorPresenter, but also presents the
+// extensions of errors that have them.
+func ErrorPresenter(ctx context.Context, err error) *gqlerror.Error {
+	gqlErr := graphql.DefaultErrorPresenter(ctx, err)
+
+	var extended extendedError
+	if errors.As(err, &extended) {
+		gqlErr.Extensions = extended.Extensions()
+	}
+
+	return gqlErr
+}
+
 // Returns defaultErr when err == target. Else returns ErrInternal.
 func ErrWithOrInternal(err, target, defaultErr error) error {
 	if err == nil {
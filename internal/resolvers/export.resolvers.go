@@ -0,0 +1,33 @@
+package resolvers
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.56
+
+import (
+	"context"
+
+	"github.com/marcos-brito/booklist/internal/auth"
+	"github.com/marcos-brito/booklist/internal/export"
+	"github.com/marcos-brito/booklist/internal/models"
+)
+
+// ExportData is the resolver for the exportData field.
+func (r *mutationResolver) ExportData(ctx context.Context, format models.ExportFormat) (*models.DataExport, error) {
+	_, ident, ok := auth.GetSession(ctx)
+	if !ok {
+		return nil, ErrUnauthorized
+	}
+
+	profile, err := r.Repos.Users.FindProfileByUserUuid(ident.UUID)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	dataExport, err := r.Repos.Exports.Create(profile.ID, format, export.TTL)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	return dataExport, nil
+}
@@ -0,0 +1,131 @@
+package resolvers_test
+
+import (
+	"archive/zip"
+	"bytes"
+	"context"
+	"encoding/json"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/marcos-brito/booklist/internal/export"
+	"github.com/marcos-brito/booklist/internal/importer"
+	"github.com/marcos-brito/booklist/internal/models"
+	"github.com/stretchr/testify/assert"
+)
+
+func DownloadExport(ctx context.Context, dataExport *models.DataExport) *httptest.ResponseRecorder {
+	request := httptest.NewRequestWithContext(ctx, http.MethodGet, dataExport.URL(), nil)
+	request.SetPathValue("token", dataExport.Token)
+	recorder := httptest.NewRecorder()
+	export.Handler(repos.Exports, repos.Users)(recorder, request)
+
+	return recorder
+}
+
+func TestExportData(t *testing.T) {
+	resolver := NewResolver()
+	ctx, _ := NewUser(t)
+	author := CreateAuthor(t, ctx)
+	publisher := CreatePublisher(t, ctx)
+	book, err := resolver.Mutation().CreateBook(ctx, models.CreateBook{
+		Title:     "Grande Sertão: Veredas",
+		Isbn:      RandomIsbn(),
+		Authors:   []uint{author.ID},
+		Publisher: &publisher.ID,
+	})
+	assert.Nil(t, err)
+
+	book = ApproveBook(t, book)
+	item := AddItemToUserCollection(t, ctx, book.ID)
+	startedAt := time.Date(2022, time.May, 1, 0, 0, 0, 0, time.UTC)
+	finishedAt := time.Date(2022, time.May, 20, 0, 0, 0, 0, time.UTC)
+	_, err = resolver.Mutation().ChangeItemStatus(ctx, item.ID, models.StatusRead, &models.ReadingDates{
+		StartedAt:  &startedAt,
+		FinishedAt: &finishedAt,
+	})
+	assert.Nil(t, err)
+
+	rating := 4.0
+	_, err = resolver.Mutation().RateItem(ctx, item.ID, &rating)
+	assert.Nil(t, err)
+
+	list := CreateList(t, ctx, false)
+	_, err = resolver.Mutation().AddToList(ctx, list.ID, book.ID)
+	assert.Nil(t, err)
+
+	t.Run("should download an archive with everything", func(t *testing.T) {
+		dataExport, err := resolver.Mutation().ExportData(ctx, models.ExportFormatZip)
+		assert.Nil(t, err)
+		assert.True(t, strings.HasPrefix(dataExport.URL(), "/export/"))
+		assert.True(t, dataExport.ExpiresAt.After(time.Now()))
+
+		response := DownloadExport(ctx, dataExport)
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.Equal(t, "application/zip", response.Header().Get("Content-Type"))
+
+		body := response.Body.Bytes()
+		reader, err := zip.NewReader(bytes.NewReader(body), int64(len(body)))
+		assert.Nil(t, err)
+
+		files := map[string][]byte{}
+		for _, file := range reader.File {
+			opened, err := file.Open()
+			assert.Nil(t, err)
+			files[file.Name], err = io.ReadAll(opened)
+			assert.Nil(t, err)
+		}
+
+		for _, name := range []string{"booklist.json", "collection.csv", "lists.csv", "settings.csv", "submitted_books.csv", "goodreads_library_export.csv"} {
+			assert.Contains(t, files, name)
+		}
+
+		archive := &export.Archive{}
+		err = json.Unmarshal(files["booklist.json"], archive)
+		assert.Nil(t, err)
+		assert.Len(t, archive.Collection, 1)
+		assert.Equal(t, book.ISBN, archive.Collection[0].Book.ISBN)
+		assert.Equal(t, models.StatusRead, archive.Collection[0].Status)
+		assert.Len(t, archive.Lists, 1)
+		assert.Equal(t, book.ISBN, archive.Lists[0].Books[0].ISBN)
+		assert.Equal(t, []string{author.Name}, archive.Lists[0].Books[0].Authors)
+		assert.Equal(t, publisher.Name, *archive.Lists[0].Books[0].Publisher)
+		assert.Len(t, archive.SubmittedBooks, 1)
+		assert.Equal(t, string(models.ApprovalStateApproved), archive.SubmittedBooks[0].ApprovalState)
+
+		rows, err := importer.ParseGoodreads(bytes.NewReader(files["goodreads_library_export.csv"]))
+		assert.Nil(t, err)
+		assert.Len(t, rows, 1)
+		assert.Contains(t, rows[0].ISBNs, book.ISBN)
+		assert.Equal(t, models.StatusRead, rows[0].Status)
+		assert.Equal(t, finishedAt, *rows[0].FinishedAt)
+		assert.Equal(t, 4.0, *rows[0].Rating)
+		assert.Equal(t, []string{strings.ReplaceAll(strings.ToLower(list.Name), " ", "-")}, rows[0].Lists)
+	})
+
+	t.Run("should download a tarball", func(t *testing.T) {
+		dataExport, err := resolver.Mutation().ExportData(ctx, models.ExportFormatTarGz)
+		assert.Nil(t, err)
+
+		response := DownloadExport(ctx, dataExport)
+		assert.Equal(t, http.StatusOK, response.Code)
+		assert.Equal(t, "application/gzip", response.Header().Get("Content-Type"))
+		assert.Contains(t, response.Header().Get("Content-Disposition"), ".tar.gz")
+	})
+
+	t.Run("should only be downloaded by who asked for it", func(t *testing.T) {
+		dataExport, err := resolver.Mutation().ExportData(ctx, models.ExportFormatZip)
+		assert.Nil(t, err)
+
+		other, _ := NewUser(t)
+		response := DownloadExport(other, dataExport)
+		assert.Equal(t, http.StatusNotFound, response.Code)
+
+		response = DownloadExport(context.Background(), dataExport)
+		assert.Equal(t, http.StatusUnauthorized, response.Code)
+	})
+}
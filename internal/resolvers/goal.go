@@ -0,0 +1,83 @@
+package resolvers
+
+import (
+	"errors"
+	"log"
+	"slices"
+	"time"
+
+	"github.com/marcos-brito/booklist/internal/models"
+	"github.com/marcos-brito/booklist/internal/stream"
+	"gorm.io/gorm"
+)
+
+// Keeps the completion of the goal for the year in sync with what was
+// read. It has to run after anything that changes the books finished in
+// that year.
+func (r *Resolver) syncGoal(profile *models.Profile, year int) error {
+	goalStore := r.Repos.Goals
+	goal, err := goalStore.FindByYear(profile.ID, year)
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	if !goal.Reached() {
+		if goal.CompletedAt != nil {
+			return goalStore.Reopen(goal)
+		}
+
+		return nil
+	}
+
+	completed, err := goalStore.Complete(goal)
+	if err != nil || !completed {
+		return err
+	}
+
+	r.emitGoalCompleted(profile, goal)
+	return nil
+}
+
+// Syncs the goals of the years an item was finished in before and after
+// a change to it.
+func (r *Resolver) syncGoals(profile *models.Profile, before, after *time.Time) error {
+	years := []int{}
+	for _, date := range []*time.Time{before, after} {
+		if date != nil && !slices.Contains(years, date.Year()) {
+			years = append(years, date.Year())
+		}
+	}
+
+	for _, year := range years {
+		err := r.syncGoal(profile, year)
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// The goal is already completed by the time the event is emitted, so
+// failing to emit it is only logged. Without Redis, as in tests, there is
+// nowhere to emit it to.
+func (r *Resolver) emitGoalCompleted(profile *models.Profile, goal *models.ReadingGoal) {
+	if r.Redis == nil {
+		return
+	}
+
+	err := stream.Emit(r.Redis, &stream.GoalCompleted{
+		UserUUID: profile.UUID,
+		Year:     goal.Year,
+		Books:    goal.BooksRead,
+		Pages:    goal.PagesRead,
+	})
+
+	if err != nil {
+		log.Printf("couldn't emit goal completion: %s", err)
+	}
+}
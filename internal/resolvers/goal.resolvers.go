@@ -0,0 +1,48 @@
+package resolvers
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.56
+
+import (
+	"context"
+
+	"github.com/marcos-brito/booklist/internal/auth"
+	"github.com/marcos-brito/booklist/internal/models"
+)
+
+// SetReadingGoal is the resolver for the setReadingGoal field.
+func (r *mutationResolver) SetReadingGoal(ctx context.Context, year int, books *int, pages *int) (*models.ReadingGoal, error) {
+	_, ident, ok := auth.GetSession(ctx)
+	if !ok {
+		return nil, ErrUnauthorized
+	}
+
+	err := checkReadingGoal(year, books, pages)
+	if err != nil {
+		return nil, err
+	}
+
+	profile, err := r.Repos.Users.FindProfileByUserUuid(ident.UUID)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	goalStore := r.Repos.Goals
+	_, err = goalStore.Set(profile.ID, year, books, pages)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	err = r.syncGoal(profile, year)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	goal, err := goalStore.FindByYear(profile.ID, year)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	return goal, nil
+}
@@ -0,0 +1,125 @@
+package resolvers_test
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/marcos-brito/booklist/internal/models"
+	"github.com/marcos-brito/booklist/internal/resolvers"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSetReadingGoal(t *testing.T) {
+	resolver := NewResolver()
+
+	finish := func(t *testing.T, ctx context.Context, finishedAt time.Time) *models.CollectionItem {
+		pageCount := 100
+		book, err := resolver.Mutation().CreateBook(ctx, models.CreateBook{
+			Title:     "O Alienista",
+			Isbn:      RandomIsbn(),
+			PageCount: &pageCount,
+		})
+		assert.Nil(t, err)
+
+		item := AddItemToUserCollection(t, ctx, book.ID)
+		startedAt := finishedAt.AddDate(0, 0, -7)
+		item, err = resolver.Mutation().ChangeItemStatus(ctx, item.ID, models.StatusRead, &models.ReadingDates{
+			StartedAt:  &startedAt,
+			FinishedAt: &finishedAt,
+		})
+		assert.Nil(t, err)
+
+		return item
+	}
+
+	t.Run("should report progress towards the goal", func(t *testing.T) {
+		ctx, user := NewUser(t)
+		books, pages := 3, 500
+		finish(t, ctx, time.Date(2021, time.May, 1, 0, 0, 0, 0, time.UTC))
+		finish(t, ctx, time.Date(2021, time.June, 1, 0, 0, 0, 0, time.UTC))
+		finish(t, ctx, time.Date(2022, time.June, 1, 0, 0, 0, 0, time.UTC))
+
+		goal, err := resolver.Mutation().SetReadingGoal(ctx, 2021, &books, &pages)
+		assert.Nil(t, err)
+		assert.Equal(t, 2, goal.BooksRead)
+		assert.Equal(t, 200, goal.PagesRead)
+		assert.Equal(t, 3, *goal.ExpectedBooks())
+		assert.Equal(t, 500, *goal.ExpectedPages())
+		assert.Equal(t, models.GoalPaceBehind, goal.Pace())
+		assert.Nil(t, goal.CompletedAt)
+
+		got, err := resolver.CurrentUser().ReadingGoal(ctx, user, 2021)
+		assert.Nil(t, err)
+		assert.Equal(t, goal.ID, got.ID)
+
+		got, err = resolver.CurrentUser().ReadingGoal(ctx, user, 2020)
+		assert.Nil(t, err)
+		assert.Nil(t, got)
+	})
+
+	t.Run("should complete and reopen the goal as books are read", func(t *testing.T) {
+		ctx, user := NewUser(t)
+		books := 2
+		_, err := resolver.Mutation().SetReadingGoal(ctx, 2021, &books, nil)
+		assert.Nil(t, err)
+
+		finish(t, ctx, time.Date(2021, time.May, 1, 0, 0, 0, 0, time.UTC))
+		item := finish(t, ctx, time.Date(2021, time.June, 1, 0, 0, 0, 0, time.UTC))
+
+		goal, err := resolver.CurrentUser().ReadingGoal(ctx, user, 2021)
+		assert.Nil(t, err)
+		assert.NotNil(t, goal.CompletedAt)
+		assert.Equal(t, models.GoalPaceOnTrack, goal.Pace())
+
+		_, err = resolver.Mutation().DeleteFromCollection(ctx, item.ID)
+		assert.Nil(t, err)
+
+		goal, err = resolver.CurrentUser().ReadingGoal(ctx, user, 2021)
+		assert.Nil(t, err)
+		assert.Nil(t, goal.CompletedAt)
+	})
+
+	t.Run("should complete the goal when a book is added as read", func(t *testing.T) {
+		ctx, user := NewUser(t)
+		books := 1
+		year := time.Now().Year()
+		_, err := resolver.Mutation().SetReadingGoal(ctx, year, &books, nil)
+		assert.Nil(t, err)
+
+		book := CreateBook(t, ctx)
+		status := models.StatusRead
+		_, err = resolver.Mutation().AddToCollection(ctx, book.ID, &status)
+		assert.Nil(t, err)
+
+		goal, err := resolver.CurrentUser().ReadingGoal(ctx, user, year)
+		assert.Nil(t, err)
+		assert.NotNil(t, goal.CompletedAt)
+	})
+
+	t.Run("should replace the targets of an existing goal", func(t *testing.T) {
+		ctx, _ := NewUser(t)
+		books, pages := 10, 1000
+		first, err := resolver.Mutation().SetReadingGoal(ctx, 2021, &books, nil)
+		assert.Nil(t, err)
+
+		second, err := resolver.Mutation().SetReadingGoal(ctx, 2021, nil, &pages)
+		assert.Nil(t, err)
+		assert.Equal(t, first.ID, second.ID)
+		assert.Nil(t, second.Books)
+		assert.Equal(t, 1000, *second.Pages)
+	})
+
+	t.Run("should fail if goal is invalid", func(t *testing.T) {
+		ctx, _ := NewUser(t)
+		zero := 0
+
+		got, err := resolver.Mutation().SetReadingGoal(ctx, 2021, nil, nil)
+		assert.Nil(t, got)
+		assert.ErrorAs(t, err, ErrAsPointer(resolvers.ErrBadArgument("books", "")))
+
+		got, err = resolver.Mutation().SetReadingGoal(ctx, 2021, &zero, nil)
+		assert.Nil(t, got)
+		assert.ErrorAs(t, err, ErrAsPointer(resolvers.ErrBadArgument("books", "")))
+	})
+}
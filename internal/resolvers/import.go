@@ -0,0 +1,40 @@
+package resolvers
+
+import (
+	"log"
+	"slices"
+	"time"
+
+	"github.com/marcos-brito/booklist/internal/importer"
+	"github.com/marcos-brito/booklist/internal/models"
+)
+
+// Exports of a few thousand books are well under this.
+const maxImportSize = 20 << 20
+
+// Runs the import in the background. Imported books may be finished in
+// any year, so the goals of the years they're imported with are synced
+// once it's done. The job is copied so the resolver's response isn't
+// written to while it's being sent.
+func (r *Resolver) runImport(job models.ImportJob, profile *models.Profile, rows []*importer.Row) {
+	err := r.Importer.Run(&job, profile, rows)
+	if err != nil {
+		return
+	}
+
+	years := []int{}
+	now := time.Now()
+	for _, row := range rows {
+		finishedAt := row.Dates(now).FinishedAt
+		if finishedAt != nil && !slices.Contains(years, finishedAt.Year()) {
+			years = append(years, finishedAt.Year())
+		}
+	}
+
+	for _, year := range years {
+		err = r.syncGoal(profile, year)
+		if err != nil {
+			log.Printf("couldn't sync goal after import %d: %s", job.ID, err)
+		}
+	}
+}
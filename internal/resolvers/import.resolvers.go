@@ -0,0 +1,70 @@
+package resolvers
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.56
+
+import (
+	"context"
+	"fmt"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/marcos-brito/booklist/internal/auth"
+	"github.com/marcos-brito/booklist/internal/importer"
+	"github.com/marcos-brito/booklist/internal/models"
+)
+
+// ImportCollection is the resolver for the importCollection field.
+func (r *mutationResolver) ImportCollection(ctx context.Context, format models.ImportFormat, file graphql.Upload) (*models.ImportJob, error) {
+	_, ident, ok := auth.GetSession(ctx)
+	if !ok {
+		return nil, ErrUnauthorized
+	}
+
+	if r.Importer == nil {
+		return nil, ErrInternal
+	}
+
+	if file.Size > maxImportSize {
+		return nil, ErrBadArgument("file", fmt.Sprintf("must be at most %d bytes", maxImportSize))
+	}
+
+	rows, err := importer.Parse(format, file.File)
+	if err != nil {
+		return nil, ErrBadArgument("file", err.Error())
+	}
+
+	profile, err := r.Repos.Users.FindProfileByUserUuid(ident.UUID)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	job, err := r.Repos.Imports.Create(profile.ID, format, len(rows))
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	go r.runImport(*job, profile, rows)
+
+	return job, nil
+}
+
+// ImportJob is the resolver for the importJob field.
+func (r *queryResolver) ImportJob(ctx context.Context, id uint) (*models.ImportJob, error) {
+	_, ident, ok := auth.GetSession(ctx)
+	if !ok {
+		return nil, ErrUnauthorized
+	}
+
+	profile, err := r.Repos.Users.FindProfileByUserUuid(ident.UUID)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	job, err := r.Repos.Imports.FindById(id)
+	if err != nil || job.ProfileID != profile.ID {
+		return nil, ErrBadId(id, "importJob")
+	}
+
+	return job, nil
+}
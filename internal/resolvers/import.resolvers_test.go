@@ -0,0 +1,175 @@
+package resolvers_test
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql"
+	"github.com/marcos-brito/booklist/internal/models"
+	"github.com/marcos-brito/booklist/internal/resolvers"
+	"github.com/stretchr/testify/assert"
+)
+
+func WaitImport(t *testing.T, ctx context.Context, id uint) *models.ImportJob {
+	resolver := NewResolver()
+	var job *models.ImportJob
+	assert.Eventually(t, func() bool {
+		var err error
+		job, err = resolver.Query().ImportJob(ctx, id)
+		assert.Nil(t, err)
+
+		return job.Status == models.ImportStatusDone || job.Status == models.ImportStatusFailed
+	}, 5*time.Second, 50*time.Millisecond)
+
+	return job
+}
+
+func TestImportCollection(t *testing.T) {
+	RequireDatabase(t)
+	resolver := NewResolver()
+	header := "Title,Author,ISBN,ISBN13,My Rating,Date Read,Exclusive Shelf\n"
+
+	upload := func(export string) graphql.Upload {
+		return graphql.Upload{File: strings.NewReader(export), Size: int64(len(export))}
+	}
+
+	t.Run("should import books into the collection", func(t *testing.T) {
+		ctx, user := NewUser(t)
+		existing := ApproveBook(t, CreateBook(t, ctx))
+		isbn := RandomIsbn()
+		export := header +
+			fmt.Sprintf(`%s,,,="%s",5,2021/03/14,read`, existing.Title, existing.ISBN) + "\n" +
+			fmt.Sprintf(`Memórias Póstumas,Machado de Assis,,="%s",0,,classics`, isbn) + "\n" +
+			`Dom Casmurro,Machado de Assis,,,0,,to-read` + "\n"
+
+		job, err := resolver.Mutation().ImportCollection(ctx, models.ImportFormatGoodreads, upload(export))
+		assert.Nil(t, err)
+		assert.Equal(t, 3, job.Total)
+
+		job = WaitImport(t, ctx, job.ID)
+		assert.Equal(t, models.ImportStatusDone, job.Status)
+		assert.Equal(t, 3, job.Processed)
+		assert.Equal(t, 2, job.Imported)
+		assert.NotNil(t, job.FinishedAt)
+		assert.Len(t, job.Errors, 1)
+		assert.Equal(t, 4, job.Errors[0].Line)
+		assert.Equal(t, "Dom Casmurro", job.Errors[0].Title)
+
+		items, err := resolver.CurrentUser().Collection(ctx, user, nil, nil)
+		assert.Nil(t, err)
+		assert.Len(t, items, 2)
+		for _, item := range items {
+			if item.BookID == existing.ID {
+				assert.Equal(t, models.StatusRead, item.Status)
+				assert.Equal(t, 5.0, *item.Rating)
+				assert.Equal(t, 2021, item.FinishedAt.Year())
+			} else {
+				assert.Equal(t, models.StatusToRead, item.Status)
+			}
+		}
+
+		lists, err := resolver.CurrentUser().Lists(ctx, user)
+		assert.Nil(t, err)
+		assert.Len(t, lists, 1)
+		assert.Equal(t, "classics", lists[0].Name)
+	})
+
+	t.Run("should not date books read on an unknown date", func(t *testing.T) {
+		ctx, user := NewUser(t)
+		book := ApproveBook(t, CreateBook(t, ctx))
+		books := 1
+		year := time.Now().Year()
+		_, err := resolver.Mutation().SetReadingGoal(ctx, year, &books, nil)
+		assert.Nil(t, err)
+
+		export := header + fmt.Sprintf(`%s,,,="%s",0,,read`, book.Title, book.ISBN) + "\n"
+		job, err := resolver.Mutation().ImportCollection(ctx, models.ImportFormatGoodreads, upload(export))
+		assert.Nil(t, err)
+
+		job = WaitImport(t, ctx, job.ID)
+		assert.Equal(t, 1, job.Imported)
+
+		items, err := resolver.CurrentUser().Collection(ctx, user, nil, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, models.StatusRead, items[0].Status)
+		assert.Nil(t, items[0].FinishedAt)
+
+		goal, err := resolver.CurrentUser().ReadingGoal(ctx, user, year)
+		assert.Nil(t, err)
+		assert.Equal(t, 0, goal.BooksRead)
+		assert.Nil(t, goal.CompletedAt)
+	})
+
+	t.Run("should import other formats", func(t *testing.T) {
+		ctx, user := NewUser(t)
+		book := ApproveBook(t, CreateBook(t, ctx))
+		export := "Title,Authors,ISBN/UID,Read Status,Star Rating,Tags\n" +
+			fmt.Sprintf(`%s,,%s,did-not-finish,2.25,abandoned`, book.Title, book.ISBN) + "\n"
+
+		job, err := resolver.Mutation().ImportCollection(ctx, models.ImportFormatStorygraph, upload(export))
+		assert.Nil(t, err)
+
+		job = WaitImport(t, ctx, job.ID)
+		assert.Equal(t, models.ImportFormatStorygraph, job.Format)
+		assert.Equal(t, 1, job.Imported)
+
+		items, err := resolver.CurrentUser().Collection(ctx, user, nil, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, models.StatusDropped, items[0].Status)
+		assert.Equal(t, 2.5, *items[0].Rating)
+	})
+
+	t.Run("should report books already in the collection", func(t *testing.T) {
+		ctx, _ := NewUser(t)
+		book := ApproveBook(t, CreateBook(t, ctx))
+		AddItemToUserCollection(t, ctx, book.ID)
+		export := header + fmt.Sprintf(`%s,,,="%s",0,,to-read`, book.Title, book.ISBN) + "\n"
+
+		job, err := resolver.Mutation().ImportCollection(ctx, models.ImportFormatGoodreads, upload(export))
+		assert.Nil(t, err)
+
+		job = WaitImport(t, ctx, job.ID)
+		assert.Equal(t, 0, job.Imported)
+		assert.Len(t, job.Errors, 1)
+		assert.Contains(t, job.Errors[0].Message, "already in the collection")
+	})
+
+	t.Run("should fail if the file isn't an export", func(t *testing.T) {
+		ctx, _ := NewUser(t)
+		job, err := resolver.Mutation().ImportCollection(ctx, models.ImportFormatGoodreads, upload("name,email\n"))
+
+		assert.Nil(t, job)
+		assert.ErrorAs(t, err, ErrAsPointer(resolvers.ErrBadArgument("file", "")))
+	})
+
+	t.Run("should hide jobs of other users", func(t *testing.T) {
+		ctx, _ := NewUser(t)
+		job, err := resolver.Mutation().ImportCollection(ctx, models.ImportFormatGoodreads, upload(header))
+		assert.Nil(t, err)
+		WaitImport(t, ctx, job.ID)
+
+		other, _ := NewUser(t)
+		got, err := resolver.Query().ImportJob(other, job.ID)
+		assert.Nil(t, got)
+		assert.ErrorAs(t, err, ErrAsPointer(resolvers.ErrBadId(job.ID, "importJob")))
+	})
+}
+
+func TestImportCollectionWithoutImporter(t *testing.T) {
+	resolver := &resolvers.Resolver{Repos: repos}
+	ctx, _ := NewUser(t)
+	export := "Title,ISBN,ISBN13,Exclusive Shelf\nEmma,,,to-read\n"
+
+	t.Run("should fail without starting a job", func(t *testing.T) {
+		job, err := resolver.Mutation().ImportCollection(ctx, models.ImportFormatGoodreads, graphql.Upload{
+			File: strings.NewReader(export),
+			Size: int64(len(export)),
+		})
+
+		assert.Nil(t, job)
+		assert.ErrorIs(t, err, resolvers.ErrInternal)
+	})
+}
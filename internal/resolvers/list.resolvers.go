@@ -6,41 +6,92 @@ package resolvers
 
 import (
 	"context"
-	"fmt"
 
 	"github.com/marcos-brito/booklist/internal/auth"
-	"github.com/marcos-brito/booklist/internal/conn"
 	"github.com/marcos-brito/booklist/internal/models"
-	"github.com/marcos-brito/booklist/internal/store"
 	"gorm.io/gorm"
 )
 
 // Books is the resolver for the books field.
 func (r *listResolver) Books(ctx context.Context, obj *models.List) ([]*models.Book, error) {
-	books, err := store.NewListStore(conn.DB).FindBooks(obj.ID)
+	viewer, err := r.viewerProfile(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	books, err := r.loadersFor(ctx).ListBooks.Load(ctx, obj.ID)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	visible := []*models.Book{}
+	for _, book := range books {
+		if book.VisibleTo(viewer) {
+			visible = append(visible, book)
+		}
+	}
+
+	return visible, nil
+}
+
+// BooksConnection is the resolver for the booksConnection field.
+func (r *listResolver) BooksConnection(ctx context.Context, obj *models.List, first *int, after *string, last *int, before *string) (*models.BookConnection, error) {
+	page, err := checkConnection(first, after, last, before)
+	if err != nil {
+		return nil, err
+	}
+
+	viewer, err := r.viewerProfile(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	listStore := r.Repos.Lists
+	books, more, err := listStore.FindBooksPage(obj.ID, viewer, page)
 	if err != nil {
 		return nil, ErrInternal
 	}
 
-	return books, nil
+	total, err := listStore.CountBooks(obj.ID, viewer)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	return newBookConnection(books, page, more, total), nil
 }
 
 // Owner is the resolver for the owner field.
 func (r *listResolver) Owner(ctx context.Context, obj *models.List) (*models.User, error) {
-	profile, err := store.NewUserStore(conn.DB).FindFullProfileById(obj.ProfileID)
+	return r.loadPublicUser(ctx, obj.ProfileID)
+}
+
+// Followers is the resolver for the followers field.
+func (r *listResolver) Followers(ctx context.Context, obj *models.List) ([]*models.User, error) {
+	profiles, err := r.Repos.Lists.FindFollowers(obj.ID)
 	if err != nil {
 		return nil, ErrInternal
 	}
 
-	if profile.Settings.Private {
-		return nil, nil
+	users := []*models.User{}
+	for _, profile := range profiles {
+		if profile.Settings.Private {
+			continue
+		}
+
+		users = append(users, &models.User{UUID: profile.UUID})
 	}
 
-	user := &models.User{
-		UUID: profile.UUID,
+	return users, nil
+}
+
+// FollowerCount is the resolver for the followerCount field.
+func (r *listResolver) FollowerCount(ctx context.Context, obj *models.List) (int, error) {
+	count, err := r.Repos.Lists.CountFollowers(obj.ID)
+	if err != nil {
+		return 0, ErrInternal
 	}
 
-	return user, nil
+	return int(count), nil
 }
 
 // CreateList is the resolver for the createList field.
@@ -55,7 +106,7 @@ func (r *mutationResolver) CreateList(ctx context.Context, name string, descript
 		*publish = false
 	}
 
-	list, err := store.NewListStore(conn.DB).Create(name, description, *publish, ident.UUID)
+	list, err := r.Repos.Lists.Create(name, description, *publish, ident.UUID)
 	if err != nil {
 		return nil, ErrInternal
 	}
@@ -70,12 +121,12 @@ func (r *mutationResolver) DeleteList(ctx context.Context, id uint) (*models.Lis
 		return nil, ErrUnauthorized
 	}
 
-	ok, err := listIsOwned(id, ident.UUID)
+	ok, err := r.listIsOwned(id, ident.UUID)
 	if !ok {
 		return nil, err
 	}
 
-	list, err := store.NewListStore(conn.DB).Delete(id)
+	list, err := r.Repos.Lists.Delete(id)
 	if err != nil {
 		return nil, ErrInternal
 	}
@@ -90,12 +141,12 @@ func (r *mutationResolver) PublishList(ctx context.Context, id uint) (*models.Li
 		return nil, ErrUnauthorized
 	}
 
-	ok, err := listIsOwned(id, ident.UUID)
+	ok, err := r.listIsOwned(id, ident.UUID)
 	if !ok {
 		return nil, err
 	}
 
-	list, err := store.NewListStore(conn.DB).Publish(id)
+	list, err := r.Repos.Lists.Publish(id)
 	if err != nil {
 		return nil, ErrInternal
 	}
@@ -110,12 +161,12 @@ func (r *mutationResolver) UnpublishList(ctx context.Context, id uint) (*models.
 		return nil, ErrUnauthorized
 	}
 
-	ok, err := listIsOwned(id, ident.UUID)
+	ok, err := r.listIsOwned(id, ident.UUID)
 	if !ok {
 		return nil, err
 	}
 
-	list, err := store.NewListStore(conn.DB).Unpublish(id)
+	list, err := r.Repos.Lists.Unpublish(id)
 	if err != nil {
 		return nil, ErrInternal
 	}
@@ -130,13 +181,13 @@ func (r *mutationResolver) CloneList(ctx context.Context, id uint) (*models.List
 		return nil, ErrUnauthorized
 	}
 
-	listStore := store.NewListStore(conn.DB)
+	listStore := r.Repos.Lists
 	list, err := listStore.FindById(id)
 	if err != nil {
 		return nil, ErrWithOrInternal(gorm.ErrRecordNotFound, err, ErrBadId(id, "list"))
 	}
 
-	ok, _ = listIsOwned(id, ident.UUID)
+	ok, _ = r.listIsOwned(id, ident.UUID)
 	if !list.Published && !ok {
 		return nil, ErrBadId(id, "list")
 	}
@@ -151,12 +202,62 @@ func (r *mutationResolver) CloneList(ctx context.Context, id uint) (*models.List
 
 // FollowList is the resolver for the followList field.
 func (r *mutationResolver) FollowList(ctx context.Context, id uint) (*models.List, error) {
-	panic(fmt.Errorf("not implemented: FollowList - followList"))
+	_, ident, ok := auth.GetSession(ctx)
+	if !ok {
+		return nil, ErrUnauthorized
+	}
+
+	listStore := r.Repos.Lists
+	list, err := listStore.FindById(id)
+	if err != nil {
+		return nil, ErrWithOrInternal(err, gorm.ErrRecordNotFound, ErrBadId(id, "list"))
+	}
+
+	ok, _ = r.listIsOwned(id, ident.UUID)
+	if ok {
+		return nil, ErrFollowOwnList
+	}
+
+	if !list.Published {
+		return nil, ErrBadId(id, "list")
+	}
+
+	list, err = listStore.Follow(id, ident.UUID)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	return list, nil
 }
 
 // UnfollowList is the resolver for the unfollowList field.
 func (r *mutationResolver) UnfollowList(ctx context.Context, id uint) (*models.List, error) {
-	panic(fmt.Errorf("not implemented: UnfollowList - unfollowList"))
+	_, ident, ok := auth.GetSession(ctx)
+	if !ok {
+		return nil, ErrUnauthorized
+	}
+
+	listStore := r.Repos.Lists
+	_, err := listStore.FindById(id)
+	if err != nil {
+		return nil, ErrWithOrInternal(err, gorm.ErrRecordNotFound, ErrBadId(id, "list"))
+	}
+
+	following, err := listStore.IsFollower(id, ident.UUID)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	if !following {
+		return nil, ErrBadId(id, "list")
+	}
+
+	list, err := listStore.Unfollow(id, ident.UUID)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	return list, nil
 }
 
 // AddToList is the resolver for the addToList field.
@@ -166,17 +267,17 @@ func (r *mutationResolver) AddToList(ctx context.Context, listID uint, bookID ui
 		return nil, ErrUnauthorized
 	}
 
-	ok, err := listIsOwned(listID, ident.UUID)
+	ok, err := r.listIsOwned(listID, ident.UUID)
 	if !ok {
 		return nil, err
 	}
 
-	_, err = store.NewBookStore(conn.DB).FindById(bookID)
+	_, err = r.findVisibleBook(ctx, bookID)
 	if err != nil {
-		return nil, ErrWithOrInternal(gorm.ErrRecordNotFound, err, ErrBadId(bookID, "book"))
+		return nil, err
 	}
 
-	list, err := store.NewListStore(conn.DB).AddBook(listID, bookID)
+	list, err := r.Repos.Lists.AddBook(listID, bookID)
 	if err != nil {
 		return nil, ErrInternal
 	}
@@ -191,17 +292,17 @@ func (r *mutationResolver) RemoveFromList(ctx context.Context, listID uint, book
 		return nil, ErrUnauthorized
 	}
 
-	ok, err := listIsOwned(listID, ident.UUID)
+	ok, err := r.listIsOwned(listID, ident.UUID)
 	if !ok {
 		return nil, err
 	}
 
-	_, err = store.NewBookStore(conn.DB).FindById(bookID)
+	_, err = r.Repos.Books.FindById(bookID)
 	if err != nil {
 		return nil, ErrWithOrInternal(gorm.ErrRecordNotFound, err, ErrBadId(bookID, "book"))
 	}
 
-	list, err := store.NewListStore(conn.DB).RemoveBook(listID, bookID)
+	list, err := r.Repos.Lists.RemoveBook(listID, bookID)
 	if err != nil {
 		return nil, ErrInternal
 	}
@@ -13,7 +13,7 @@ import (
 )
 
 func CreateList(t *testing.T, ctx context.Context, publish bool) *models.List {
-	resolver := resolvers.Resolver{}
+	resolver := NewResolver()
 	list, err := resolver.Mutation().CreateList(ctx, fmt.Sprintf("List %d", rand.Int()), nil, &publish)
 
 	assert.Nil(t, err)
@@ -21,7 +21,7 @@ func CreateList(t *testing.T, ctx context.Context, publish bool) *models.List {
 }
 
 func UpdateSettings(t *testing.T, ctx context.Context, settings *models.UpdateSettings) {
-	resolver := resolvers.Resolver{}
+	resolver := NewResolver()
 	_, err := resolver.Mutation().UpdateSettings(ctx, *settings)
 
 	assert.Nil(t, err)
@@ -30,8 +30,42 @@ func UpdateSettings(t *testing.T, ctx context.Context, settings *models.UpdateSe
 func TestBooks(t *testing.T) {
 }
 
+func TestBooksConnection(t *testing.T) {
+	resolver := NewResolver()
+	ctx, _ := NewUser(t)
+	list := CreateList(t, ctx, true)
+	approved := ApproveBook(t, CreateBook(t, ctx))
+	pending := CreateBook(t, ctx)
+	for _, book := range []*models.Book{approved, pending} {
+		_, err := resolver.Mutation().AddToList(ctx, list.ID, book.ID)
+		assert.Nil(t, err)
+	}
+
+	t.Run("should page the books in the list", func(t *testing.T) {
+		one := 1
+		got, err := resolver.List().BooksConnection(ctx, list, &one, nil, nil, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, 2, got.TotalCount)
+		assert.Equal(t, approved.ID, got.Edges[0].Node.ID)
+		assert.True(t, got.PageInfo.HasNextPage)
+
+		got, err = resolver.List().BooksConnection(ctx, list, &one, got.PageInfo.EndCursor, nil, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, pending.ID, got.Edges[0].Node.ID)
+		assert.False(t, got.PageInfo.HasNextPage)
+	})
+
+	t.Run("should leave out books others can't see", func(t *testing.T) {
+		other, _ := NewUser(t)
+		got, err := resolver.List().BooksConnection(other, list, nil, nil, nil, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, got.TotalCount)
+		assert.Equal(t, approved.ID, got.Edges[0].Node.ID)
+	})
+}
+
 func TestOwner(t *testing.T) {
-	resolver := resolvers.Resolver{}
+	resolver := NewResolver()
 	ctx, owner := NewUser(t)
 	list := CreateList(t, ctx, true)
 
@@ -54,7 +88,7 @@ func TestOwner(t *testing.T) {
 }
 
 func TestCreateList(t *testing.T) {
-	resolver := resolvers.Resolver{}
+	resolver := NewResolver()
 
 	t.Run("should create private list by default", func(t *testing.T) {
 		ctx, user := NewUser(t)
@@ -92,7 +126,7 @@ func TestCreateList(t *testing.T) {
 }
 
 func TestDeleteList(t *testing.T) {
-	resolver := resolvers.Resolver{}
+	resolver := NewResolver()
 
 	t.Run("should delete a list", func(t *testing.T) {
 		ctx, user := NewUser(t)
@@ -128,7 +162,7 @@ func TestDeleteList(t *testing.T) {
 }
 
 func TestPublishList(t *testing.T) {
-	resolver := resolvers.Resolver{}
+	resolver := NewResolver()
 
 	t.Run("should publish a list", func(t *testing.T) {
 		ctx, _ := NewUser(t)
@@ -161,7 +195,7 @@ func TestPublishList(t *testing.T) {
 }
 
 func TestUnpublishList(t *testing.T) {
-	resolver := resolvers.Resolver{}
+	resolver := NewResolver()
 
 	t.Run("should unpublish a list", func(t *testing.T) {
 		ctx, _ := NewUser(t)
@@ -194,7 +228,7 @@ func TestUnpublishList(t *testing.T) {
 }
 
 func TestCloneList(t *testing.T) {
-	resolver := resolvers.Resolver{}
+	resolver := NewResolver()
 	ctx, user := NewUser(t)
 	published := CreateList(t, ctx, true)
 	private := CreateList(t, ctx, false)
@@ -237,14 +271,140 @@ func TestCloneList(t *testing.T) {
 	})
 }
 
-func TestFollowList(t *testing.T) {}
+func TestFollowers(t *testing.T) {
+	resolver := NewResolver()
+	ctx, _ := NewUser(t)
+	list := CreateList(t, ctx, true)
+
+	t.Run("should return and count only public followers", func(t *testing.T) {
+		ctx1, user1 := NewUser(t)
+		ctx2, user2 := NewUser(t)
+		UpdateSettings(t, ctx1, &models.UpdateSettings{Private: false})
+		UpdateSettings(t, ctx2, &models.UpdateSettings{Private: true})
+		FollowList(t, ctx1, list.ID)
+		FollowList(t, ctx2, list.ID)
+
+		followers, err := resolver.List().Followers(ctx, list)
+		assert.Nil(t, err)
+		assert.Contains(t, followers, &models.User{UUID: user1.UUID})
+		assert.NotContains(t, followers, &models.User{UUID: user2.UUID})
+
+		count, err := resolver.List().FollowerCount(ctx, list)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, count)
+	})
+}
+
+func FollowList(t *testing.T, ctx context.Context, id uint) *models.List {
+	resolver := NewResolver()
+	list, err := resolver.Mutation().FollowList(ctx, id)
 
-func TestUnfollowList(t *testing.T) {}
+	assert.Nil(t, err)
+	return list
+}
+
+func TestFollowList(t *testing.T) {
+	resolver := NewResolver()
+	ctx, _ := NewUser(t)
+	published := CreateList(t, ctx, true)
+	private := CreateList(t, ctx, false)
+
+	t.Run("should follow a published list", func(t *testing.T) {
+		ctx, user := NewUser(t)
+
+		got, err := resolver.Mutation().FollowList(ctx, published.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, published.ID, got.ID)
+
+		lists, err := resolver.CurrentUser().FollowedLists(ctx, user)
+		assert.Nil(t, err)
+		assert.Equal(t, published.ID, lists[0].ID)
+	})
+
+	t.Run("should not duplicate an existing follow", func(t *testing.T) {
+		ctx, user := NewUser(t)
+		FollowList(t, ctx, published.ID)
+		FollowList(t, ctx, published.ID)
+
+		lists, err := resolver.CurrentUser().FollowedLists(ctx, user)
+		assert.Nil(t, err)
+		assert.Len(t, lists, 1)
+	})
+
+	t.Run("should fail if list is not published", func(t *testing.T) {
+		ctx, _ := NewUser(t)
+		got, err := resolver.Mutation().FollowList(ctx, private.ID)
+
+		assert.Nil(t, got)
+		assert.ErrorAs(t, err, ErrAsPointer(resolvers.ErrBadId(private.ID, "list")))
+	})
+
+	t.Run("should fail if user owns the list", func(t *testing.T) {
+		got, err := resolver.Mutation().FollowList(ctx, published.ID)
+
+		assert.Nil(t, got)
+		assert.ErrorIs(t, err, resolvers.ErrFollowOwnList)
+	})
+
+	t.Run("should fail if list id does not exist", func(t *testing.T) {
+		ctx, _ := NewUser(t)
+		id := rand.Uint()
+		got, err := resolver.Mutation().FollowList(ctx, id)
+
+		assert.Nil(t, got)
+		assert.ErrorAs(t, err, ErrAsPointer(resolvers.ErrBadId(id, "list")))
+	})
+
+	t.Run("should fail if there is no session", func(t *testing.T) {
+		ctx, _ := NewUser(t)
+		ctx = auth.AddSessionToContext(ctx, nil)
+		got, err := resolver.Mutation().FollowList(ctx, published.ID)
+
+		assert.Nil(t, got)
+		assert.ErrorIs(t, err, resolvers.ErrUnauthorized)
+	})
+}
+
+func TestUnfollowList(t *testing.T) {
+	resolver := NewResolver()
+	ctx, _ := NewUser(t)
+	list := CreateList(t, ctx, true)
+
+	t.Run("should unfollow a list", func(t *testing.T) {
+		ctx, user := NewUser(t)
+		FollowList(t, ctx, list.ID)
+
+		got, err := resolver.Mutation().UnfollowList(ctx, list.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, list.ID, got.ID)
+
+		lists, err := resolver.CurrentUser().FollowedLists(ctx, user)
+		assert.Nil(t, err)
+		assert.Empty(t, lists)
+	})
+
+	t.Run("should fail if user does not follow the list", func(t *testing.T) {
+		ctx, _ := NewUser(t)
+		got, err := resolver.Mutation().UnfollowList(ctx, list.ID)
+
+		assert.Nil(t, got)
+		assert.ErrorAs(t, err, ErrAsPointer(resolvers.ErrBadId(list.ID, "list")))
+	})
+
+	t.Run("should fail if there is no session", func(t *testing.T) {
+		ctx, _ := NewUser(t)
+		ctx = auth.AddSessionToContext(ctx, nil)
+		got, err := resolver.Mutation().UnfollowList(ctx, list.ID)
+
+		assert.Nil(t, got)
+		assert.ErrorIs(t, err, resolvers.ErrUnauthorized)
+	})
+}
 
 func TestAddToList(t *testing.T) {
-	resolver := resolvers.Resolver{}
+	resolver := NewResolver()
 	ctx, _ := NewUser(t)
-	book := CreateBook(t, ctx)
+	book := ApproveBook(t, CreateBook(t, ctx))
 
 	t.Run("should add a book to a list", func(t *testing.T) {
 		ctx, _ := NewUser(t)
@@ -290,9 +450,9 @@ func TestAddToList(t *testing.T) {
 }
 
 func TestDeleteFromList(t *testing.T) {
-	resolver := resolvers.Resolver{}
+	resolver := NewResolver()
 	ctx, _ := NewUser(t)
-	book := CreateBook(t, ctx)
+	book := ApproveBook(t, CreateBook(t, ctx))
 
 	t.Run("should delete a book from a list", func(t *testing.T) {
 		ctx, _ := NewUser(t)
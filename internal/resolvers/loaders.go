@@ -0,0 +1,56 @@
+package resolvers
+
+import (
+	"context"
+
+	"github.com/google/uuid"
+	"github.com/marcos-brito/booklist/internal/loaders"
+	"github.com/marcos-brito/booklist/internal/models"
+)
+
+// Returns the loaders of the request. Resolvers called outside of one get
+// loaders of their own, which fetch right away.
+func (r *Resolver) loadersFor(ctx context.Context) *loaders.Loaders {
+	l, ok := loaders.GetLoaders(ctx)
+	if !ok {
+		return loaders.New(r.Repos, 0)
+	}
+
+	return l
+}
+
+// Returns the settings of the user with the given UUID.
+func (r *Resolver) loadSettings(ctx context.Context, userUuid uuid.UUID) (*models.Settings, error) {
+	l := r.loadersFor(ctx)
+	profile, err := l.ProfilesByUuid.Load(ctx, userUuid)
+	if err != nil || profile == nil {
+		return nil, ErrInternal
+	}
+
+	settings, err := l.Settings.Load(ctx, profile.ID)
+	if err != nil || settings == nil {
+		return nil, ErrInternal
+	}
+
+	return settings, nil
+}
+
+// Returns the user with the given profile, or nil if they are private.
+func (r *Resolver) loadPublicUser(ctx context.Context, profileId uint) (*models.User, error) {
+	l := r.loadersFor(ctx)
+	profile, err := l.Profiles.Load(ctx, profileId)
+	if err != nil || profile == nil {
+		return nil, ErrInternal
+	}
+
+	settings, err := l.Settings.Load(ctx, profileId)
+	if err != nil || settings == nil {
+		return nil, ErrInternal
+	}
+
+	if settings.Private {
+		return nil, nil
+	}
+
+	return &models.User{UUID: profile.UUID}, nil
+}
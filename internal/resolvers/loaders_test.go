@@ -0,0 +1,114 @@
+package resolvers_test
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/99designs/gqlgen/graphql/handler"
+	"github.com/99designs/gqlgen/graphql/handler/transport"
+	"github.com/marcos-brito/booklist/internal/loaders"
+	"github.com/marcos-brito/booklist/internal/models"
+	"github.com/marcos-brito/booklist/internal/resolvers"
+	"github.com/marcos-brito/booklist/internal/store"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+	"gorm.io/gorm/logger"
+)
+
+// Counts the statements run through it, logging nothing.
+type statementCounter struct {
+	logger.Interface
+	count atomic.Int64
+}
+
+func (c *statementCounter) Trace(ctx context.Context, begin time.Time, fc func() (string, int64), err error) {
+	c.count.Add(1)
+}
+
+const nestedQuery = `{
+	me {
+		collection {
+			book {
+				title
+				authors { name }
+				publisher { name }
+				addedBy { uuid }
+			}
+		}
+		lists {
+			books { title authors { name } }
+			owner { uuid }
+		}
+	}
+}`
+
+// Runs the nested query for a user with the given number of books in
+// their collection and in a list, and returns how many statements it took.
+func CountStatements(t *testing.T, books int) int64 {
+	resolver := NewResolver()
+	ctx, _ := NewUser(t)
+	list := CreateList(t, ctx, true)
+	for range books {
+		author := ApproveAuthor(t, CreateAuthor(t, ctx))
+		publisher := CreatePublisher(t, ctx)
+		book, err := resolver.Mutation().CreateBook(ctx, models.CreateBook{
+			Title:     "Book",
+			Isbn:      RandomIsbn(),
+			Authors:   []uint{author.ID},
+			Publisher: &publisher.ID,
+		})
+		assert.Nil(t, err)
+
+		AddItemToUserCollection(t, ctx, ApproveBook(t, book).ID)
+		_, err = resolver.Mutation().AddToList(ctx, list.ID, book.ID)
+		assert.Nil(t, err)
+	}
+
+	counter := &statementCounter{Interface: logger.Discard}
+	repos := store.NewRepositories(db.Session(&gorm.Session{Logger: counter}))
+	server := handler.New(resolvers.NewExecutableSchema(resolvers.Config{Resolvers: &resolvers.Resolver{Repos: repos}}))
+	server.AddTransport(transport.POST{})
+
+	// Generous wait, so a slow machine doesn't split the batches
+	ctx = loaders.AddLoadersToContext(ctx, loaders.New(repos, 50*time.Millisecond))
+	body, err := json.Marshal(map[string]string{"query": nestedQuery})
+	assert.Nil(t, err)
+
+	request := httptest.NewRequestWithContext(ctx, http.MethodPost, "/graphql", strings.NewReader(string(body)))
+	request.Header.Set("Content-Type", "application/json")
+	recorder := httptest.NewRecorder()
+	server.ServeHTTP(recorder, request)
+
+	response := struct {
+		Data struct {
+			Me struct {
+				Collection []any
+			}
+		}
+		Errors []any
+	}{}
+	err = json.Unmarshal(recorder.Body.Bytes(), &response)
+	assert.Nil(t, err)
+	assert.Empty(t, response.Errors)
+	assert.Len(t, response.Data.Me.Collection, books)
+
+	return counter.count.Load()
+}
+
+func TestLoaders(t *testing.T) {
+	RequireDatabase(t)
+	t.Run("should take as many statements for many books as for one", func(t *testing.T) {
+		// The profile for me, the profile and items for the collection, the
+		// profile and lists for the lists, then one for each loader: the
+		// viewer, books, authors, publishers, profiles, settings and list
+		// books.
+		assert.Equal(t, int64(12), CountStatements(t, 1))
+		assert.Equal(t, int64(12), CountStatements(t, 10))
+	})
+}
@@ -0,0 +1,185 @@
+package resolvers
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.56
+
+import (
+	"context"
+	"strings"
+
+	"github.com/marcos-brito/booklist/internal/auth"
+	"github.com/marcos-brito/booklist/internal/models"
+	"gorm.io/gorm"
+)
+
+// ApproveBook is the resolver for the approveBook field.
+func (r *mutationResolver) ApproveBook(ctx context.Context, id uint) (*models.Book, error) {
+	_, ident, ok := auth.GetSession(ctx)
+	if !ok {
+		return nil, ErrUnauthorized
+	}
+
+	ok, err := r.isModerator(ident.UUID)
+	if !ok {
+		return nil, err
+	}
+
+	book, err := r.Repos.Books.Approve(id)
+	if err != nil {
+		return nil, ErrWithOrInternal(err, gorm.ErrRecordNotFound, ErrBadId(id, "book"))
+	}
+
+	return book, nil
+}
+
+// RejectBook is the resolver for the rejectBook field.
+func (r *mutationResolver) RejectBook(ctx context.Context, id uint, reason string) (*models.Book, error) {
+	_, ident, ok := auth.GetSession(ctx)
+	if !ok {
+		return nil, ErrUnauthorized
+	}
+
+	ok, err := r.isModerator(ident.UUID)
+	if !ok {
+		return nil, err
+	}
+
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, ErrBadArgument("reason", "must not be empty")
+	}
+
+	book, err := r.Repos.Books.Reject(id, reason)
+	if err != nil {
+		return nil, ErrWithOrInternal(err, gorm.ErrRecordNotFound, ErrBadId(id, "book"))
+	}
+
+	return book, nil
+}
+
+// ApproveAuthor is the resolver for the approveAuthor field.
+func (r *mutationResolver) ApproveAuthor(ctx context.Context, id uint) (*models.Author, error) {
+	_, ident, ok := auth.GetSession(ctx)
+	if !ok {
+		return nil, ErrUnauthorized
+	}
+
+	ok, err := r.isModerator(ident.UUID)
+	if !ok {
+		return nil, err
+	}
+
+	author, err := r.Repos.Authors.Approve(id)
+	if err != nil {
+		return nil, ErrWithOrInternal(err, gorm.ErrRecordNotFound, ErrBadId(id, "author"))
+	}
+
+	return author, nil
+}
+
+// RejectAuthor is the resolver for the rejectAuthor field.
+func (r *mutationResolver) RejectAuthor(ctx context.Context, id uint, reason string) (*models.Author, error) {
+	_, ident, ok := auth.GetSession(ctx)
+	if !ok {
+		return nil, ErrUnauthorized
+	}
+
+	ok, err := r.isModerator(ident.UUID)
+	if !ok {
+		return nil, err
+	}
+
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, ErrBadArgument("reason", "must not be empty")
+	}
+
+	author, err := r.Repos.Authors.Reject(id, reason)
+	if err != nil {
+		return nil, ErrWithOrInternal(err, gorm.ErrRecordNotFound, ErrBadId(id, "author"))
+	}
+
+	return author, nil
+}
+
+// ApprovePublisher is the resolver for the approvePublisher field.
+func (r *mutationResolver) ApprovePublisher(ctx context.Context, id uint) (*models.Publisher, error) {
+	_, ident, ok := auth.GetSession(ctx)
+	if !ok {
+		return nil, ErrUnauthorized
+	}
+
+	ok, err := r.isModerator(ident.UUID)
+	if !ok {
+		return nil, err
+	}
+
+	publisher, err := r.Repos.Publishers.Approve(id)
+	if err != nil {
+		return nil, ErrWithOrInternal(err, gorm.ErrRecordNotFound, ErrBadId(id, "publisher"))
+	}
+
+	return publisher, nil
+}
+
+// RejectPublisher is the resolver for the rejectPublisher field.
+func (r *mutationResolver) RejectPublisher(ctx context.Context, id uint, reason string) (*models.Publisher, error) {
+	_, ident, ok := auth.GetSession(ctx)
+	if !ok {
+		return nil, ErrUnauthorized
+	}
+
+	ok, err := r.isModerator(ident.UUID)
+	if !ok {
+		return nil, err
+	}
+
+	reason = strings.TrimSpace(reason)
+	if reason == "" {
+		return nil, ErrBadArgument("reason", "must not be empty")
+	}
+
+	publisher, err := r.Repos.Publishers.Reject(id, reason)
+	if err != nil {
+		return nil, ErrWithOrInternal(err, gorm.ErrRecordNotFound, ErrBadId(id, "publisher"))
+	}
+
+	return publisher, nil
+}
+
+// ModerationQueue is the resolver for the moderationQueue field.
+func (r *queryResolver) ModerationQueue(ctx context.Context, limit *int, offset *int) (*models.BookPage, error) {
+	_, ident, ok := auth.GetSession(ctx)
+	if !ok {
+		return nil, ErrUnauthorized
+	}
+
+	ok, err := r.isModerator(ident.UUID)
+	if !ok {
+		return nil, err
+	}
+
+	limitValue, offsetValue, err := checkPage(limit, offset)
+	if err != nil {
+		return nil, err
+	}
+
+	bookStore := r.Repos.Books
+	books, err := bookStore.FindPending(limitValue, offsetValue)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	count, err := bookStore.CountPending()
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	page := &models.BookPage{
+		Books:      books,
+		TotalCount: int(count),
+	}
+
+	return page, nil
+}
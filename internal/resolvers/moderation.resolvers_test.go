@@ -0,0 +1,143 @@
+package resolvers_test
+
+import (
+	"context"
+	"testing"
+
+	"github.com/marcos-brito/booklist/internal/auth"
+	"github.com/marcos-brito/booklist/internal/models"
+	"github.com/marcos-brito/booklist/internal/resolvers"
+	"github.com/stretchr/testify/assert"
+)
+
+func NewModerator(t *testing.T) (context.Context, *models.CurrentUser) {
+	ctx, user := NewUser(t)
+	_, err := repos.Users.SetModerator(user.UUID, true)
+	if err != nil {
+		t.Fatalf("unexpected error: %s", err)
+	}
+
+	user.Moderator = true
+	return ctx, user
+}
+
+// Approves the book so every user can see it.
+func ApproveBook(t *testing.T, book *models.Book) *models.Book {
+	resolver := NewResolver()
+	ctx, _ := NewModerator(t)
+	book, err := resolver.Mutation().ApproveBook(ctx, book.ID)
+
+	assert.Nil(t, err)
+	return book
+}
+
+func TestModerationQueue(t *testing.T) {
+	resolver := NewResolver()
+	ctx, _ := NewUser(t)
+	pending := CreateBook(t, ctx)
+	approved := ApproveBook(t, CreateBook(t, ctx))
+
+	t.Run("should list pending books", func(t *testing.T) {
+		ctx, _ := NewModerator(t)
+		limit := 100
+		got, err := resolver.Query().ModerationQueue(ctx, &limit, nil)
+		assert.Nil(t, err)
+
+		ids := []uint{}
+		for _, book := range got.Books {
+			ids = append(ids, book.ID)
+		}
+
+		assert.Contains(t, ids, pending.ID)
+		assert.NotContains(t, ids, approved.ID)
+	})
+
+	t.Run("should fail if user is not a moderator", func(t *testing.T) {
+		got, err := resolver.Query().ModerationQueue(ctx, nil, nil)
+
+		assert.Nil(t, got)
+		assert.ErrorIs(t, err, resolvers.ErrForbidden)
+	})
+
+	t.Run("should fail if there is no session", func(t *testing.T) {
+		ctx := auth.AddSessionToContext(context.Background(), nil)
+		got, err := resolver.Query().ModerationQueue(ctx, nil, nil)
+
+		assert.Nil(t, got)
+		assert.ErrorIs(t, err, resolvers.ErrUnauthorized)
+	})
+}
+
+func TestApproveBook(t *testing.T) {
+	resolver := NewResolver()
+
+	t.Run("should make the book visible to everyone", func(t *testing.T) {
+		ctx, _ := NewUser(t)
+		book := CreateBook(t, ctx)
+		other, _ := NewUser(t)
+
+		_, err := resolver.Query().Book(other, book.ID)
+		assert.ErrorAs(t, err, ErrAsPointer(resolvers.ErrBadId(book.ID, "book")))
+
+		approved := ApproveBook(t, book)
+		assert.False(t, approved.NeedsApproval)
+
+		got, err := resolver.Query().Book(other, book.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, book.ID, got.ID)
+
+		state, err := resolver.Book().ApprovalState(other, got)
+		assert.Nil(t, err)
+		assert.Equal(t, models.ApprovalStateApproved, state)
+	})
+
+	t.Run("should fail if user is not a moderator", func(t *testing.T) {
+		ctx, _ := NewUser(t)
+		book := CreateBook(t, ctx)
+		got, err := resolver.Mutation().ApproveBook(ctx, book.ID)
+
+		assert.Nil(t, got)
+		assert.ErrorIs(t, err, resolvers.ErrForbidden)
+	})
+}
+
+func TestRejectBook(t *testing.T) {
+	resolver := NewResolver()
+	ctx, _ := NewUser(t)
+	moderator, _ := NewModerator(t)
+
+	t.Run("should show the reason to the submitter only", func(t *testing.T) {
+		book := CreateBook(t, ctx)
+		rejected, err := resolver.Mutation().RejectBook(moderator, book.ID, "duplicate entry")
+		assert.Nil(t, err)
+
+		state, err := resolver.Book().ApprovalState(ctx, rejected)
+		assert.Nil(t, err)
+		assert.Equal(t, models.ApprovalStateRejected, state)
+
+		reason, err := resolver.Book().RejectionReason(ctx, rejected)
+		assert.Nil(t, err)
+		assert.Equal(t, "duplicate entry", *reason)
+
+		other, _ := NewUser(t)
+		reason, err = resolver.Book().RejectionReason(other, rejected)
+		assert.Nil(t, err)
+		assert.Nil(t, reason)
+	})
+
+	t.Run("should fail if reason is empty", func(t *testing.T) {
+		book := CreateBook(t, ctx)
+		got, err := resolver.Mutation().RejectBook(moderator, book.ID, " ")
+
+		assert.Nil(t, got)
+		assert.ErrorAs(t, err, ErrAsPointer(resolvers.ErrBadArgument("reason", "")))
+	})
+
+	t.Run("should fail if user is not a moderator", func(t *testing.T) {
+		book := CreateBook(t, ctx)
+		got, err := resolver.Mutation().RejectBook(ctx, book.ID, "duplicate entry")
+
+		assert.Nil(t, got)
+		assert.ErrorIs(t, err, resolvers.ErrForbidden)
+	})
+}
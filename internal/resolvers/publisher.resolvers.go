@@ -0,0 +1,119 @@
+package resolvers
+
+// This file will be automatically regenerated based on the schema, any resolver implementations
+// will be copied through when generating and any unknown code will be moved to the end.
+// Code generated by github.com/99designs/gqlgen version v0.17.56
+
+import (
+	"context"
+	"strings"
+
+	"github.com/marcos-brito/booklist/internal/auth"
+	"github.com/marcos-brito/booklist/internal/models"
+	"gorm.io/gorm"
+)
+
+// CreatePublisher is the resolver for the createPublisher field.
+func (r *mutationResolver) CreatePublisher(ctx context.Context, input models.CreatePublisher) (*models.Publisher, error) {
+	_, ident, ok := auth.GetSession(ctx)
+	if !ok {
+		return nil, ErrUnauthorized
+	}
+
+	input.Name = strings.TrimSpace(input.Name)
+	if input.Name == "" {
+		return nil, ErrBadArgument("name", "must not be empty")
+	}
+
+	publisher, err := r.Repos.Publishers.Create(&input, ident.UUID)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	return publisher, nil
+}
+
+// UpdatePublisher is the resolver for the updatePublisher field.
+func (r *mutationResolver) UpdatePublisher(ctx context.Context, id uint, changes models.UpdatePublisher) (*models.Publisher, error) {
+	_, ident, ok := auth.GetSession(ctx)
+	if !ok {
+		return nil, ErrUnauthorized
+	}
+
+	publisher, err := r.findVisiblePublisher(ctx, id)
+	if err != nil {
+		return nil, err
+	}
+
+	profile, err := r.Repos.Users.FindProfileByUserUuid(ident.UUID)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	if !canEdit(profile, publisher.NeedsApproval, publisher.ProfileID) {
+		return nil, ErrForbidden
+	}
+
+	if changes.Name != nil && strings.TrimSpace(*changes.Name) == "" {
+		return nil, ErrBadArgument("name", "must not be empty")
+	}
+
+	publisher, err = r.Repos.Publishers.Update(id, &changes)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	return publisher, nil
+}
+
+// MergePublishers is the resolver for the mergePublishers field.
+func (r *mutationResolver) MergePublishers(ctx context.Context, source uint, target uint) (*models.Publisher, error) {
+	_, ident, ok := auth.GetSession(ctx)
+	if !ok {
+		return nil, ErrUnauthorized
+	}
+
+	ok, err := r.isModerator(ident.UUID)
+	if !ok {
+		return nil, err
+	}
+
+	if source == target {
+		return nil, ErrBadArgument("source", "must be different from target")
+	}
+
+	publisherStore := r.Repos.Publishers
+	for _, id := range []uint{source, target} {
+		_, err = publisherStore.FindById(id)
+		if err != nil {
+			return nil, ErrWithOrInternal(err, gorm.ErrRecordNotFound, ErrBadId(id, "publisher"))
+		}
+	}
+
+	publisher, err := publisherStore.Merge(source, target)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	return publisher, nil
+}
+
+// ApprovalState is the resolver for the approvalState field.
+func (r *publisherResolver) ApprovalState(ctx context.Context, obj *models.Publisher) (models.ApprovalState, error) {
+	return approvalState(obj.NeedsApproval, obj.RejectionReason), nil
+}
+
+// RejectionReason is the resolver for the rejectionReason field.
+func (r *publisherResolver) RejectionReason(ctx context.Context, obj *models.Publisher) (*string, error) {
+	return r.visibleRejectionReason(ctx, obj.ProfileID, obj.RejectionReason)
+}
+
+// Publisher is the resolver for the publisher field.
+func (r *queryResolver) Publisher(ctx context.Context, id uint) (*models.Publisher, error) {
+	return r.findVisiblePublisher(ctx, id)
+}
+
+// Publisher returns PublisherResolver implementation.
+func (r *Resolver) Publisher() PublisherResolver { return &publisherResolver{r} }
+
+type publisherResolver struct{ *Resolver }
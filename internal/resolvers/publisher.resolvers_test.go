@@ -0,0 +1,89 @@
+package resolvers_test
+
+import (
+	"context"
+	"fmt"
+	"math/rand/v2"
+	"testing"
+
+	"github.com/marcos-brito/booklist/internal/models"
+	"github.com/marcos-brito/booklist/internal/resolvers"
+	"github.com/stretchr/testify/assert"
+)
+
+func CreatePublisher(t *testing.T, ctx context.Context) *models.Publisher {
+	resolver := NewResolver()
+	publisher, err := resolver.Mutation().CreatePublisher(ctx, models.CreatePublisher{
+		Name: fmt.Sprintf("Publisher %d", rand.Int()),
+	})
+
+	assert.Nil(t, err)
+	return publisher
+}
+
+func TestCreatePublisher(t *testing.T) {
+	resolver := NewResolver()
+
+	t.Run("should create publisher waiting for approval", func(t *testing.T) {
+		ctx, _ := NewUser(t)
+		publisher := CreatePublisher(t, ctx)
+		assert.True(t, publisher.NeedsApproval)
+
+		other, _ := NewUser(t)
+		got, err := resolver.Query().Publisher(other, publisher.ID)
+		assert.Nil(t, got)
+		assert.ErrorAs(t, err, ErrAsPointer(resolvers.ErrBadId(publisher.ID, "publisher")))
+	})
+}
+
+func TestApprovePublisher(t *testing.T) {
+	resolver := NewResolver()
+	ctx, _ := NewUser(t)
+	moderator, _ := NewModerator(t)
+
+	t.Run("should make publisher visible to everyone", func(t *testing.T) {
+		publisher := CreatePublisher(t, ctx)
+		_, err := resolver.Mutation().ApprovePublisher(moderator, publisher.ID)
+		assert.Nil(t, err)
+
+		other, _ := NewUser(t)
+		got, err := resolver.Query().Publisher(other, publisher.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, publisher.ID, got.ID)
+	})
+}
+
+func TestMergePublishers(t *testing.T) {
+	resolver := NewResolver()
+	ctx, _ := NewUser(t)
+	moderator, _ := NewModerator(t)
+
+	t.Run("should move books to the target publisher", func(t *testing.T) {
+		source := CreatePublisher(t, ctx)
+		target := CreatePublisher(t, ctx)
+		book, err := resolver.Mutation().CreateBook(ctx, models.CreateBook{
+			Title:     "Capitães da areia",
+			Isbn:      RandomIsbn(),
+			Publisher: &source.ID,
+		})
+		assert.Nil(t, err)
+
+		_, err = resolver.Mutation().MergePublishers(moderator, source.ID, target.ID)
+		assert.Nil(t, err)
+
+		book, err = resolver.Query().Book(ctx, book.ID)
+		assert.Nil(t, err)
+
+		got, err := resolver.Book().Publisher(ctx, book)
+		assert.Nil(t, err)
+		assert.Equal(t, target.ID, got.ID)
+	})
+
+	t.Run("should fail if source and target are the same", func(t *testing.T) {
+		publisher := CreatePublisher(t, ctx)
+		got, err := resolver.Mutation().MergePublishers(moderator, publisher.ID, publisher.ID)
+
+		assert.Nil(t, got)
+		assert.ErrorAs(t, err, ErrAsPointer(resolvers.ErrBadArgument("source", "")))
+	})
+}
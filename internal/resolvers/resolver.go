@@ -1,5 +1,27 @@
 package resolvers
 
+import (
+	"github.com/marcos-brito/booklist/internal/auth"
+	"github.com/marcos-brito/booklist/internal/importer"
+	"github.com/marcos-brito/booklist/internal/store"
+	"github.com/redis/go-redis/v9"
+)
+
 //go:generate go run github.com/99designs/gqlgen generate
 
-type Resolver struct{}
+const (
+	defaultPageSize = 20
+	maxPageSize     = 100
+)
+
+// Everything the resolvers depend on, built once when the server starts.
+// Redis and Identities can be left nil, as in tests. Events are then not
+// emitted, and accounts are deleted without their identities and names
+// are never shown. Importer can be left nil too, with imports then
+// failing before a job is created.
+type Resolver struct {
+	Repos      *store.Repositories
+	Importer   importer.Runner
+	Redis      *redis.Client
+	Identities auth.IdentityProvider
+}
@@ -8,16 +8,13 @@ import (
 	"context"
 
 	"github.com/google/uuid"
-	"github.com/marcos-brito/booklist/internal/auth"
-	"github.com/marcos-brito/booklist/internal/conn"
 	"github.com/marcos-brito/booklist/internal/models"
-	"github.com/marcos-brito/booklist/internal/store"
 	"gorm.io/gorm"
 )
 
 // User is the resolver for the user field.
 func (r *queryResolver) User(ctx context.Context, uuid uuid.UUID) (*models.User, error) {
-	userStore := store.NewUserStore(conn.DB)
+	userStore := r.Repos.Users
 	settings, err := userStore.FindSettingsByUserUuid(uuid)
 	if err != nil {
 		return nil, ErrWithOrInternal(gorm.ErrRecordNotFound, err, ErrBadUuid(uuid, "user"))
@@ -36,16 +33,16 @@ func (r *queryResolver) User(ctx context.Context, uuid uuid.UUID) (*models.User,
 
 // Name is the resolver for the name field.
 func (r *userResolver) Name(ctx context.Context, obj *models.User) (*string, error) {
-	settings, err := store.NewUserStore(conn.DB).FindSettingsByUserUuid(obj.UUID)
+	settings, err := r.loadSettings(ctx, obj.UUID)
 	if err != nil {
-		return nil, ErrInternal
+		return nil, err
 	}
 
-	if !settings.ShowName {
+	if !settings.ShowName || r.Identities == nil {
 		return nil, nil
 	}
 
-	ident, ok := auth.FindIdentity(obj.UUID, conn.Ory)
+	ident, ok := r.Identities.FindIdentity(obj.UUID)
 	if !ok {
 		return nil, ErrInternal
 	}
@@ -55,7 +52,7 @@ func (r *userResolver) Name(ctx context.Context, obj *models.User) (*string, err
 
 // Lists is the resolver for the lists field.
 func (r *userResolver) Lists(ctx context.Context, obj *models.User) ([]*models.List, error) {
-	lists, err := store.NewUserStore(conn.DB).FindPublicLists(obj.UUID)
+	lists, err := r.Repos.Users.FindPublicLists(obj.UUID)
 	if err != nil {
 		return nil, ErrInternal
 	}
@@ -63,19 +60,50 @@ func (r *userResolver) Lists(ctx context.Context, obj *models.User) ([]*models.L
 	return lists, nil
 }
 
-// Collection is the resolver for the collection field.
-func (r *userResolver) Collection(ctx context.Context, obj *models.User) ([]*models.CollectionItem, error) {
-	userStore := store.NewUserStore(conn.DB)
-	settings, err := userStore.FindSettingsByUserUuid(obj.UUID)
+// ListsConnection is the resolver for the listsConnection field.
+func (r *userResolver) ListsConnection(ctx context.Context, obj *models.User, first *int, after *string, last *int, before *string) (*models.ListConnection, error) {
+	page, err := checkConnection(first, after, last, before)
+	if err != nil {
+		return nil, err
+	}
+
+	userStore := r.Repos.Users
+	lists, more, err := userStore.FindListsPage(obj.UUID, false, page)
 	if err != nil {
 		return nil, ErrInternal
 	}
 
+	total, err := userStore.CountLists(obj.UUID, false)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	return newListConnection(lists, page, more, total), nil
+}
+
+// Collection is the resolver for the collection field.
+func (r *userResolver) Collection(ctx context.Context, obj *models.User, filter *models.CollectionFilter, sort *models.CollectionSort) ([]*models.CollectionItem, error) {
+	err := checkCollectionFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	userStore := r.Repos.Users
+	settings, err := r.loadSettings(ctx, obj.UUID)
+	if err != nil {
+		return nil, err
+	}
+
 	if !settings.ShowCollection {
 		return nil, nil
 	}
 
-	collection, err := userStore.FindItems(obj.UUID)
+	viewer, err := r.viewerProfile(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	collection, err := userStore.FindPublicItems(obj.UUID, viewer, filter, sort)
 	if err != nil {
 		return nil, ErrInternal
 	}
@@ -83,6 +111,96 @@ func (r *userResolver) Collection(ctx context.Context, obj *models.User) ([]*mod
 	return collection, nil
 }
 
+// CollectionConnection is the resolver for the collectionConnection field.
+func (r *userResolver) CollectionConnection(ctx context.Context, obj *models.User, filter *models.CollectionFilter, sort *models.CollectionSort, first *int, after *string, last *int, before *string) (*models.CollectionItemConnection, error) {
+	page, err := checkConnection(first, after, last, before)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkCollectionFilter(filter)
+	if err != nil {
+		return nil, err
+	}
+
+	userStore := r.Repos.Users
+	settings, err := r.loadSettings(ctx, obj.UUID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !settings.ShowCollection {
+		return nil, nil
+	}
+
+	viewer, err := r.viewerProfile(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	items, more, err := userStore.FindPublicItemsPage(obj.UUID, viewer, filter, sort, page)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	total, err := userStore.CountPublicItems(obj.UUID, viewer, filter)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	return newItemConnection(items, page, more, total), nil
+}
+
+// FollowedLists is the resolver for the followedLists field.
+func (r *userResolver) FollowedLists(ctx context.Context, obj *models.User) ([]*models.List, error) {
+	userStore := r.Repos.Users
+	settings, err := r.loadSettings(ctx, obj.UUID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !settings.ShowListsFollows {
+		return nil, nil
+	}
+
+	lists, err := userStore.FindFollowedLists(obj.UUID)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	return lists, nil
+}
+
+// Stats is the resolver for the stats field.
+func (r *userResolver) Stats(ctx context.Context, obj *models.User, rangeArg *models.StatsRange) (*models.Stats, error) {
+	userStore := r.Repos.Users
+	settings, err := r.loadSettings(ctx, obj.UUID)
+	if err != nil {
+		return nil, err
+	}
+
+	if !settings.ShowStats {
+		return nil, nil
+	}
+
+	viewer, err := r.viewerProfile(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	err = checkStatsRange(rangeArg)
+	if err != nil {
+		return nil, err
+	}
+
+	stats, err := userStore.FindStats(obj.UUID, rangeArg, viewer)
+	if err != nil {
+		return nil, ErrInternal
+	}
+
+	return stats, nil
+}
+
 // User returns UserResolver implementation.
 func (r *Resolver) User() UserResolver { return &userResolver{r} }
 
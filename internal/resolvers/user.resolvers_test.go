@@ -5,12 +5,13 @@ import (
 	"testing"
 
 	"github.com/google/uuid"
+	"github.com/marcos-brito/booklist/internal/models"
 	"github.com/marcos-brito/booklist/internal/resolvers"
 	"github.com/stretchr/testify/assert"
 )
 
 func TestUser(t *testing.T) {
-	resolver := resolvers.Resolver{}
+	resolver := NewResolver()
 	_, user := NewUser(t)
 
 	t.Run("shold return the user", func(t *testing.T) {
@@ -34,3 +35,75 @@ func TestUser(t *testing.T) {
 		assert.ErrorAs(t, err, ErrAsPointer(resolvers.ErrBadUuid(uuid, "user")))
 	})
 }
+
+func TestUserListsConnection(t *testing.T) {
+	resolver := NewResolver()
+
+	t.Run("should only return published lists", func(t *testing.T) {
+		ctx, user := NewUser(t)
+		published := CreateList(t, ctx, true)
+		CreateList(t, ctx, false)
+
+		other, _ := NewUser(t)
+		got, err := resolver.User().ListsConnection(other, &models.User{UUID: user.UUID}, nil, nil, nil, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, got.TotalCount)
+		assert.Equal(t, published.ID, got.Edges[0].Node.ID)
+
+		all, err := resolver.CurrentUser().ListsConnection(ctx, user, nil, nil, nil, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, 2, all.TotalCount)
+	})
+}
+
+func TestUserCollectionConnection(t *testing.T) {
+	resolver := NewResolver()
+	ctx, user := NewUser(t)
+	AddItemToUserCollection(t, ctx, ApproveBook(t, CreateBook(t, ctx)).ID)
+	AddItemToUserCollection(t, ctx, CreateBook(t, ctx).ID)
+
+	t.Run("should return visible items if the collection is shown", func(t *testing.T) {
+		UpdateSettings(t, ctx, &models.UpdateSettings{ShowCollection: true})
+		other, _ := NewUser(t)
+
+		got, err := resolver.User().CollectionConnection(other, &models.User{UUID: user.UUID}, nil, nil, nil, nil, nil, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, 1, got.TotalCount)
+		assert.Len(t, got.Edges, 1)
+	})
+
+	t.Run("should return nil if the collection is hidden", func(t *testing.T) {
+		UpdateSettings(t, ctx, &models.UpdateSettings{ShowCollection: false})
+		other, _ := NewUser(t)
+
+		got, err := resolver.User().CollectionConnection(other, &models.User{UUID: user.UUID}, nil, nil, nil, nil, nil, nil)
+		assert.Nil(t, err)
+		assert.Nil(t, got)
+	})
+}
+
+func TestUserFollowedLists(t *testing.T) {
+	resolver := NewResolver()
+	ctx, _ := NewUser(t)
+	list := CreateList(t, ctx, true)
+
+	t.Run("should return followed lists if they are shown", func(t *testing.T) {
+		ctx, user := NewUser(t)
+		UpdateSettings(t, ctx, &models.UpdateSettings{ShowListsFollows: true})
+		FollowList(t, ctx, list.ID)
+
+		got, err := resolver.User().FollowedLists(ctx, &models.User{UUID: user.UUID})
+		assert.Nil(t, err)
+		assert.Equal(t, list.ID, got[0].ID)
+	})
+
+	t.Run("should return nil if followed lists are hidden", func(t *testing.T) {
+		ctx, user := NewUser(t)
+		UpdateSettings(t, ctx, &models.UpdateSettings{ShowListsFollows: false})
+		FollowList(t, ctx, list.ID)
+
+		got, err := resolver.User().FollowedLists(ctx, &models.User{UUID: user.UUID})
+		assert.Nil(t, err)
+		assert.Nil(t, got)
+	})
+}
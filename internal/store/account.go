@@ -0,0 +1,85 @@
+package store
+
+import (
+	"errors"
+
+	"github.com/google/uuid"
+	"github.com/marcos-brito/booklist/internal/models"
+	"gorm.io/gorm"
+)
+
+// Deletes the profile of the user and everything it owns for good, soft
+// deleted rows included. Books, authors and publishers the user submitted
+// stay for everyone else, but without the profile. Deleting a profile
+// that doesn't exist does nothing, so it's safe to do more than once.
+func (us *UserStore) DeleteAccount(userUuid uuid.UUID) error {
+	profile := &models.Profile{}
+	err := us.DB.Unscoped().Where(&models.Profile{UUID: userUuid}).First(profile).Error
+	if errors.Is(err, gorm.ErrRecordNotFound) {
+		return nil
+	}
+
+	if err != nil {
+		return err
+	}
+
+	return us.DB.Transaction(func(tx *gorm.DB) error {
+		// Items that were already deleted had their ratings removed then
+		rated := []*models.CollectionItem{}
+		err := tx.Where("profile_id = ? AND rating IS NOT NULL", profile.ID).Find(&rated).Error
+		if err != nil {
+			return err
+		}
+
+		for _, item := range rated {
+			err = updateBookRating(tx, item.BookID, item.Rating, nil)
+			if err != nil {
+				return err
+			}
+		}
+
+		tx = tx.Unscoped().Session(&gorm.Session{})
+		items := tx.Model(&models.CollectionItem{}).Select("id").Where("profile_id = ?", profile.ID)
+		lists := tx.Model(&models.List{}).Select("id").Where("profile_id = ?", profile.ID)
+		jobs := tx.Model(&models.ImportJob{}).Select("id").Where("profile_id = ?", profile.ID)
+
+		err = tx.Exec("DELETE FROM list_books WHERE list_id IN (?)", lists).Error
+		if err != nil {
+			return err
+		}
+
+		// In order, since the first ones find their rows through the others
+		owned := []struct {
+			model any
+			query string
+			args  []any
+		}{
+			{&models.ReadingEvent{}, "collection_item_id IN (?)", []any{items}},
+			{&models.ReadingSession{}, "collection_item_id IN (?)", []any{items}},
+			{&models.CollectionItem{}, "profile_id = ?", []any{profile.ID}},
+			{&models.ListFollow{}, "list_id IN (?) OR profile_id = ?", []any{lists, profile.ID}},
+			{&models.List{}, "profile_id = ?", []any{profile.ID}},
+			{&models.ReadingGoal{}, "profile_id = ?", []any{profile.ID}},
+			{&models.ImportError{}, "import_job_id IN (?)", []any{jobs}},
+			{&models.ImportJob{}, "profile_id = ?", []any{profile.ID}},
+			{&models.DataExport{}, "profile_id = ?", []any{profile.ID}},
+			{&models.Settings{}, "profile_id = ?", []any{profile.ID}},
+		}
+
+		for _, rows := range owned {
+			err = tx.Where(rows.query, rows.args...).Delete(rows.model).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		for _, submission := range []any{&models.Book{}, &models.Author{}, &models.Publisher{}} {
+			err = tx.Model(submission).Where("profile_id = ?", profile.ID).UpdateColumn("profile_id", nil).Error
+			if err != nil {
+				return err
+			}
+		}
+
+		return tx.Delete(profile).Error
+	})
+}
@@ -1,6 +1,9 @@
 package store
 
 import (
+	"strings"
+
+	"github.com/google/uuid"
 	"github.com/marcos-brito/booklist/internal/models"
 	"gorm.io/gorm"
 )
@@ -41,3 +44,151 @@ func (as *AuthorStore) FindManyById(ids ...uint) ([]*models.Author, error, *uint
 
 	return authors, nil, nil
 }
+
+// Returns a page of the authors visible to the viewer whose name
+// contains search, ordered by name. An empty search matches every author.
+func (as *AuthorStore) Search(search string, needsApproval *bool, viewer *models.Profile, limit, offset int) ([]*models.Author, error) {
+	query := as.DB.Model(&models.Author{}).Scopes(VisibleTo(viewer))
+
+	if search != "" {
+		query = query.Where("LOWER(name) LIKE ?", "%"+strings.ToLower(search)+"%")
+	}
+
+	if needsApproval != nil {
+		query = query.Where("needs_approval = ?", *needsApproval)
+	}
+
+	authors := []*models.Author{}
+	err := query.Order("name").Order("id").Limit(limit).Offset(offset).Find(&authors).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return authors, nil
+}
+
+// Returns the author visible to the viewer whose name matches the given
+// one, ignoring case. Approved authors come first.
+func (as *AuthorStore) FindByName(name string, viewer *models.Profile) (*models.Author, error) {
+	author := &models.Author{}
+	err := as.DB.Scopes(VisibleTo(viewer)).
+		Where("LOWER(name) = LOWER(?)", name).
+		Order("needs_approval").Order("id").
+		First(author).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return author, nil
+}
+
+// Returns the author's books that are visible to the viewer.
+func (as *AuthorStore) FindBooks(id uint, viewer *models.Profile) ([]*models.Book, error) {
+	author := &models.Author{}
+	author.ID = id
+	books := []*models.Book{}
+	err := as.DB.Model(author).Scopes(VisibleTo(viewer)).Association("Books").Find(&books)
+
+	if err != nil {
+		return nil, err
+	}
+
+	return books, nil
+}
+
+func (as *AuthorStore) Create(input *models.CreateAuthor, userUuid uuid.UUID) (*models.Author, error) {
+	profile, err := NewUserStore(as.DB).FindProfileByUserUuid(userUuid)
+	if err != nil {
+		return nil, err
+	}
+
+	author := models.NewAuthorFromInput(input, profile.ID)
+	err = as.DB.Create(author).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return as.FindById(author.ID)
+}
+
+func (as *AuthorStore) Update(id uint, changes *models.UpdateAuthor) (*models.Author, error) {
+	author, err := as.FindById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if changes.Name != nil {
+		author.Name = *changes.Name
+	}
+
+	if changes.BirthDay != nil {
+		author.BirthDay = changes.BirthDay
+	}
+
+	err = as.DB.Save(author).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return author, nil
+}
+
+// Moves every book of the source author to the target and deletes the
+// source. Books credited to both keep a single entry.
+func (as *AuthorStore) Merge(sourceId, targetId uint) (*models.Author, error) {
+	err := as.DB.Transaction(func(tx *gorm.DB) error {
+		credited := tx.Table("book_authors").Select("book_id").Where("author_id = ?", targetId)
+		err := tx.Exec(`INSERT INTO book_authors (book_id, author_id)
+			SELECT book_id, ? FROM book_authors WHERE author_id = ? AND book_id NOT IN (?)`,
+			targetId, sourceId, credited).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Exec("DELETE FROM book_authors WHERE author_id = ?", sourceId).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Delete(&models.Author{}, sourceId).Error
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return as.FindById(targetId)
+}
+
+func (as *AuthorStore) Approve(id uint) (*models.Author, error) {
+	author, err := as.FindById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	author.NeedsApproval = false
+	author.RejectionReason = nil
+	err = as.DB.Save(author).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return author, nil
+}
+
+func (as *AuthorStore) Reject(id uint, reason string) (*models.Author, error) {
+	author, err := as.FindById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	author.NeedsApproval = true
+	author.RejectionReason = &reason
+	err = as.DB.Save(author).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return author, nil
+}
@@ -1,6 +1,11 @@
 package store
 
 import (
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
 	"github.com/google/uuid"
 	"github.com/marcos-brito/booklist/internal/models"
 	"gorm.io/gorm"
@@ -25,15 +30,307 @@ func (bs *BookStore) FindById(id uint) (*models.Book, error) {
 	return book, nil
 }
 
-func (bs *BookStore) Create(input *models.CreateBook, userUuid uuid.UUID) (*models.Book, error) {
-	authors := []*models.Author{}
-	err := bs.DB.Find(&authors, input.Authors).Error
+func (bs *BookStore) FindByIds(ids []uint) ([]*models.Book, error) {
+	books := []*models.Book{}
+	err := bs.DB.Where("id IN ?", ids).Find(&books).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return books, nil
+}
+
+type bookAuthor struct {
+	models.Author
+	BookID uint
+}
+
+// Returns the authors of each of the books, keyed by book, whether they
+// are visible to anyone or not.
+func (bs *BookStore) FindAuthorsByBooks(ids []uint) (map[uint][]*models.Author, error) {
+	rows := []*bookAuthor{}
+	err := bs.DB.Model(&models.Author{}).Select("authors.*, book_authors.book_id").
+		Joins("JOIN book_authors ON book_authors.author_id = authors.id").
+		Where("book_authors.book_id IN ?", ids).Order("authors.id").Find(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	authors := map[uint][]*models.Author{}
+	for _, row := range rows {
+		authors[row.BookID] = append(authors[row.BookID], &row.Author)
+	}
+
+	return authors, nil
+}
+
+func (bs *BookStore) FindByIsbn(isbn string) (*models.Book, error) {
+	book := &models.Book{}
+	err := bs.DB.First(book, &models.Book{ISBN: isbn}).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return book, nil
+}
+
+// Returns a page of the books visible to the viewer that match the
+// filter, ordered by ID. A nil filter matches every book.
+func (bs *BookStore) FindMany(filter *models.BookFilter, viewer *models.Profile, limit, offset int) ([]*models.Book, error) {
+	books := []*models.Book{}
+	err := bs.filtered(filter).Scopes(VisibleTo(viewer)).Order("id").Limit(limit).Offset(offset).Find(&books).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return books, nil
+}
+
+func (bs *BookStore) Count(filter *models.BookFilter, viewer *models.Profile) (int64, error) {
+	var count int64
+	err := bs.filtered(filter).Scopes(VisibleTo(viewer)).Count(&count).Error
+
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+type searchRow struct {
+	models.Book
+	Score     float64
+	Highlight string
+}
+
+// Runs a full-text search over titles, author names and publisher names,
+// best matches first. Only books visible to the viewer are included.
+func (bs *BookStore) Search(query string, viewer *models.Profile, limit int) ([]*models.BookSearchResult, error) {
+	rows := []*searchRow{}
+	search := bs.DB.Model(&models.Book{}).Scopes(VisibleTo(viewer))
+
+	if bs.DB.Dialector.Name() == "postgres" {
+		search = search.
+			Select(`books.*, ts_rank(books.search_vector, query) AS score,
+				ts_headline('simple', book_search_text(books.title, books.publisher_id, books.id), query,
+					'StartSel=<b>, StopSel=</b>, HighlightAll=true') AS highlight`).
+			Joins("CROSS JOIN websearch_to_tsquery('simple', ?) AS query", query).
+			Where("books.search_vector @@ query")
+	} else {
+		match := matchQuery(query)
+		if match == "" {
+			return []*models.BookSearchResult{}, nil
+		}
+
+		// bm25 is lower for better matches
+		search = search.
+			Select(`books.*, -bm25(book_search, 1.0, 0.4, 0.2) AS score,
+				highlight(book_search, 0, '<b>', '</b>') ||
+				CASE WHEN book_search.authors <> '' THEN ' · ' || highlight(book_search, 1, '<b>', '</b>') ELSE '' END ||
+				CASE WHEN book_search.publisher <> '' THEN ' · ' || highlight(book_search, 2, '<b>', '</b>') ELSE '' END AS highlight`).
+			Joins("JOIN book_search ON book_search.rowid = books.id").
+			Where("book_search MATCH ?", match)
+	}
+
+	err := search.Order("score DESC").Order("books.id").Limit(limit).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+
+	results := []*models.BookSearchResult{}
+	for _, row := range rows {
+		book := row.Book
+		results = append(results, &models.BookSearchResult{
+			Book:      &book,
+			Score:     row.Score,
+			Highlight: row.Highlight,
+		})
+	}
+
+	return results, nil
+}
+
+// Matches a quoted phrase or a word, either of which may be negated.
+var searchTerm = regexp.MustCompile(`-?"[^"]*"?|\S+`)
+
+// Turns a web search into an FTS5 query, reading it the way Postgres'
+// websearch_to_tsquery does: words and "quoted phrases" must all match
+// unless joined by or, and a leading - leaves out the books with a word.
+// Returns an empty string when there is nothing to search for.
+func matchQuery(search string) string {
+	parts := []string{}
+	or := false
+
+	for _, term := range searchTerm.FindAllString(search, -1) {
+		if strings.EqualFold(term, "or") {
+			or = len(parts) > 0
+			continue
+		}
+
+		negated := strings.HasPrefix(term, "-")
+		text := strings.Trim(strings.TrimPrefix(term, "-"), `"`)
+		if !strings.ContainsFunc(text, func(r rune) bool { return unicode.IsLetter(r) || unicode.IsNumber(r) }) {
+			continue
+		}
+
+		phrase := `"` + strings.ReplaceAll(text, `"`, `""`) + `"`
+		switch {
+		case negated && len(parts) == 0:
+			// FTS5 can't leave out words from everything
+		case negated:
+			parts = append(parts, "NOT", phrase)
+		case or:
+			parts = append(parts, "OR", phrase)
+		default:
+			parts = append(parts, phrase)
+		}
+
+		or = false
+	}
+
+	return strings.Join(parts, " ")
+}
+
+// Returns a page of the books waiting for approval that haven't been
+// rejected yet, oldest first.
+func (bs *BookStore) FindPending(limit, offset int) ([]*models.Book, error) {
+	books := []*models.Book{}
+	err := bs.pending().Order("id").Limit(limit).Offset(offset).Find(&books).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return books, nil
+}
+
+func (bs *BookStore) CountPending() (int64, error) {
+	var count int64
+	err := bs.pending().Count(&count).Error
+
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (bs *BookStore) pending() *gorm.DB {
+	return bs.DB.Model(&models.Book{}).Scopes(Pending)
+}
+
+func (bs *BookStore) Approve(id uint) (*models.Book, error) {
+	book, err := bs.FindById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	book.NeedsApproval = false
+	book.RejectionReason = nil
+	err = bs.DB.Save(book).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return book, nil
+}
+
+// Rejects the book with the given reason. The book stays hidden from
+// everyone but its submitter and moderators.
+func (bs *BookStore) Reject(id uint, reason string) (*models.Book, error) {
+	book, err := bs.FindById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	book.NeedsApproval = true
+	book.RejectionReason = &reason
+	err = bs.DB.Save(book).Error
 	if err != nil {
 		return nil, err
 	}
 
+	return book, nil
+}
+
+// Returns a page of the books added by the user, newest first.
+func (bs *BookStore) FindSubmitted(userUuid uuid.UUID, limit, offset int) ([]*models.Book, error) {
+	profile, err := NewUserStore(bs.DB).FindProfileByUserUuid(userUuid)
+	if err != nil {
+		return nil, err
+	}
+
+	books := []*models.Book{}
+	err = bs.DB.Where(&models.Book{ProfileID: &profile.ID}).
+		Order("created_at DESC").Order("id DESC").Limit(limit).Offset(offset).Find(&books).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return books, nil
+}
+
+func (bs *BookStore) CountSubmitted(userUuid uuid.UUID) (int64, error) {
+	profile, err := NewUserStore(bs.DB).FindProfileByUserUuid(userUuid)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int64
+	err = bs.DB.Model(&models.Book{}).Where(&models.Book{ProfileID: &profile.ID}).Count(&count).Error
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (bs *BookStore) filtered(filter *models.BookFilter) *gorm.DB {
+	query := bs.DB.Model(&models.Book{})
+	if filter == nil {
+		return query
+	}
+
+	if filter.Author != nil {
+		query = query.Where("id IN (?)",
+			bs.DB.Table("book_authors").Select("book_id").Where("author_id = ?", *filter.Author))
+	}
+
+	if filter.Publisher != nil {
+		query = query.Where("publisher_id = ?", *filter.Publisher)
+	}
+
+	if filter.PublishedFrom != nil {
+		from := time.Date(*filter.PublishedFrom, time.January, 1, 0, 0, 0, 0, time.UTC)
+		query = query.Where("published_at >= ?", from)
+	}
+
+	if filter.PublishedTo != nil {
+		to := time.Date(*filter.PublishedTo+1, time.January, 1, 0, 0, 0, 0, time.UTC)
+		query = query.Where("published_at < ?", to)
+	}
+
+	if filter.NeedsApproval != nil {
+		query = query.Where("needs_approval = ?", *filter.NeedsApproval)
+	}
+
+	return query
+}
+
+func (bs *BookStore) Create(input *models.CreateBook, userUuid uuid.UUID) (*models.Book, error) {
+	// Finding by no ids at all would find every author
+	authors := []*models.Author{}
+	if len(input.Authors) > 0 {
+		err := bs.DB.Find(&authors, input.Authors).Error
+		if err != nil {
+			return nil, err
+		}
+	}
+
 	profile := &models.Profile{}
-	err = bs.DB.First(profile, &models.Profile{UUID: userUuid}).Error
+	err := bs.DB.First(profile, &models.Profile{UUID: userUuid}).Error
 	if err != nil {
 		return nil, err
 	}
@@ -51,3 +348,44 @@ func (bs *BookStore) Create(input *models.CreateBook, userUuid uuid.UUID) (*mode
 
 	return book, nil
 }
+
+// Returns the reviews of a book, newest first. Reviews follow the
+// collection of their author, so only the ones in collections the viewer
+// can see are included.
+func (bs *BookStore) FindReviews(id uint, viewer *models.Profile, limit, offset int) ([]*models.Review, error) {
+	reviews := []*models.Review{}
+	err := bs.reviews(id, viewer).
+		Select(`collection_items.id, collection_items.profile_id, collection_items.rating,
+			collection_items.review AS body, collection_items.spoiler, collection_items.reviewed_at`).
+		Order("collection_items.reviewed_at DESC").Order("collection_items.id DESC").
+		Limit(limit).Offset(offset).Scan(&reviews).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return reviews, nil
+}
+
+func (bs *BookStore) CountReviews(id uint, viewer *models.Profile) (int64, error) {
+	var count int64
+	err := bs.reviews(id, viewer).Count(&count).Error
+
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (bs *BookStore) reviews(id uint, viewer *models.Profile) *gorm.DB {
+	var viewerId uint
+	if viewer != nil {
+		viewerId = viewer.ID
+	}
+
+	return bs.DB.Model(&models.CollectionItem{}).
+		Joins("JOIN settings ON settings.profile_id = collection_items.profile_id AND settings.deleted_at IS NULL").
+		Where("collection_items.book_id = ? AND collection_items.review IS NOT NULL", id).
+		Where("settings.show_collection = ? OR collection_items.profile_id = ?", true, viewerId)
+}
@@ -0,0 +1,83 @@
+package store
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/marcos-brito/booklist/internal/models"
+	"gorm.io/gorm"
+)
+
+type ExportStore struct {
+	*gorm.DB
+}
+
+func NewExportStore(db *gorm.DB) *ExportStore {
+	return &ExportStore{db}
+}
+
+// Returns the export with the token, as long as it hasn't expired.
+func (es *ExportStore) FindByToken(token string) (*models.DataExport, error) {
+	export := &models.DataExport{}
+	err := es.DB.Where("token = ? AND expires_at > ?", token, time.Now()).First(export).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return export, nil
+}
+
+func (es *ExportStore) Create(profileId uint, format models.ExportFormat, ttl time.Duration) (*models.DataExport, error) {
+	token := make([]byte, 32)
+	_, err := rand.Read(token)
+	if err != nil {
+		return nil, err
+	}
+
+	export := &models.DataExport{
+		ProfileID: profileId,
+		Token:     hex.EncodeToString(token),
+		Format:    format,
+		ExpiresAt: time.Now().Add(ttl),
+	}
+
+	err = es.DB.Create(export).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return export, nil
+}
+
+// Loads everything of the profile that goes into an export, including
+// submissions that were never approved.
+func (es *ExportStore) FindData(profileId uint) (*models.ExportData, error) {
+	data := &models.ExportData{Settings: &models.Settings{}}
+	err := es.DB.First(data.Settings, &models.Settings{ProfileID: profileId}).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = es.DB.Preload("Book.Authors").Preload("Book.Publisher").
+		Where(&models.CollectionItem{ProfileID: profileId}).Order("id").Find(&data.Collection).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = es.DB.Preload("Books", func(db *gorm.DB) *gorm.DB {
+		return db.Order("books.id")
+	}).Preload("Books.Authors").Preload("Books.Publisher").
+		Where(&models.List{ProfileID: profileId}).Order("id").Find(&data.Lists).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = es.DB.Preload("Authors").Preload("Publisher").
+		Where(&models.Book{ProfileID: &profileId}).Order("id").Find(&data.SubmittedBooks).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return data, nil
+}
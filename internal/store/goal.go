@@ -0,0 +1,90 @@
+package store
+
+import (
+	"time"
+
+	"github.com/marcos-brito/booklist/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+type GoalStore struct {
+	*gorm.DB
+}
+
+func NewGoalStore(db *gorm.DB) *GoalStore {
+	return &GoalStore{db}
+}
+
+// Returns the goal of the profile for the year along with how much of it
+// was already read.
+func (gs *GoalStore) FindByYear(profileId uint, year int) (*models.ReadingGoal, error) {
+	goal := &models.ReadingGoal{}
+	err := gs.DB.Where(&models.ReadingGoal{ProfileID: profileId, Year: year}).First(goal).Error
+	if err != nil {
+		return nil, err
+	}
+
+	err = gs.countRead(goal)
+	if err != nil {
+		return nil, err
+	}
+
+	return goal, nil
+}
+
+// Creates the goal for the year or replaces its targets if there is one.
+func (gs *GoalStore) Set(profileId uint, year int, books, pages *int) (*models.ReadingGoal, error) {
+	goal := &models.ReadingGoal{ProfileID: profileId, Year: year, Books: books, Pages: pages}
+	err := gs.DB.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "profile_id"}, {Name: "year"}},
+		DoUpdates: clause.AssignmentColumns([]string{"books", "pages", "updated_at"}),
+	}).Create(goal).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return gs.FindByYear(profileId, year)
+}
+
+// Marks the goal as completed. Reports whether it was this call that did
+// it, so completion is only acted upon once.
+func (gs *GoalStore) Complete(goal *models.ReadingGoal) (bool, error) {
+	now := time.Now()
+	res := gs.DB.Model(&models.ReadingGoal{}).
+		Where("id = ? AND completed_at IS NULL", goal.ID).
+		Update("completed_at", now)
+
+	if res.Error != nil {
+		return false, res.Error
+	}
+
+	if res.RowsAffected == 0 {
+		return false, nil
+	}
+
+	goal.CompletedAt = &now
+	return true, nil
+}
+
+// Undoes Complete, for when what was read no longer reaches the goal.
+func (gs *GoalStore) Reopen(goal *models.ReadingGoal) error {
+	err := gs.DB.Model(goal).Update("completed_at", nil).Error
+	if err != nil {
+		return err
+	}
+
+	goal.CompletedAt = nil
+	return nil
+}
+
+func (gs *GoalStore) countRead(goal *models.ReadingGoal) error {
+	from := time.Date(goal.Year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(1, 0, 0).Add(-time.Nanosecond)
+
+	return finishedItems(gs.DB, goal.ProfileID, &from, &to).
+		Select("COUNT(*) AS books_read, COALESCE(SUM(books.page_count), 0) AS pages_read").
+		Joins("JOIN books ON books.id = collection_items.book_id").
+		Row().Scan(&goal.BooksRead, &goal.PagesRead)
+}
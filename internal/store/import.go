@@ -0,0 +1,77 @@
+package store
+
+import (
+	"time"
+
+	"github.com/marcos-brito/booklist/internal/models"
+	"gorm.io/gorm"
+)
+
+type ImportStore struct {
+	*gorm.DB
+}
+
+func NewImportStore(db *gorm.DB) *ImportStore {
+	return &ImportStore{db}
+}
+
+func (is *ImportStore) FindById(id uint) (*models.ImportJob, error) {
+	job := &models.ImportJob{}
+	err := is.DB.Preload("Errors", func(db *gorm.DB) *gorm.DB {
+		return db.Order("line, id")
+	}).First(job, id).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+func (is *ImportStore) Create(profileId uint, format models.ImportFormat, total int) (*models.ImportJob, error) {
+	job := &models.ImportJob{
+		ProfileID: profileId,
+		Format:    format,
+		Status:    models.ImportStatusPending,
+		Total:     total,
+		Errors:    []*models.ImportError{},
+	}
+
+	err := is.DB.Create(job).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return job, nil
+}
+
+// Saves how far the job has got. Errors are saved as they happen, so
+// they are left alone here.
+func (is *ImportStore) SaveProgress(job *models.ImportJob) error {
+	return is.DB.Model(job).Select("status", "processed", "imported").Updates(job).Error
+}
+
+func (is *ImportStore) AddError(job *models.ImportJob, line int, title, message string) error {
+	importError := &models.ImportError{
+		ImportJobID: job.ID,
+		Line:        line,
+		Title:       title,
+		Message:     message,
+	}
+
+	err := is.DB.Create(importError).Error
+	if err != nil {
+		return err
+	}
+
+	job.Errors = append(job.Errors, importError)
+	return nil
+}
+
+func (is *ImportStore) Finish(job *models.ImportJob, status models.ImportStatus) error {
+	now := time.Now()
+	job.Status = status
+	job.FinishedAt = &now
+
+	return is.DB.Model(job).Select("status", "processed", "imported", "finished_at").Updates(job).Error
+}
@@ -4,7 +4,6 @@ import (
 	"github.com/google/uuid"
 	"github.com/marcos-brito/booklist/internal/models"
 	"gorm.io/gorm"
-	"gorm.io/gorm/clause"
 )
 
 type ListStore struct {
@@ -45,19 +44,68 @@ func (ls *ListStore) FindById(id uint) (*models.List, error) {
 	return list, nil
 }
 
-func (ls *ListStore) FindBooks(id uint) ([]*models.Book, error) {
-	list := &models.List{}
-	list.ID = id
-	books := []*models.Book{}
-	err := ls.DB.Model(list).Association("Books").Find(&books)
+type listBook struct {
+	models.Book
+	ListID uint
+}
 
+// Returns the books in each of the lists, keyed by list, whether they are
+// visible to anyone or not.
+func (ls *ListStore) FindBooksByLists(ids []uint) (map[uint][]*models.Book, error) {
+	rows := []*listBook{}
+	err := ls.DB.Model(&models.Book{}).Select("books.*, list_books.list_id").
+		Joins("JOIN list_books ON list_books.book_id = books.id").
+		Where("list_books.list_id IN ?", ids).Order("books.id").Find(&rows).Error
 	if err != nil {
 		return nil, err
 	}
 
+	books := map[uint][]*models.Book{}
+	for _, row := range rows {
+		books[row.ListID] = append(books[row.ListID], &row.Book)
+	}
+
 	return books, nil
 }
 
+// Returns a page of the books in the list that are visible to the
+// viewer, in the order of their ids.
+func (ls *ListStore) FindBooksPage(id uint, viewer *models.Profile, page *models.Page) ([]*models.Book, bool, error) {
+	return findPage[models.Book](ls.books(id, viewer), byId("books.id"), page)
+}
+
+func (ls *ListStore) CountBooks(id uint, viewer *models.Profile) (int64, error) {
+	var count int64
+	err := ls.books(id, viewer).Count(&count).Error
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (ls *ListStore) books(id uint, viewer *models.Profile) *gorm.DB {
+	return ls.DB.Model(&models.Book{}).Scopes(VisibleTo(viewer)).
+		Joins("JOIN list_books ON list_books.book_id = books.id").
+		Where("list_books.list_id = ?", id)
+}
+
+// Returns the list of the user with exactly the given name.
+func (ls *ListStore) FindByName(name string, userUuid uuid.UUID) (*models.List, error) {
+	profile, err := NewUserStore(ls.DB).FindProfileByUserUuid(userUuid)
+	if err != nil {
+		return nil, err
+	}
+
+	list := &models.List{}
+	err = ls.DB.Where(&models.List{ProfileID: profile.ID, Name: name}).First(list).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
 func (ls *ListStore) Create(name string, desc *string, publish bool, userUuid uuid.UUID) (*models.List, error) {
 	profile, err := NewUserStore(ls.DB).FindProfileByUserUuid(userUuid)
 	if err != nil {
@@ -115,7 +163,7 @@ func (ls *ListStore) Clone(id uint, userUuid uuid.UUID) (*models.List, error) {
 		Books:       original.Books,
 	}
 
-	err = ls.DB.Clauses(clause.Returning{}).Create(list).Error
+	err = ls.DB.Create(list).Error
 	if err != nil {
 		return nil, err
 	}
@@ -154,11 +202,88 @@ func (ls *ListStore) Unpublish(id uint) (*models.List, error) {
 }
 
 func (ls *ListStore) Follow(id uint, userUuid uuid.UUID) (*models.List, error) {
-	return nil, nil
+	profile, err := NewUserStore(ls.DB).FindProfileByUserUuid(userUuid)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := ls.FindById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = ls.DB.Model(profile).Association("FollowedLists").Append(list)
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
 }
 
 func (ls *ListStore) Unfollow(id uint, userUuid uuid.UUID) (*models.List, error) {
-	return nil, nil
+	profile, err := NewUserStore(ls.DB).FindProfileByUserUuid(userUuid)
+	if err != nil {
+		return nil, err
+	}
+
+	list, err := ls.FindById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = ls.DB.Model(profile).Association("FollowedLists").Delete(list)
+	if err != nil {
+		return nil, err
+	}
+
+	return list, nil
+}
+
+func (ls *ListStore) IsFollower(id uint, userUuid uuid.UUID) (bool, error) {
+	profile, err := NewUserStore(ls.DB).FindProfileByUserUuid(userUuid)
+	if err != nil {
+		return false, err
+	}
+
+	var count int64
+	err = ls.DB.Model(&models.ListFollow{}).
+		Where(&models.ListFollow{ProfileID: profile.ID, ListID: id}).Count(&count).Error
+	if err != nil {
+		return false, err
+	}
+
+	return count > 0, nil
+}
+
+// Returns the profiles following the list with their settings loaded.
+func (ls *ListStore) FindFollowers(id uint) ([]*models.Profile, error) {
+	profiles := []*models.Profile{}
+	err := ls.DB.Preload("Settings").
+		Joins("JOIN list_follows ON list_follows.profile_id = profiles.id").
+		Where("list_follows.list_id = ?", id).
+		Order("list_follows.created_at").
+		Find(&profiles).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return profiles, nil
+}
+
+// Private profiles are left out, as they are from the followers field.
+func (ls *ListStore) CountFollowers(id uint) (int64, error) {
+	var count int64
+	err := ls.DB.Model(&models.ListFollow{}).
+		Joins("JOIN settings ON settings.profile_id = list_follows.profile_id AND settings.deleted_at IS NULL").
+		Where("list_follows.list_id = ? AND settings.private = ?", id, false).
+		Count(&count).Error
+
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
 }
 
 func (ls *ListStore) AddBook(listId, bookId uint) (*models.List, error) {
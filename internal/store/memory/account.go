@@ -0,0 +1,97 @@
+package memory
+
+import (
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/marcos-brito/booklist/internal/models"
+)
+
+// Deletes the profile of the user and everything it owns for good.
+// Books, authors and publishers the user submitted stay for everyone
+// else, but without the profile. Deleting a profile that doesn't exist
+// does nothing, so it's safe to do more than once.
+func (us *UserStore) DeleteAccount(userUuid uuid.UUID) error {
+	defer us.lock()()
+
+	profile, err := us.existingProfile(userUuid)
+	if err != nil {
+		return nil
+	}
+
+	// Items that were already deleted had their ratings removed then
+	for _, item := range us.items.where(func(item *models.CollectionItem) bool { return item.ProfileID == profile.ID }) {
+		us.updateBookRating(item.BookID, item.Rating, nil)
+	}
+
+	items := us.items.purge(func(item *models.CollectionItem) bool {
+		return item.ProfileID == profile.ID
+	})
+
+	us.events.purge(func(event *models.ReadingEvent) bool {
+		return slices.Contains(items, event.CollectionItemID)
+	})
+
+	us.sessions.purge(func(session *models.ReadingSession) bool {
+		return slices.Contains(items, session.CollectionItemID)
+	})
+
+	lists := us.lists.purge(func(list *models.List) bool {
+		return list.ProfileID == profile.ID
+	})
+
+	us.listBooks = slices.DeleteFunc(us.listBooks, func(p pair) bool {
+		return slices.Contains(lists, p.left)
+	})
+
+	us.follows = slices.DeleteFunc(us.follows, func(follow *models.ListFollow) bool {
+		return slices.Contains(lists, follow.ListID) || follow.ProfileID == profile.ID
+	})
+
+	us.settings.purge(func(settings *models.Settings) bool {
+		return settings.ProfileID == profile.ID
+	})
+
+	us.goals.purge(func(goal *models.ReadingGoal) bool {
+		return goal.ProfileID == profile.ID
+	})
+
+	jobs := us.imports.purge(func(job *models.ImportJob) bool {
+		return job.ProfileID == profile.ID
+	})
+
+	us.importErrors.purge(func(importError *models.ImportError) bool {
+		return slices.Contains(jobs, importError.ImportJobID)
+	})
+
+	us.exports.purge(func(export *models.DataExport) bool {
+		return export.ProfileID == profile.ID
+	})
+
+	for _, book := range us.books.all() {
+		if book.ProfileID != nil && *book.ProfileID == profile.ID {
+			book.ProfileID = nil
+			us.books.put(book)
+		}
+	}
+
+	for _, author := range us.authors.all() {
+		if author.ProfileID != nil && *author.ProfileID == profile.ID {
+			author.ProfileID = nil
+			us.authors.put(author)
+		}
+	}
+
+	for _, publisher := range us.publishers.all() {
+		if publisher.ProfileID != nil && *publisher.ProfileID == profile.ID {
+			publisher.ProfileID = nil
+			us.publishers.put(publisher)
+		}
+	}
+
+	us.profiles.purge(func(found *models.Profile) bool {
+		return found.ID == profile.ID
+	})
+
+	return nil
+}
@@ -0,0 +1,196 @@
+package memory
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+
+	"github.com/google/uuid"
+	"github.com/marcos-brito/booklist/internal/models"
+	"gorm.io/gorm"
+)
+
+type AuthorStore struct {
+	*DB
+}
+
+func NewAuthorStore(db *DB) *AuthorStore {
+	return &AuthorStore{db}
+}
+
+func (as *AuthorStore) FindById(id uint) (*models.Author, error) {
+	defer as.lock()()
+
+	author, ok := as.authors.find(id)
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return author, nil
+}
+
+func (as *AuthorStore) FindManyById(ids ...uint) ([]*models.Author, error, *uint) {
+	defer as.lock()()
+
+	authors := []*models.Author{}
+	for _, id := range ids {
+		author, ok := as.authors.find(id)
+		if !ok {
+			return nil, gorm.ErrRecordNotFound, &id
+		}
+
+		authors = append(authors, author)
+	}
+
+	return authors, nil, nil
+}
+
+// Returns a page of the authors visible to the viewer whose name
+// contains search, ordered by name. An empty search matches every author.
+func (as *AuthorStore) Search(search string, needsApproval *bool, viewer *models.Profile, limit, offset int) ([]*models.Author, error) {
+	defer as.lock()()
+
+	search = strings.ToLower(search)
+	authors := as.authors.where(func(author *models.Author) bool {
+		if !author.VisibleTo(viewer) || !strings.Contains(strings.ToLower(author.Name), search) {
+			return false
+		}
+
+		return needsApproval == nil || author.NeedsApproval == *needsApproval
+	})
+
+	slices.SortStableFunc(authors, func(a, b *models.Author) int {
+		return strings.Compare(a.Name, b.Name)
+	})
+
+	return window(authors, limit, offset), nil
+}
+
+// Returns the author visible to the viewer whose name matches the given
+// one, ignoring case. Approved authors come first.
+func (as *AuthorStore) FindByName(name string, viewer *models.Profile) (*models.Author, error) {
+	defer as.lock()()
+
+	authors := as.authors.where(func(author *models.Author) bool {
+		return author.VisibleTo(viewer) && strings.EqualFold(author.Name, name)
+	})
+
+	if len(authors) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	slices.SortStableFunc(authors, func(a, b *models.Author) int {
+		return cmp.Compare(boolRank(a.NeedsApproval), boolRank(b.NeedsApproval))
+	})
+
+	return authors[0], nil
+}
+
+// Orders false before true, like the database does.
+func boolRank(b bool) int {
+	if b {
+		return 1
+	}
+
+	return 0
+}
+
+// Returns the author's books that are visible to the viewer.
+func (as *AuthorStore) FindBooks(id uint, viewer *models.Profile) ([]*models.Book, error) {
+	defer as.lock()()
+
+	ids := lefts(as.bookAuthors, id)
+	return as.books.where(func(book *models.Book) bool {
+		return slices.Contains(ids, book.ID) && book.VisibleTo(viewer)
+	}), nil
+}
+
+func (as *AuthorStore) Create(input *models.CreateAuthor, userUuid uuid.UUID) (*models.Author, error) {
+	defer as.lock()()
+
+	profile := as.profile(userUuid)
+	author := models.NewAuthorFromInput(input, profile.ID)
+	as.authors.insert(author)
+
+	return author, nil
+}
+
+func (as *AuthorStore) Update(id uint, changes *models.UpdateAuthor) (*models.Author, error) {
+	defer as.lock()()
+
+	author, ok := as.authors.find(id)
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	if changes.Name != nil {
+		author.Name = *changes.Name
+	}
+
+	if changes.BirthDay != nil {
+		author.BirthDay = changes.BirthDay
+	}
+
+	as.authors.save(author)
+	return author, nil
+}
+
+// Moves every book of the source author to the target and deletes the
+// source. Books credited to both keep a single entry.
+func (as *AuthorStore) Merge(sourceId, targetId uint) (*models.Author, error) {
+	defer as.lock()()
+
+	credited := lefts(as.bookAuthors, targetId)
+	merged := []pair{}
+	for _, p := range as.bookAuthors {
+		if p.right == sourceId {
+			if slices.Contains(credited, p.left) {
+				continue
+			}
+
+			p.right = targetId
+		}
+
+		merged = append(merged, p)
+	}
+
+	as.bookAuthors = merged
+	as.authors.delete(sourceId)
+
+	author, ok := as.authors.find(targetId)
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return author, nil
+}
+
+func (as *AuthorStore) Approve(id uint) (*models.Author, error) {
+	defer as.lock()()
+
+	author, ok := as.authors.find(id)
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	author.NeedsApproval = false
+	author.RejectionReason = nil
+	as.authors.save(author)
+
+	return author, nil
+}
+
+func (as *AuthorStore) Reject(id uint, reason string) (*models.Author, error) {
+	defer as.lock()()
+
+	author, ok := as.authors.find(id)
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	author.NeedsApproval = true
+	author.RejectionReason = &reason
+	as.authors.save(author)
+
+	return author, nil
+}
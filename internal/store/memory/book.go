@@ -0,0 +1,434 @@
+package memory
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/google/uuid"
+	"github.com/marcos-brito/booklist/internal/models"
+	"gorm.io/gorm"
+)
+
+type BookStore struct {
+	*DB
+}
+
+func NewBookStore(db *DB) *BookStore {
+	return &BookStore{db}
+}
+
+func (bs *BookStore) FindById(id uint) (*models.Book, error) {
+	defer bs.lock()()
+
+	book, ok := bs.books.find(id)
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return book, nil
+}
+
+func (bs *BookStore) FindByIds(ids []uint) ([]*models.Book, error) {
+	defer bs.lock()()
+
+	return bs.books.where(func(book *models.Book) bool {
+		return slices.Contains(ids, book.ID)
+	}), nil
+}
+
+// Returns the authors of each of the books, keyed by book, whether they
+// are visible to anyone or not.
+func (bs *BookStore) FindAuthorsByBooks(ids []uint) (map[uint][]*models.Author, error) {
+	defer bs.lock()()
+
+	authors := map[uint][]*models.Author{}
+	for _, id := range ids {
+		found := bs.bookAuthorsOf(id)
+		if len(found) > 0 {
+			authors[id] = found
+		}
+	}
+
+	return authors, nil
+}
+
+// Returns the authors of the book ordered by id.
+func (db *DB) bookAuthorsOf(id uint) []*models.Author {
+	ids := rights(db.bookAuthors, id)
+	return db.authors.where(func(author *models.Author) bool {
+		return slices.Contains(ids, author.ID)
+	})
+}
+
+func (bs *BookStore) FindByIsbn(isbn string) (*models.Book, error) {
+	defer bs.lock()()
+
+	books := bs.books.where(func(book *models.Book) bool {
+		return book.ISBN == isbn
+	})
+
+	if len(books) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return books[0], nil
+}
+
+// Returns a page of the books visible to the viewer that match the
+// filter, ordered by ID. A nil filter matches every book.
+func (bs *BookStore) FindMany(filter *models.BookFilter, viewer *models.Profile, limit, offset int) ([]*models.Book, error) {
+	defer bs.lock()()
+
+	return window(bs.filtered(filter, viewer), limit, offset), nil
+}
+
+func (bs *BookStore) Count(filter *models.BookFilter, viewer *models.Profile) (int64, error) {
+	defer bs.lock()()
+
+	return int64(len(bs.filtered(filter, viewer))), nil
+}
+
+// Weights of the title, the authors and the publisher in searches, the
+// same ts_rank gives the weights they have in the search document.
+var searchWeights = []float64{1, 0.4, 0.2}
+
+// Matches books whose title, authors or publisher contain every word of
+// the query, best matches first. Postgres understands more of the query
+// and ranks matches in its own way, so only what matches is the same.
+func (bs *BookStore) Search(query string, viewer *models.Profile, limit int) ([]*models.BookSearchResult, error) {
+	defer bs.lock()()
+
+	terms := words(query)
+	results := []*models.BookSearchResult{}
+	if len(terms) == 0 {
+		return results, nil
+	}
+
+	for _, book := range bs.books.all() {
+		if !book.VisibleTo(viewer) {
+			continue
+		}
+
+		fields := []string{book.Title, bs.searchAuthors(book.ID), ""}
+		if book.PublisherID != nil {
+			if publisher, ok := bs.publishers.find(*book.PublisherID); ok {
+				fields[2] = publisher.Name
+			}
+		}
+
+		score, matches := 0.0, true
+		for _, term := range terms {
+			weight := 0.0
+			for i, field := range fields {
+				if slices.Contains(words(field), term) {
+					weight = max(weight, searchWeights[i])
+				}
+			}
+
+			score += weight
+			matches = matches && weight > 0
+		}
+
+		if !matches {
+			continue
+		}
+
+		results = append(results, &models.BookSearchResult{
+			Book:      book,
+			Score:     score,
+			Highlight: highlight(fields, terms),
+		})
+	}
+
+	slices.SortStableFunc(results, func(a, b *models.BookSearchResult) int {
+		return cmp.Compare(b.Score, a.Score)
+	})
+
+	return window(results, limit, 0), nil
+}
+
+// Names of the authors of the book ordered by name, as they are in the
+// search document.
+func (db *DB) searchAuthors(id uint) string {
+	names := []string{}
+	for _, author := range db.bookAuthorsOf(id) {
+		names = append(names, author.Name)
+	}
+
+	slices.Sort(names)
+	return strings.Join(names, " ")
+}
+
+// Splits text into lowercase words the way the simple text search
+// configuration does.
+func words(text string) []string {
+	return strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+// Joins the fields like book_search_text and wraps every word matching
+// the terms in bold, like ts_headline with HighlightAll.
+func highlight(fields []string, terms []string) string {
+	parts := []string{}
+	for _, field := range fields {
+		if field == "" {
+			continue
+		}
+
+		var b strings.Builder
+		start := -1
+		flush := func(end int) {
+			word := field[start:end]
+			if slices.Contains(terms, strings.ToLower(word)) {
+				word = "<b>" + word + "</b>"
+			}
+
+			b.WriteString(word)
+			start = -1
+		}
+
+		for i, r := range field {
+			if unicode.IsLetter(r) || unicode.IsDigit(r) {
+				if start < 0 {
+					start = i
+				}
+
+				continue
+			}
+
+			if start >= 0 {
+				flush(i)
+			}
+
+			b.WriteRune(r)
+		}
+
+		if start >= 0 {
+			flush(len(field))
+		}
+
+		parts = append(parts, b.String())
+	}
+
+	return strings.Join(parts, " · ")
+}
+
+// Returns a page of the books waiting for approval that haven't been
+// rejected yet, oldest first.
+func (bs *BookStore) FindPending(limit, offset int) ([]*models.Book, error) {
+	defer bs.lock()()
+
+	return window(bs.books.where(bookPending), limit, offset), nil
+}
+
+func (bs *BookStore) CountPending() (int64, error) {
+	defer bs.lock()()
+
+	return int64(len(bs.books.where(bookPending))), nil
+}
+
+// Same as Pending in package store.
+func bookPending(book *models.Book) bool {
+	return book.NeedsApproval && book.RejectionReason == nil
+}
+
+func (bs *BookStore) Approve(id uint) (*models.Book, error) {
+	defer bs.lock()()
+
+	book, ok := bs.books.find(id)
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	book.NeedsApproval = false
+	book.RejectionReason = nil
+	bs.books.save(book)
+
+	return book, nil
+}
+
+// Rejects the book with the given reason. The book stays hidden from
+// everyone but its submitter and moderators.
+func (bs *BookStore) Reject(id uint, reason string) (*models.Book, error) {
+	defer bs.lock()()
+
+	book, ok := bs.books.find(id)
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	book.NeedsApproval = true
+	book.RejectionReason = &reason
+	bs.books.save(book)
+
+	return book, nil
+}
+
+// Returns a page of the books added by the user, newest first.
+func (bs *BookStore) FindSubmitted(userUuid uuid.UUID, limit, offset int) ([]*models.Book, error) {
+	defer bs.lock()()
+
+	books := bs.submitted(userUuid)
+	slices.SortStableFunc(books, func(a, b *models.Book) int {
+		return cmp.Or(b.CreatedAt.Compare(a.CreatedAt), cmp.Compare(b.ID, a.ID))
+	})
+
+	return window(books, limit, offset), nil
+}
+
+func (bs *BookStore) CountSubmitted(userUuid uuid.UUID) (int64, error) {
+	defer bs.lock()()
+
+	return int64(len(bs.submitted(userUuid))), nil
+}
+
+func (bs *BookStore) submitted(userUuid uuid.UUID) []*models.Book {
+	profile := bs.profile(userUuid)
+	return bs.books.where(func(book *models.Book) bool {
+		return book.ProfileID != nil && *book.ProfileID == profile.ID
+	})
+}
+
+func (bs *BookStore) filtered(filter *models.BookFilter, viewer *models.Profile) []*models.Book {
+	return bs.books.where(func(book *models.Book) bool {
+		if !book.VisibleTo(viewer) {
+			return false
+		}
+
+		if filter == nil {
+			return true
+		}
+
+		if filter.Author != nil && !slices.Contains(rights(bs.bookAuthors, book.ID), *filter.Author) {
+			return false
+		}
+
+		if filter.Publisher != nil && (book.PublisherID == nil || *book.PublisherID != *filter.Publisher) {
+			return false
+		}
+
+		if filter.PublishedFrom != nil {
+			from := time.Date(*filter.PublishedFrom, time.January, 1, 0, 0, 0, 0, time.UTC)
+			if book.PublishedAt == nil || book.PublishedAt.Before(from) {
+				return false
+			}
+		}
+
+		if filter.PublishedTo != nil {
+			to := time.Date(*filter.PublishedTo+1, time.January, 1, 0, 0, 0, 0, time.UTC)
+			if book.PublishedAt == nil || !book.PublishedAt.Before(to) {
+				return false
+			}
+		}
+
+		return filter.NeedsApproval == nil || book.NeedsApproval == *filter.NeedsApproval
+	})
+}
+
+// Fails like the database would for an ISBN that's taken or a publisher
+// that doesn't exist.
+func (bs *BookStore) Create(input *models.CreateBook, userUuid uuid.UUID) (*models.Book, error) {
+	defer bs.lock()()
+
+	authors := bs.authors.where(func(author *models.Author) bool {
+		return slices.Contains(input.Authors, author.ID)
+	})
+
+	profile, err := bs.existingProfile(userUuid)
+	if err != nil {
+		return nil, err
+	}
+
+	taken := bs.books.where(func(book *models.Book) bool {
+		return book.ISBN == input.Isbn
+	})
+
+	if len(taken) > 0 {
+		return nil, gorm.ErrDuplicatedKey
+	}
+
+	if input.Publisher != nil {
+		if _, ok := bs.publishers.find(*input.Publisher); !ok {
+			return nil, gorm.ErrForeignKeyViolated
+		}
+	}
+
+	book := models.NewBookFromInput(input, nil, profile.ID)
+	bs.books.insert(book)
+	for _, author := range authors {
+		bs.bookAuthors = append(bs.bookAuthors, pair{book.ID, author.ID})
+	}
+
+	book.Authors = authors
+	return book, nil
+}
+
+// Returns the reviews of a book, newest first. Reviews follow the
+// collection of their author, so only the ones in collections the viewer
+// can see are included.
+func (bs *BookStore) FindReviews(id uint, viewer *models.Profile, limit, offset int) ([]*models.Review, error) {
+	defer bs.lock()()
+
+	items := bs.reviews(id, viewer)
+	slices.SortStableFunc(items, func(a, b *models.CollectionItem) int {
+		return cmp.Or(compareTimes(b.ReviewedAt, a.ReviewedAt), cmp.Compare(b.ID, a.ID))
+	})
+
+	reviews := []*models.Review{}
+	for _, item := range window(items, limit, offset) {
+		review := &models.Review{
+			ID:        item.ID,
+			ProfileID: item.ProfileID,
+			Rating:    item.Rating,
+			Body:      *item.Review,
+			Spoiler:   item.Spoiler,
+		}
+
+		if item.ReviewedAt != nil {
+			review.ReviewedAt = *item.ReviewedAt
+		}
+
+		reviews = append(reviews, review)
+	}
+
+	return reviews, nil
+}
+
+func (bs *BookStore) CountReviews(id uint, viewer *models.Profile) (int64, error) {
+	defer bs.lock()()
+
+	return int64(len(bs.reviews(id, viewer))), nil
+}
+
+func (bs *BookStore) reviews(id uint, viewer *models.Profile) []*models.CollectionItem {
+	return bs.items.where(func(item *models.CollectionItem) bool {
+		if item.BookID != id || item.Review == nil {
+			return false
+		}
+
+		settings, ok := bs.settingsOf(item.ProfileID)
+		if !ok {
+			return false
+		}
+
+		return settings.ShowCollection || (viewer != nil && item.ProfileID == viewer.ID)
+	})
+}
+
+// Orders nil times last, like nulls in ascending order.
+func compareTimes(a, b *time.Time) int {
+	switch {
+	case a == nil && b == nil:
+		return 0
+	case a == nil:
+		return 1
+	case b == nil:
+		return -1
+	}
+
+	return a.Compare(*b)
+}
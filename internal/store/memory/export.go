@@ -0,0 +1,111 @@
+package memory
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"time"
+
+	"github.com/marcos-brito/booklist/internal/models"
+	"gorm.io/gorm"
+)
+
+type ExportStore struct {
+	*DB
+}
+
+func NewExportStore(db *DB) *ExportStore {
+	return &ExportStore{db}
+}
+
+// Returns the export with the token, as long as it hasn't expired.
+func (es *ExportStore) FindByToken(token string) (*models.DataExport, error) {
+	defer es.lock()()
+
+	now := time.Now()
+	exports := es.exports.where(func(export *models.DataExport) bool {
+		return export.Token == token && export.ExpiresAt.After(now)
+	})
+
+	if len(exports) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return exports[0], nil
+}
+
+func (es *ExportStore) Create(profileId uint, format models.ExportFormat, ttl time.Duration) (*models.DataExport, error) {
+	defer es.lock()()
+
+	token := make([]byte, 32)
+	_, err := rand.Read(token)
+	if err != nil {
+		return nil, err
+	}
+
+	export := &models.DataExport{
+		ProfileID: profileId,
+		Token:     hex.EncodeToString(token),
+		Format:    format,
+		ExpiresAt: time.Now().Add(ttl),
+	}
+
+	es.exports.insert(export)
+	return export, nil
+}
+
+// Loads everything of the profile that goes into an export, including
+// submissions that were never approved.
+func (es *ExportStore) FindData(profileId uint) (*models.ExportData, error) {
+	defer es.lock()()
+
+	settings, ok := es.settingsOf(profileId)
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	data := &models.ExportData{Settings: settings}
+	data.Collection = es.items.where(func(item *models.CollectionItem) bool {
+		return item.ProfileID == profileId
+	})
+
+	for _, item := range data.Collection {
+		book, ok := es.books.find(item.BookID)
+		if ok {
+			item.Book = *es.withAssociations(book)
+		}
+	}
+
+	data.Lists = es.lists.where(func(list *models.List) bool {
+		return list.ProfileID == profileId
+	})
+
+	for _, list := range data.Lists {
+		list.Books = []models.Book{}
+		for _, book := range es.listBooksOf(list.ID) {
+			list.Books = append(list.Books, *es.withAssociations(book))
+		}
+	}
+
+	data.SubmittedBooks = es.books.where(func(book *models.Book) bool {
+		return book.ProfileID != nil && *book.ProfileID == profileId
+	})
+
+	for _, book := range data.SubmittedBooks {
+		es.withAssociations(book)
+	}
+
+	return data, nil
+}
+
+// Loads the authors and publisher of the book, like preloading them.
+func (db *DB) withAssociations(book *models.Book) *models.Book {
+	book.Authors = db.bookAuthorsOf(book.ID)
+	if book.PublisherID != nil {
+		publisher, ok := db.publishers.find(*book.PublisherID)
+		if ok {
+			book.Publisher = *publisher
+		}
+	}
+
+	return book
+}
@@ -0,0 +1,106 @@
+package memory
+
+import (
+	"time"
+
+	"github.com/marcos-brito/booklist/internal/models"
+	"gorm.io/gorm"
+)
+
+type GoalStore struct {
+	*DB
+}
+
+func NewGoalStore(db *DB) *GoalStore {
+	return &GoalStore{db}
+}
+
+// Returns the goal of the profile for the year along with how much of it
+// was already read.
+func (gs *GoalStore) FindByYear(profileId uint, year int) (*models.ReadingGoal, error) {
+	defer gs.lock()()
+
+	return gs.goalOf(profileId, year)
+}
+
+func (gs *GoalStore) goalOf(profileId uint, year int) (*models.ReadingGoal, error) {
+	goals := gs.goals.where(func(goal *models.ReadingGoal) bool {
+		return goal.ProfileID == profileId && goal.Year == year
+	})
+
+	if len(goals) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	goal := goals[0]
+	from := time.Date(year, time.January, 1, 0, 0, 0, 0, time.UTC)
+	to := from.AddDate(1, 0, 0).Add(-time.Nanosecond)
+
+	// Items whose book is gone don't count, like in the join the gorm
+	// store does
+	for _, item := range gs.finishedItems(profileId, &models.StatsRange{From: &from, To: &to}) {
+		book, ok := gs.books.unscoped(item.BookID)
+		if !ok {
+			continue
+		}
+
+		goal.BooksRead++
+		if book.PageCount != nil {
+			goal.PagesRead += *book.PageCount
+		}
+	}
+
+	return goal, nil
+}
+
+// Creates the goal for the year or replaces its targets if there is one.
+func (gs *GoalStore) Set(profileId uint, year int, books, pages *int) (*models.ReadingGoal, error) {
+	defer gs.lock()()
+
+	goal, err := gs.goalOf(profileId, year)
+	if err != nil {
+		goal = &models.ReadingGoal{ProfileID: profileId, Year: year}
+	}
+
+	goal.Books = books
+	goal.Pages = pages
+	if goal.ID == 0 {
+		gs.goals.insert(goal)
+	} else {
+		gs.goals.save(goal)
+	}
+
+	return gs.goalOf(profileId, year)
+}
+
+// Marks the goal as completed. Reports whether it was this call that did
+// it, so completion is only acted upon once.
+func (gs *GoalStore) Complete(goal *models.ReadingGoal) (bool, error) {
+	defer gs.lock()()
+
+	stored, ok := gs.goals.find(goal.ID)
+	if !ok || stored.CompletedAt != nil {
+		return false, nil
+	}
+
+	now := time.Now()
+	stored.CompletedAt = &now
+	gs.goals.save(stored)
+
+	goal.CompletedAt = &now
+	return true, nil
+}
+
+// Undoes Complete, for when what was read no longer reaches the goal.
+func (gs *GoalStore) Reopen(goal *models.ReadingGoal) error {
+	defer gs.lock()()
+
+	stored, ok := gs.goals.find(goal.ID)
+	if ok {
+		stored.CompletedAt = nil
+		gs.goals.save(stored)
+	}
+
+	goal.CompletedAt = nil
+	return nil
+}
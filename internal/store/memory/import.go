@@ -0,0 +1,113 @@
+package memory
+
+import (
+	"cmp"
+	"slices"
+	"time"
+
+	"github.com/marcos-brito/booklist/internal/models"
+	"gorm.io/gorm"
+)
+
+type ImportStore struct {
+	*DB
+}
+
+func NewImportStore(db *DB) *ImportStore {
+	return &ImportStore{db}
+}
+
+func (is *ImportStore) FindById(id uint) (*models.ImportJob, error) {
+	defer is.lock()()
+
+	job, ok := is.imports.find(id)
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	job.Errors = is.importErrors.where(func(importError *models.ImportError) bool {
+		return importError.ImportJobID == id
+	})
+
+	slices.SortStableFunc(job.Errors, func(a, b *models.ImportError) int {
+		return cmp.Compare(a.Line, b.Line)
+	})
+
+	return job, nil
+}
+
+func (is *ImportStore) Create(profileId uint, format models.ImportFormat, total int) (*models.ImportJob, error) {
+	defer is.lock()()
+
+	job := &models.ImportJob{
+		ProfileID: profileId,
+		Format:    format,
+		Status:    models.ImportStatusPending,
+		Total:     total,
+	}
+
+	is.imports.insert(job)
+	job.Errors = []*models.ImportError{}
+
+	return job, nil
+}
+
+// Saves how far the job has got. Errors are saved as they happen, so
+// they are left alone here.
+func (is *ImportStore) SaveProgress(job *models.ImportJob) error {
+	defer is.lock()()
+
+	is.updateJob(job, func(stored *models.ImportJob) {
+		stored.Status = job.Status
+		stored.Processed = job.Processed
+		stored.Imported = job.Imported
+	})
+
+	return nil
+}
+
+func (is *ImportStore) AddError(job *models.ImportJob, line int, title, message string) error {
+	defer is.lock()()
+
+	importError := &models.ImportError{
+		ImportJobID: job.ID,
+		Line:        line,
+		Title:       title,
+		Message:     message,
+	}
+
+	is.importErrors.insert(importError)
+	job.Errors = append(job.Errors, importError)
+
+	return nil
+}
+
+func (is *ImportStore) Finish(job *models.ImportJob, status models.ImportStatus) error {
+	defer is.lock()()
+
+	now := time.Now()
+	job.Status = status
+	job.FinishedAt = &now
+
+	is.updateJob(job, func(stored *models.ImportJob) {
+		stored.Status = job.Status
+		stored.Processed = job.Processed
+		stored.Imported = job.Imported
+		stored.FinishedAt = job.FinishedAt
+	})
+
+	return nil
+}
+
+// Applies the update to the stored job and saves it, like gorm's Updates
+// on a few columns. Jobs that don't exist are left alone.
+func (is *ImportStore) updateJob(job *models.ImportJob, update func(*models.ImportJob)) {
+	stored, ok := is.imports.find(job.ID)
+	if !ok {
+		return
+	}
+
+	update(stored)
+	is.imports.save(stored)
+	job.UpdatedAt = stored.UpdatedAt
+}
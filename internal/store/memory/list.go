@@ -0,0 +1,311 @@
+package memory
+
+import (
+	"slices"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/marcos-brito/booklist/internal/models"
+	"gorm.io/gorm"
+)
+
+type ListStore struct {
+	*DB
+}
+
+func NewListStore(db *DB) *ListStore {
+	return &ListStore{db}
+}
+
+func (ls *ListStore) IsOwner(id uint, userUuid uuid.UUID) (bool, error) {
+	defer ls.lock()()
+
+	list, ok := ls.lists.find(id)
+	if !ok {
+		return false, gorm.ErrRecordNotFound
+	}
+
+	return list.ProfileID == ls.profile(userUuid).ID, nil
+}
+
+func (ls *ListStore) FindById(id uint) (*models.List, error) {
+	defer ls.lock()()
+
+	list, ok := ls.lists.find(id)
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return list, nil
+}
+
+// Returns the books in each of the lists, keyed by list, whether they are
+// visible to anyone or not.
+func (ls *ListStore) FindBooksByLists(ids []uint) (map[uint][]*models.Book, error) {
+	defer ls.lock()()
+
+	books := map[uint][]*models.Book{}
+	for _, id := range ids {
+		found := ls.listBooksOf(id)
+		if len(found) > 0 {
+			books[id] = found
+		}
+	}
+
+	return books, nil
+}
+
+// Returns the books in the list ordered by id.
+func (db *DB) listBooksOf(id uint) []*models.Book {
+	ids := rights(db.listBooks, id)
+	return db.books.where(func(book *models.Book) bool {
+		return slices.Contains(ids, book.ID)
+	})
+}
+
+// Returns a page of the books in the list that are visible to the
+// viewer, in the order of their ids.
+func (ls *ListStore) FindBooksPage(id uint, viewer *models.Profile, page *models.Page) ([]*models.Book, bool, error) {
+	defer ls.lock()()
+
+	order := byId(func(book *models.Book) uint { return book.ID })
+	books, more := findPage(ls.visibleBooks(id, viewer), order, page, ls.books.unscoped)
+	return books, more, nil
+}
+
+func (ls *ListStore) CountBooks(id uint, viewer *models.Profile) (int64, error) {
+	defer ls.lock()()
+
+	return int64(len(ls.visibleBooks(id, viewer))), nil
+}
+
+func (ls *ListStore) visibleBooks(id uint, viewer *models.Profile) []*models.Book {
+	return slices.DeleteFunc(ls.listBooksOf(id), func(book *models.Book) bool {
+		return !book.VisibleTo(viewer)
+	})
+}
+
+// Returns the list of the user with exactly the given name.
+func (ls *ListStore) FindByName(name string, userUuid uuid.UUID) (*models.List, error) {
+	defer ls.lock()()
+
+	profile := ls.profile(userUuid)
+	lists := ls.lists.where(func(list *models.List) bool {
+		return list.ProfileID == profile.ID && list.Name == name
+	})
+
+	if len(lists) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return lists[0], nil
+}
+
+func (ls *ListStore) Create(name string, desc *string, publish bool, userUuid uuid.UUID) (*models.List, error) {
+	defer ls.lock()()
+
+	list := &models.List{
+		Name:        name,
+		Description: desc,
+		ProfileID:   ls.profile(userUuid).ID,
+		Published:   publish,
+	}
+
+	ls.lists.insert(list)
+	return list, nil
+}
+
+// Returns an empty list, like the gorm store does.
+func (ls *ListStore) Delete(id uint) (*models.List, error) {
+	defer ls.lock()()
+
+	ls.lists.delete(id)
+	return &models.List{}, nil
+}
+
+// Copies the list along with its books. Like the gorm store, a list that
+// doesn't exist is copied as an empty one.
+func (ls *ListStore) Clone(id uint, userUuid uuid.UUID) (*models.List, error) {
+	defer ls.lock()()
+
+	original, ok := ls.lists.find(id)
+	if !ok {
+		original = &models.List{}
+	}
+
+	books := ls.listBooksOf(original.ID)
+	list := &models.List{
+		ProfileID:   ls.profile(userUuid).ID,
+		Name:        original.Name,
+		Description: original.Description,
+		Published:   false,
+	}
+
+	ls.lists.insert(list)
+	for _, book := range books {
+		ls.listBooks = append(ls.listBooks, pair{list.ID, book.ID})
+	}
+
+	list.Books = []models.Book{}
+	for _, book := range books {
+		list.Books = append(list.Books, *book)
+	}
+
+	return list, nil
+}
+
+func (ls *ListStore) Publish(id uint) (*models.List, error) {
+	return ls.setPublished(id, true)
+}
+
+func (ls *ListStore) Unpublish(id uint) (*models.List, error) {
+	return ls.setPublished(id, false)
+}
+
+func (ls *ListStore) setPublished(id uint, published bool) (*models.List, error) {
+	defer ls.lock()()
+
+	list, ok := ls.lists.find(id)
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	list.Published = published
+	ls.lists.save(list)
+
+	return list, nil
+}
+
+// Following a list twice does nothing.
+func (ls *ListStore) Follow(id uint, userUuid uuid.UUID) (*models.List, error) {
+	defer ls.lock()()
+
+	profile := ls.profile(userUuid)
+	list, ok := ls.lists.find(id)
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	if ls.followOf(id, profile.ID) < 0 {
+		ls.follows = append(ls.follows, &models.ListFollow{
+			ProfileID: profile.ID,
+			ListID:    id,
+			CreatedAt: time.Now(),
+		})
+	}
+
+	return list, nil
+}
+
+func (ls *ListStore) Unfollow(id uint, userUuid uuid.UUID) (*models.List, error) {
+	defer ls.lock()()
+
+	profile := ls.profile(userUuid)
+	list, ok := ls.lists.find(id)
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	if i := ls.followOf(id, profile.ID); i >= 0 {
+		ls.follows = slices.Delete(ls.follows, i, i+1)
+	}
+
+	return list, nil
+}
+
+// Returns the index of the follow of the list by the profile, or -1 if
+// they don't follow it.
+func (db *DB) followOf(listId, profileId uint) int {
+	return slices.IndexFunc(db.follows, func(follow *models.ListFollow) bool {
+		return follow.ListID == listId && follow.ProfileID == profileId
+	})
+}
+
+func (ls *ListStore) IsFollower(id uint, userUuid uuid.UUID) (bool, error) {
+	defer ls.lock()()
+
+	return ls.followOf(id, ls.profile(userUuid).ID) >= 0, nil
+}
+
+// Returns the profiles following the list with their settings loaded.
+func (ls *ListStore) FindFollowers(id uint) ([]*models.Profile, error) {
+	defer ls.lock()()
+
+	follows := slices.Clone(ls.follows)
+	slices.SortStableFunc(follows, func(a, b *models.ListFollow) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+
+	profiles := []*models.Profile{}
+	for _, follow := range follows {
+		if follow.ListID != id {
+			continue
+		}
+
+		profile, ok := ls.profiles.find(follow.ProfileID)
+		if !ok {
+			continue
+		}
+
+		if settings, ok := ls.settingsOf(profile.ID); ok {
+			profile.Settings = *settings
+		}
+
+		profiles = append(profiles, profile)
+	}
+
+	return profiles, nil
+}
+
+// Private profiles are left out, as they are from the followers field.
+func (ls *ListStore) CountFollowers(id uint) (int64, error) {
+	defer ls.lock()()
+
+	var count int64
+	for _, follow := range ls.follows {
+		if follow.ListID != id {
+			continue
+		}
+
+		if settings, ok := ls.settingsOf(follow.ProfileID); ok && !settings.Private {
+			count++
+		}
+	}
+
+	return count, nil
+}
+
+// Adding a book twice does nothing.
+func (ls *ListStore) AddBook(listId, bookId uint) (*models.List, error) {
+	defer ls.lock()()
+
+	if _, ok := ls.books.find(bookId); !ok {
+		return nil, gorm.ErrForeignKeyViolated
+	}
+
+	list, ok := ls.lists.find(listId)
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	if !slices.Contains(ls.listBooks, pair{listId, bookId}) {
+		ls.listBooks = append(ls.listBooks, pair{listId, bookId})
+	}
+
+	return list, nil
+}
+
+func (ls *ListStore) RemoveBook(listId, bookId uint) (*models.List, error) {
+	defer ls.lock()()
+
+	ls.listBooks = slices.DeleteFunc(ls.listBooks, func(p pair) bool {
+		return p == pair{listId, bookId}
+	})
+
+	list, ok := ls.lists.find(listId)
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return list, nil
+}
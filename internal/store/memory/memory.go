@@ -0,0 +1,290 @@
+// Package memory has stores that keep everything in memory and behave
+// like the gorm ones in package store, so code using the repositories can
+// run without a database. Like the gorm stores, they return
+// gorm.ErrRecordNotFound for rows that don't exist.
+package memory
+
+import (
+	"cmp"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/marcos-brito/booklist/internal/models"
+	"github.com/marcos-brito/booklist/internal/store"
+	"gorm.io/gorm"
+)
+
+// The rows of a single table, keyed by id. Rows are kept without their
+// associations, which live in the join tables of DB like they do in the
+// database. Deleted rows are kept apart, like soft deleted ones are.
+type table[T any] struct {
+	rows    map[uint]*T
+	deleted map[uint]*T
+	last    uint
+	// Points to the id and timestamps of a row. Updated is nil for rows
+	// that don't keep it.
+	fields func(*T) (id *uint, created, updated *time.Time)
+}
+
+func newTable[T any](fields func(*T) (*uint, *time.Time, *time.Time)) *table[T] {
+	return &table[T]{rows: map[uint]*T{}, deleted: map[uint]*T{}, fields: fields}
+}
+
+func modelFields(model *gorm.Model) (*uint, *time.Time, *time.Time) {
+	return &model.ID, &model.CreatedAt, &model.UpdatedAt
+}
+
+// Stores a copy of the row with the next id, setting its timestamps like
+// gorm does. They are set on the given row too.
+func (t *table[T]) insert(row *T) {
+	t.last++
+	id, _, _ := t.fields(row)
+	*id = t.last
+	t.save(row)
+}
+
+// Replaces the stored row with a copy of the given one, setting its
+// timestamps like gorm's Save does.
+func (t *table[T]) save(row *T) {
+	now := time.Now()
+	_, created, updated := t.fields(row)
+	if created.IsZero() {
+		*created = now
+	}
+
+	if updated != nil {
+		*updated = now
+	}
+
+	t.put(row)
+}
+
+// Same as save, leaving the timestamps as they are, like gorm's
+// UpdateColumns.
+func (t *table[T]) put(row *T) {
+	id, _, _ := t.fields(row)
+	stored := *row
+	t.rows[*id] = &stored
+}
+
+// Returns a copy of the row, so callers can't change it without saving
+// it.
+func (t *table[T]) find(id uint) (*T, bool) {
+	row, ok := t.rows[id]
+	if !ok {
+		return nil, false
+	}
+
+	found := *row
+	return &found, true
+}
+
+// Same as find, including deleted rows.
+func (t *table[T]) unscoped(id uint) (*T, bool) {
+	row, ok := t.find(id)
+	if ok {
+		return row, true
+	}
+
+	row, ok = t.deleted[id]
+	if !ok {
+		return nil, false
+	}
+
+	found := *row
+	return &found, true
+}
+
+func (t *table[T]) delete(id uint) {
+	row, ok := t.rows[id]
+	if !ok {
+		return
+	}
+
+	delete(t.rows, id)
+	t.deleted[id] = row
+}
+
+// Deletes the rows that match for good, deleted ones included, and
+// returns their ids.
+func (t *table[T]) purge(match func(*T) bool) []uint {
+	ids := []uint{}
+	for _, rows := range []map[uint]*T{t.rows, t.deleted} {
+		for id, row := range rows {
+			if match(row) {
+				delete(rows, id)
+				ids = append(ids, id)
+			}
+		}
+	}
+
+	return ids
+}
+
+// Returns copies of the rows that match, ordered by id.
+func (t *table[T]) where(match func(*T) bool) []*T {
+	rows := []*T{}
+	for _, row := range t.rows {
+		if match(row) {
+			found := *row
+			rows = append(rows, &found)
+		}
+	}
+
+	slices.SortFunc(rows, func(a, b *T) int {
+		left, _, _ := t.fields(a)
+		right, _, _ := t.fields(b)
+		return cmp.Compare(*left, *right)
+	})
+
+	return rows
+}
+
+func (t *table[T]) all() []*T {
+	return t.where(func(*T) bool { return true })
+}
+
+// A row of a many to many join table.
+type pair struct {
+	left, right uint
+}
+
+// Everything the stores keep, shared by all of them like a database.
+// Every store method holds the lock for as long as it runs, so methods
+// only call each other through the unexported helpers, which expect it
+// to be held.
+type DB struct {
+	mu           sync.Mutex
+	books        *table[models.Book]
+	authors      *table[models.Author]
+	publishers   *table[models.Publisher]
+	profiles     *table[models.Profile]
+	settings     *table[models.Settings]
+	lists        *table[models.List]
+	items        *table[models.CollectionItem]
+	events       *table[models.ReadingEvent]
+	sessions     *table[models.ReadingSession]
+	goals        *table[models.ReadingGoal]
+	imports      *table[models.ImportJob]
+	importErrors *table[models.ImportError]
+	exports      *table[models.DataExport]
+	// Book and author
+	bookAuthors []pair
+	// List and book
+	listBooks []pair
+	follows   []*models.ListFollow
+}
+
+func NewDB() *DB {
+	return &DB{
+		books: newTable(func(book *models.Book) (*uint, *time.Time, *time.Time) {
+			return modelFields(&book.Model)
+		}),
+		authors: newTable(func(author *models.Author) (*uint, *time.Time, *time.Time) {
+			return modelFields(&author.Model)
+		}),
+		publishers: newTable(func(publisher *models.Publisher) (*uint, *time.Time, *time.Time) {
+			return modelFields(&publisher.Model)
+		}),
+		profiles: newTable(func(profile *models.Profile) (*uint, *time.Time, *time.Time) {
+			return modelFields(&profile.Model)
+		}),
+		settings: newTable(func(settings *models.Settings) (*uint, *time.Time, *time.Time) {
+			return modelFields(&settings.Model)
+		}),
+		lists: newTable(func(list *models.List) (*uint, *time.Time, *time.Time) {
+			return modelFields(&list.Model)
+		}),
+		items: newTable(func(item *models.CollectionItem) (*uint, *time.Time, *time.Time) {
+			return modelFields(&item.Model)
+		}),
+		events: newTable(func(event *models.ReadingEvent) (*uint, *time.Time, *time.Time) {
+			return &event.ID, &event.CreatedAt, nil
+		}),
+		sessions: newTable(func(session *models.ReadingSession) (*uint, *time.Time, *time.Time) {
+			return &session.ID, &session.CreatedAt, nil
+		}),
+		goals: newTable(func(goal *models.ReadingGoal) (*uint, *time.Time, *time.Time) {
+			return modelFields(&goal.Model)
+		}),
+		imports: newTable(func(job *models.ImportJob) (*uint, *time.Time, *time.Time) {
+			return modelFields(&job.Model)
+		}),
+		// Import errors have no timestamps, so the one set goes nowhere
+		importErrors: newTable(func(importError *models.ImportError) (*uint, *time.Time, *time.Time) {
+			return &importError.ID, &time.Time{}, nil
+		}),
+		exports: newTable(func(export *models.DataExport) (*uint, *time.Time, *time.Time) {
+			return modelFields(&export.Model)
+		}),
+	}
+}
+
+var (
+	_ store.BookRepository      = (*BookStore)(nil)
+	_ store.AuthorRepository    = (*AuthorStore)(nil)
+	_ store.PublisherRepository = (*PublisherStore)(nil)
+	_ store.ListRepository      = (*ListStore)(nil)
+	_ store.UserRepository      = (*UserStore)(nil)
+	_ store.GoalRepository      = (*GoalStore)(nil)
+	_ store.ImportRepository    = (*ImportStore)(nil)
+	_ store.ExportRepository    = (*ExportStore)(nil)
+)
+
+// Returns the memory stores over the database.
+func NewRepositories(db *DB) *store.Repositories {
+	return &store.Repositories{
+		Books:      NewBookStore(db),
+		Authors:    NewAuthorStore(db),
+		Publishers: NewPublisherStore(db),
+		Lists:      NewListStore(db),
+		Users:      NewUserStore(db),
+		Goals:      NewGoalStore(db),
+		Imports:    NewImportStore(db),
+		Exports:    NewExportStore(db),
+	}
+}
+
+func (db *DB) lock() func() {
+	db.mu.Lock()
+	return db.mu.Unlock
+}
+
+// Returns the right side of the pairs with the given left side, in the
+// order they were added.
+func rights(pairs []pair, left uint) []uint {
+	ids := []uint{}
+	for _, p := range pairs {
+		if p.left == left {
+			ids = append(ids, p.right)
+		}
+	}
+
+	return ids
+}
+
+func lefts(pairs []pair, right uint) []uint {
+	ids := []uint{}
+	for _, p := range pairs {
+		if p.right == right {
+			ids = append(ids, p.left)
+		}
+	}
+
+	return ids
+}
+
+// Takes the rows gorm would with the given limit and offset. A negative
+// limit means no limit.
+func window[T any](rows []*T, limit, offset int) []*T {
+	if offset > 0 {
+		rows = rows[min(offset, len(rows)):]
+	}
+
+	if limit >= 0 && limit < len(rows) {
+		rows = rows[:limit]
+	}
+
+	return rows
+}
@@ -0,0 +1,12 @@
+package memory_test
+
+import (
+	"testing"
+
+	"github.com/marcos-brito/booklist/internal/store/memory"
+	"github.com/marcos-brito/booklist/internal/store/storetest"
+)
+
+func TestConformance(t *testing.T) {
+	storetest.Run(t, memory.NewRepositories(memory.NewDB()))
+}
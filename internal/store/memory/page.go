@@ -0,0 +1,117 @@
+package memory
+
+import (
+	"cmp"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/marcos-brito/booklist/internal/models"
+)
+
+// Same as the ordering in package store. Value returns what a row is
+// sorted by, a string, time or float64, or nil for null. Rows are sorted
+// by id alone when there is no value.
+type ordering[T any] struct {
+	id    func(*T) uint
+	value func(*T) any
+	desc  bool
+}
+
+// Where a row falls in an ordering.
+type key struct {
+	value any
+	id    uint
+}
+
+func byId[T any](id func(*T) uint) *ordering[T] {
+	return &ordering[T]{id: id}
+}
+
+func (o *ordering[T]) key(row *T) key {
+	if o.value == nil {
+		return key{id: o.id(row)}
+	}
+
+	return key{o.value(row), o.id(row)}
+}
+
+func (o *ordering[T]) compare(a, b key) int {
+	switch {
+	case a.value == nil && b.value != nil:
+		return 1
+	case a.value != nil && b.value == nil:
+		return -1
+	case a.value != nil:
+		if c := o.direct(compareValues(a.value, b.value)); c != 0 {
+			return c
+		}
+	}
+
+	return o.direct(cmp.Compare(a.id, b.id))
+}
+
+func (o *ordering[T]) direct(c int) int {
+	if o.desc {
+		return -c
+	}
+
+	return c
+}
+
+func compareValues(a, b any) int {
+	switch a := a.(type) {
+	case string:
+		return strings.Compare(a, b.(string))
+	case time.Time:
+		return a.Compare(b.(time.Time))
+	case float64:
+		return cmp.Compare(a, b.(float64))
+	}
+
+	return 0
+}
+
+// Same as findPage in package store, over every row of the query. The
+// rows at the cursors are looked up with find, even if they were
+// deleted. A cursor whose row is gone for good sorts as if its value was
+// null, like it does in the database.
+func findPage[T any](rows []*T, order *ordering[T], page *models.Page, find func(uint) (*T, bool)) ([]*T, bool) {
+	cursor := func(id uint) key {
+		row, ok := find(id)
+		if !ok {
+			return key{id: id}
+		}
+
+		return order.key(row)
+	}
+
+	slices.SortStableFunc(rows, func(a, b *T) int {
+		return order.compare(order.key(a), order.key(b))
+	})
+
+	if page.After != nil {
+		after := cursor(*page.After)
+		rows = slices.DeleteFunc(rows, func(row *T) bool {
+			return order.compare(order.key(row), after) <= 0
+		})
+	}
+
+	if page.Before != nil {
+		before := cursor(*page.Before)
+		rows = slices.DeleteFunc(rows, func(row *T) bool {
+			return order.compare(order.key(row), before) >= 0
+		})
+	}
+
+	more := len(rows) > page.Limit
+	if !more {
+		return rows, false
+	}
+
+	if page.Backward {
+		return rows[len(rows)-page.Limit:], true
+	}
+
+	return rows[:page.Limit], true
+}
@@ -0,0 +1,114 @@
+package memory
+
+import (
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/marcos-brito/booklist/internal/models"
+	"gorm.io/gorm"
+)
+
+type PublisherStore struct {
+	*DB
+}
+
+func NewPublisherStore(db *DB) *PublisherStore {
+	return &PublisherStore{db}
+}
+
+func (ps *PublisherStore) FindById(id uint) (*models.Publisher, error) {
+	defer ps.lock()()
+
+	publisher, ok := ps.publishers.find(id)
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return publisher, nil
+}
+
+func (ps *PublisherStore) FindByIds(ids []uint) ([]*models.Publisher, error) {
+	defer ps.lock()()
+
+	return ps.publishers.where(func(publisher *models.Publisher) bool {
+		return slices.Contains(ids, publisher.ID)
+	}), nil
+}
+
+func (ps *PublisherStore) Create(input *models.CreatePublisher, userUuid uuid.UUID) (*models.Publisher, error) {
+	defer ps.lock()()
+
+	profile := ps.profile(userUuid)
+	publisher := models.NewPublisherFromInput(input, profile.ID)
+	ps.publishers.insert(publisher)
+
+	return publisher, nil
+}
+
+func (ps *PublisherStore) Update(id uint, changes *models.UpdatePublisher) (*models.Publisher, error) {
+	defer ps.lock()()
+
+	publisher, ok := ps.publishers.find(id)
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	if changes.Name != nil {
+		publisher.Name = *changes.Name
+	}
+
+	ps.publishers.save(publisher)
+	return publisher, nil
+}
+
+// Moves every book of the source publisher to the target and deletes
+// the source.
+func (ps *PublisherStore) Merge(sourceId, targetId uint) (*models.Publisher, error) {
+	defer ps.lock()()
+
+	for _, book := range ps.books.all() {
+		if book.PublisherID != nil && *book.PublisherID == sourceId {
+			book.PublisherID = &targetId
+			ps.books.save(book)
+		}
+	}
+
+	ps.publishers.delete(sourceId)
+
+	publisher, ok := ps.publishers.find(targetId)
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return publisher, nil
+}
+
+func (ps *PublisherStore) Approve(id uint) (*models.Publisher, error) {
+	defer ps.lock()()
+
+	publisher, ok := ps.publishers.find(id)
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	publisher.NeedsApproval = false
+	publisher.RejectionReason = nil
+	ps.publishers.save(publisher)
+
+	return publisher, nil
+}
+
+func (ps *PublisherStore) Reject(id uint, reason string) (*models.Publisher, error) {
+	defer ps.lock()()
+
+	publisher, ok := ps.publishers.find(id)
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	publisher.NeedsApproval = true
+	publisher.RejectionReason = &reason
+	ps.publishers.save(publisher)
+
+	return publisher, nil
+}
@@ -0,0 +1,158 @@
+package memory
+
+import (
+	"cmp"
+	"slices"
+
+	"github.com/google/uuid"
+	"github.com/marcos-brito/booklist/internal/models"
+)
+
+// How many authors and publishers are ranked in the stats.
+const topCount = 5
+
+// Aggregates the collection of a user. Finished books are the ones read
+// within the range, while the status distribution covers the whole
+// collection as it is now. Authors and publishers the viewer can't see
+// are left out of the rankings.
+func (us *UserStore) FindStats(userUuid uuid.UUID, statsRange *models.StatsRange, viewer *models.Profile) (*models.Stats, error) {
+	defer us.lock()()
+
+	profile := us.profile(userUuid)
+	finished := us.finishedItems(profile.ID, statsRange)
+	stats := &models.Stats{
+		FinishedPerMonth: us.finishedPer(finished, "2006-01"),
+		FinishedPerYear:  us.finishedPer(finished, "2006"),
+	}
+
+	days, started := 0.0, 0
+	for _, item := range finished {
+		if item.StartedAt != nil {
+			days += item.FinishedAt.Sub(*item.StartedAt).Hours() / 24
+			started++
+		}
+	}
+
+	if started > 0 {
+		average := days / float64(started)
+		stats.AverageDaysToFinish = &average
+	}
+
+	stats.StatusDistribution = []*models.StatusCount{}
+	for _, item := range us.items.where(func(item *models.CollectionItem) bool { return item.ProfileID == profile.ID }) {
+		i := slices.IndexFunc(stats.StatusDistribution, func(count *models.StatusCount) bool {
+			return count.Status == item.Status
+		})
+
+		if i < 0 {
+			stats.StatusDistribution = append(stats.StatusDistribution, &models.StatusCount{Status: item.Status})
+			i = len(stats.StatusDistribution) - 1
+		}
+
+		stats.StatusDistribution[i].Count++
+	}
+
+	slices.SortFunc(stats.StatusDistribution, func(a, b *models.StatusCount) int {
+		return cmp.Or(cmp.Compare(b.Count, a.Count), cmp.Compare(a.Status, b.Status))
+	})
+
+	books := []uint{}
+	for _, item := range finished {
+		books = append(books, item.BookID)
+	}
+
+	stats.TopAuthors = []*models.AuthorCount{}
+	for _, author := range us.authors.all() {
+		count := 0
+		for _, book := range lefts(us.bookAuthors, author.ID) {
+			if slices.Contains(books, book) {
+				count++
+			}
+		}
+
+		if count > 0 && author.VisibleTo(viewer) {
+			stats.TopAuthors = append(stats.TopAuthors, &models.AuthorCount{Author: author, Count: count})
+		}
+	}
+
+	slices.SortStableFunc(stats.TopAuthors, func(a, b *models.AuthorCount) int {
+		return cmp.Compare(b.Count, a.Count)
+	})
+
+	stats.TopPublishers = []*models.PublisherCount{}
+	for _, publisher := range us.publishers.all() {
+		count := 0
+		for _, book := range books {
+			found, ok := us.books.unscoped(book)
+			if ok && found.PublisherID != nil && *found.PublisherID == publisher.ID {
+				count++
+			}
+		}
+
+		if count > 0 && publisher.VisibleTo(viewer) {
+			stats.TopPublishers = append(stats.TopPublishers, &models.PublisherCount{Publisher: publisher, Count: count})
+		}
+	}
+
+	slices.SortStableFunc(stats.TopPublishers, func(a, b *models.PublisherCount) int {
+		return cmp.Compare(b.Count, a.Count)
+	})
+
+	stats.TopAuthors = window(stats.TopAuthors, topCount, 0)
+	stats.TopPublishers = window(stats.TopPublishers, topCount, 0)
+	return stats, nil
+}
+
+// Items of the profile that were read, only the ones finished within the
+// range if there is one.
+func (db *DB) finishedItems(profileId uint, statsRange *models.StatsRange) []*models.CollectionItem {
+	return db.items.where(func(item *models.CollectionItem) bool {
+		if item.ProfileID != profileId || item.Status != models.StatusRead || item.FinishedAt == nil {
+			return false
+		}
+
+		if statsRange == nil {
+			return true
+		}
+
+		if statsRange.From != nil && item.FinishedAt.Before(*statsRange.From) {
+			return false
+		}
+
+		return statsRange.To == nil || !item.FinishedAt.After(*statsRange.To)
+	})
+}
+
+// Groups the finished items by the periods their dates format to, in
+// order. Items whose book is gone don't count, like in the join the gorm
+// store does.
+func (us *UserStore) finishedPer(finished []*models.CollectionItem, layout string) []*models.PeriodStats {
+	periods := []*models.PeriodStats{}
+	for _, item := range finished {
+		book, ok := us.books.unscoped(item.BookID)
+		if !ok {
+			continue
+		}
+
+		period := item.FinishedAt.UTC().Format(layout)
+		i := slices.IndexFunc(periods, func(stats *models.PeriodStats) bool {
+			return stats.Period == period
+		})
+
+		if i < 0 {
+			periods = append(periods, &models.PeriodStats{Period: period})
+			i = len(periods) - 1
+		}
+
+		periods[i].Books++
+		if book.PageCount != nil {
+			periods[i].Pages += *book.PageCount
+		}
+	}
+
+	slices.SortFunc(periods, func(a, b *models.PeriodStats) int {
+		return cmp.Compare(a.Period, b.Period)
+	})
+
+	return periods
+}
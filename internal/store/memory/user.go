@@ -0,0 +1,550 @@
+package memory
+
+import (
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/marcos-brito/booklist/internal/models"
+	"gorm.io/gorm"
+)
+
+type UserStore struct {
+	*DB
+}
+
+func NewUserStore(db *DB) *UserStore {
+	return &UserStore{db}
+}
+
+// Same as UserStore.FindProfileByUserUuid in package store, which creates
+// the profile of users seen for the first time.
+func (db *DB) profile(userUuid uuid.UUID) *models.Profile {
+	profile, err := db.existingProfile(userUuid)
+	if err == nil {
+		return profile
+	}
+
+	profile = &models.Profile{UUID: userUuid}
+	db.profiles.insert(profile)
+	db.settings.insert(&models.Settings{ProfileID: profile.ID, Private: true})
+
+	return profile
+}
+
+func (db *DB) existingProfile(userUuid uuid.UUID) (*models.Profile, error) {
+	profiles := db.profiles.where(func(profile *models.Profile) bool {
+		return profile.UUID == userUuid
+	})
+
+	if len(profiles) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return profiles[0], nil
+}
+
+func (db *DB) settingsOf(profileId uint) (*models.Settings, bool) {
+	settings := db.settings.where(func(settings *models.Settings) bool {
+		return settings.ProfileID == profileId
+	})
+
+	if len(settings) == 0 {
+		return nil, false
+	}
+
+	return settings[0], true
+}
+
+func (us *UserStore) FindProfileByUserUuid(uuid uuid.UUID) (*models.Profile, error) {
+	defer us.lock()()
+
+	return us.profile(uuid), nil
+}
+
+func (us *UserStore) FindFullProfileById(id uint) (*models.Profile, error) {
+	defer us.lock()()
+
+	profile, ok := us.profiles.find(id)
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	if settings, ok := us.settingsOf(id); ok {
+		profile.Settings = *settings
+	}
+
+	return profile, nil
+}
+
+func (us *UserStore) FindProfilesByIds(ids []uint) ([]*models.Profile, error) {
+	defer us.lock()()
+
+	return us.profiles.where(func(profile *models.Profile) bool {
+		return slices.Contains(ids, profile.ID)
+	}), nil
+}
+
+// Unlike FindProfileByUserUuid, profiles that don't exist yet are left
+// out rather than created.
+func (us *UserStore) FindProfilesByUserUuids(uuids []uuid.UUID) ([]*models.Profile, error) {
+	defer us.lock()()
+
+	return us.profiles.where(func(profile *models.Profile) bool {
+		return slices.Contains(uuids, profile.UUID)
+	}), nil
+}
+
+func (us *UserStore) FindSettingsByProfileIds(ids []uint) ([]*models.Settings, error) {
+	defer us.lock()()
+
+	return us.settings.where(func(settings *models.Settings) bool {
+		return slices.Contains(ids, settings.ProfileID)
+	}), nil
+}
+
+func (us *UserStore) FindItemById(id uint) (*models.CollectionItem, error) {
+	defer us.lock()()
+
+	item, ok := us.items.find(id)
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return item, nil
+}
+
+// Returns the item of the user for the book, if the book is in their
+// collection.
+func (us *UserStore) FindItemByBook(userUuid uuid.UUID, bookId uint) (*models.CollectionItem, error) {
+	defer us.lock()()
+
+	profile := us.profile(userUuid)
+	items := us.items.where(func(item *models.CollectionItem) bool {
+		return item.ProfileID == profile.ID && item.BookID == bookId
+	})
+
+	if len(items) == 0 {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return items[0], nil
+}
+
+func (us *UserStore) FindSettingsByUserUuid(uuid uuid.UUID) (*models.Settings, error) {
+	defer us.lock()()
+
+	return us.settingsByUserUuid(uuid)
+}
+
+func (us *UserStore) settingsByUserUuid(uuid uuid.UUID) (*models.Settings, error) {
+	profile, err := us.existingProfile(uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	settings, ok := us.settingsOf(profile.ID)
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	return settings, nil
+}
+
+// Returns the items in the user's collection that match the filter, in
+// the given order. Both can be nil.
+func (us *UserStore) FindItems(userUuid uuid.UUID, filter *models.CollectionFilter, sort *models.CollectionSort) ([]*models.CollectionItem, error) {
+	defer us.lock()()
+
+	items := us.filteredItems(us.profile(userUuid).ID, filter)
+	us.sortItems(items, sort)
+
+	return items, nil
+}
+
+// Same as FindItems, leaving out books the viewer can't see.
+func (us *UserStore) FindPublicItems(userUuid uuid.UUID, viewer *models.Profile, filter *models.CollectionFilter, sort *models.CollectionSort) ([]*models.CollectionItem, error) {
+	defer us.lock()()
+
+	items := us.publicItems(us.filteredItems(us.profile(userUuid).ID, filter), viewer)
+	us.sortItems(items, sort)
+
+	return items, nil
+}
+
+// Returns a page of the user's collection that matches the filter, in
+// the given order.
+func (us *UserStore) FindItemsPage(userUuid uuid.UUID, filter *models.CollectionFilter, sort *models.CollectionSort, page *models.Page) ([]*models.CollectionItem, bool, error) {
+	defer us.lock()()
+
+	items := us.filteredItems(us.profile(userUuid).ID, filter)
+	found, more := findPage(items, us.itemOrdering(sort), page, us.items.unscoped)
+	return found, more, nil
+}
+
+func (us *UserStore) CountItems(userUuid uuid.UUID, filter *models.CollectionFilter) (int64, error) {
+	defer us.lock()()
+
+	return int64(len(us.filteredItems(us.profile(userUuid).ID, filter))), nil
+}
+
+// Same as FindItemsPage, leaving out books the viewer can't see.
+func (us *UserStore) FindPublicItemsPage(userUuid uuid.UUID, viewer *models.Profile, filter *models.CollectionFilter, sort *models.CollectionSort, page *models.Page) ([]*models.CollectionItem, bool, error) {
+	defer us.lock()()
+
+	items := us.publicItems(us.filteredItems(us.profile(userUuid).ID, filter), viewer)
+	found, more := findPage(items, us.itemOrdering(sort), page, us.items.unscoped)
+	return found, more, nil
+}
+
+func (us *UserStore) CountPublicItems(userUuid uuid.UUID, viewer *models.Profile, filter *models.CollectionFilter) (int64, error) {
+	defer us.lock()()
+
+	return int64(len(us.publicItems(us.filteredItems(us.profile(userUuid).ID, filter), viewer))), nil
+}
+
+func (us *UserStore) publicItems(items []*models.CollectionItem, viewer *models.Profile) []*models.CollectionItem {
+	return slices.DeleteFunc(items, func(item *models.CollectionItem) bool {
+		book, ok := us.books.find(item.BookID)
+		return !ok || !book.VisibleTo(viewer)
+	})
+}
+
+func (us *UserStore) filteredItems(profileId uint, filter *models.CollectionFilter) []*models.CollectionItem {
+	return us.items.where(func(item *models.CollectionItem) bool {
+		if item.ProfileID != profileId {
+			return false
+		}
+
+		if filter == nil {
+			return true
+		}
+
+		if len(filter.Statuses) > 0 && !slices.Contains(filter.Statuses, item.Status) {
+			return false
+		}
+
+		if filter.Author != nil && !slices.Contains(rights(us.bookAuthors, item.BookID), *filter.Author) {
+			return false
+		}
+
+		if filter.Publisher != nil {
+			book, ok := us.books.find(item.BookID)
+			if !ok || book.PublisherID == nil || *book.PublisherID != *filter.Publisher {
+				return false
+			}
+		}
+
+		if filter.AddedFrom != nil && item.CreatedAt.Before(*filter.AddedFrom) {
+			return false
+		}
+
+		if filter.AddedTo != nil && item.CreatedAt.After(*filter.AddedTo) {
+			return false
+		}
+
+		if filter.FinishedFrom != nil && (item.FinishedAt == nil || item.FinishedAt.Before(*filter.FinishedFrom)) {
+			return false
+		}
+
+		if filter.FinishedTo != nil && (item.FinishedAt == nil || item.FinishedAt.After(*filter.FinishedTo)) {
+			return false
+		}
+
+		if filter.MinRating != nil && (item.Rating == nil || *item.Rating < *filter.MinRating) {
+			return false
+		}
+
+		return filter.MaxRating == nil || (item.Rating != nil && *item.Rating <= *filter.MaxRating)
+	})
+}
+
+func (us *UserStore) sortItems(items []*models.CollectionItem, sort *models.CollectionSort) {
+	order := us.itemOrdering(sort)
+	slices.SortStableFunc(items, func(a, b *models.CollectionItem) int {
+		return order.compare(order.key(a), order.key(b))
+	})
+}
+
+// Same as itemOrdering in package store.
+func (us *UserStore) itemOrdering(sort *models.CollectionSort) *ordering[models.CollectionItem] {
+	order := byId(func(item *models.CollectionItem) uint { return item.ID })
+	if sort == nil {
+		return order
+	}
+
+	switch sort.Field {
+	case models.CollectionSortFieldTitle:
+		order.value = func(item *models.CollectionItem) any {
+			book, ok := us.books.unscoped(item.BookID)
+			if !ok {
+				return nil
+			}
+
+			return strings.ToLower(book.Title)
+		}
+	case models.CollectionSortFieldDateAdded:
+		order.value = func(item *models.CollectionItem) any {
+			return item.CreatedAt
+		}
+	case models.CollectionSortFieldDateFinished:
+		order.value = func(item *models.CollectionItem) any {
+			if item.FinishedAt == nil {
+				return nil
+			}
+
+			return *item.FinishedAt
+		}
+	case models.CollectionSortFieldRating:
+		order.value = func(item *models.CollectionItem) any {
+			if item.Rating == nil {
+				return nil
+			}
+
+			return *item.Rating
+		}
+	}
+
+	order.desc = sort.Direction != nil && *sort.Direction == models.SortDirectionDesc
+	return order
+}
+
+func (us *UserStore) FindLists(userUuid uuid.UUID) ([]*models.List, error) {
+	defer us.lock()()
+
+	return us.userLists(us.profile(userUuid).ID, true), nil
+}
+
+func (us *UserStore) FindPublicLists(userUuid uuid.UUID) ([]*models.List, error) {
+	defer us.lock()()
+
+	return us.userLists(us.profile(userUuid).ID, false), nil
+}
+
+// Returns a page of the user's lists, in the order they were created.
+// Only published lists are included unless all is set.
+func (us *UserStore) FindListsPage(userUuid uuid.UUID, all bool, page *models.Page) ([]*models.List, bool, error) {
+	defer us.lock()()
+
+	lists := us.userLists(us.profile(userUuid).ID, all)
+	order := byId(func(list *models.List) uint { return list.ID })
+	found, more := findPage(lists, order, page, us.lists.unscoped)
+	return found, more, nil
+}
+
+func (us *UserStore) CountLists(userUuid uuid.UUID, all bool) (int64, error) {
+	defer us.lock()()
+
+	return int64(len(us.userLists(us.profile(userUuid).ID, all))), nil
+}
+
+func (us *UserStore) userLists(profileId uint, all bool) []*models.List {
+	return us.lists.where(func(list *models.List) bool {
+		return list.ProfileID == profileId && (all || list.Published)
+	})
+}
+
+// Returns the published lists followed by the user. Lists that were
+// unpublished after being followed are left out.
+func (us *UserStore) FindFollowedLists(userUuid uuid.UUID) ([]*models.List, error) {
+	defer us.lock()()
+
+	profile := us.profile(userUuid)
+	return us.lists.where(func(list *models.List) bool {
+		return list.Published && us.followOf(list.ID, profile.ID) >= 0
+	}), nil
+}
+
+// Fails like the database would for a book that doesn't exist.
+func (us *UserStore) AddToCollection(userUuid uuid.UUID, bookID uint, status models.Status, dates models.ReadingDates) (*models.CollectionItem, error) {
+	defer us.lock()()
+
+	profile, err := us.existingProfile(userUuid)
+	if err != nil {
+		return nil, err
+	}
+
+	if _, ok := us.books.unscoped(bookID); !ok {
+		return nil, gorm.ErrForeignKeyViolated
+	}
+
+	item := models.NewCollectionItem(profile.ID, bookID, status, dates, time.Now())
+	us.items.insert(item)
+	us.events.insert(item.Event())
+
+	return item, nil
+}
+
+// Returns every status change of the item, oldest first.
+func (us *UserStore) FindItemHistory(id uint) ([]*models.ReadingEvent, error) {
+	defer us.lock()()
+
+	events := us.events.where(func(event *models.ReadingEvent) bool {
+		return event.CollectionItemID == id
+	})
+
+	slices.SortStableFunc(events, func(a, b *models.ReadingEvent) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+
+	return events, nil
+}
+
+// Returns every progress update of the item, oldest first.
+func (us *UserStore) FindItemSessions(id uint) ([]*models.ReadingSession, error) {
+	defer us.lock()()
+
+	sessions := us.sessions.where(func(session *models.ReadingSession) bool {
+		return session.CollectionItemID == id
+	})
+
+	slices.SortStableFunc(sessions, func(a, b *models.ReadingSession) int {
+		return a.CreatedAt.Compare(b.CreatedAt)
+	})
+
+	return sessions, nil
+}
+
+func (us *UserStore) DeleteFromCollection(id uint) (*models.CollectionItem, error) {
+	defer us.lock()()
+
+	item, ok := us.items.find(id)
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	us.items.delete(id)
+	us.updateBookRating(item.BookID, item.Rating, nil)
+
+	return item, nil
+}
+
+func (us *UserStore) SetModerator(uuid uuid.UUID, moderator bool) (*models.Profile, error) {
+	defer us.lock()()
+
+	profile := us.profile(uuid)
+	profile.Moderator = moderator
+	us.profiles.save(profile)
+
+	return profile, nil
+}
+
+func (us *UserStore) UpdateSettings(uuid uuid.UUID, changes models.UpdateSettings) (*models.Settings, error) {
+	defer us.lock()()
+
+	settings, err := us.settingsByUserUuid(uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	settings.Private = changes.Private
+	settings.ShowName = changes.ShowName
+	settings.ShowStats = changes.ShowStats
+	settings.ShowCollection = changes.ShowCollection
+	settings.ShowListsFollows = changes.ShowListsFollows
+	settings.ShowAuthorsFollows = changes.ShowAuthorsFollows
+	us.settings.save(settings)
+
+	return settings, nil
+}
+
+func (us *UserStore) ChangeItemStatus(id uint, status models.Status, dates models.ReadingDates) (*models.CollectionItem, error) {
+	defer us.lock()()
+
+	item, ok := us.items.find(id)
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	item.Transition(status, dates, time.Now())
+	us.items.save(item)
+	us.events.insert(item.Event())
+
+	return item, nil
+}
+
+// Records a reading session and moves the item forward. If that changes
+// its status, the change goes to the history like any other.
+func (us *UserStore) UpdateProgress(id uint, update models.ProgressUpdate) (*models.CollectionItem, error) {
+	defer us.lock()()
+
+	item, ok := us.items.find(id)
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	var pageCount *int
+	if book, ok := us.books.find(item.BookID); ok {
+		pageCount = book.PageCount
+	}
+
+	status := item.Status
+	session := item.Advance(update, pageCount, time.Now())
+	us.items.save(item)
+	us.sessions.insert(session)
+	if item.Status != status {
+		us.events.insert(item.Event())
+	}
+
+	return item, nil
+}
+
+// Rates the item, or removes its rating if there is none, and updates
+// the rating aggregates of the book.
+func (us *UserStore) RateItem(id uint, rating *float64) (*models.CollectionItem, error) {
+	defer us.lock()()
+
+	item, ok := us.items.find(id)
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	previous := item.Rating
+	item.Rating = rating
+	us.items.save(item)
+	us.updateBookRating(item.BookID, previous, rating)
+
+	return item, nil
+}
+
+// Reviews the item, or removes its review if there is none.
+func (us *UserStore) ReviewItem(id uint, review *string, spoiler bool) (*models.CollectionItem, error) {
+	defer us.lock()()
+
+	item, ok := us.items.find(id)
+	if !ok {
+		return nil, gorm.ErrRecordNotFound
+	}
+
+	item.Review = review
+	item.Spoiler = spoiler && review != nil
+	item.ReviewedAt = nil
+	if review != nil {
+		now := time.Now()
+		item.ReviewedAt = &now
+	}
+
+	us.items.save(item)
+	return item, nil
+}
+
+// Same as updateBookRating in package store.
+func (db *DB) updateBookRating(bookId uint, from, to *float64) {
+	book, ok := db.books.find(bookId)
+	if !ok {
+		return
+	}
+
+	if from != nil {
+		book.RatingCount--
+		book.RatingTotal -= *from
+	}
+
+	if to != nil {
+		book.RatingCount++
+		book.RatingTotal += *to
+	}
+
+	db.books.put(book)
+}
@@ -0,0 +1,107 @@
+package store
+
+import (
+	"fmt"
+	"slices"
+	"strings"
+
+	"github.com/marcos-brito/booklist/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// How the rows of a query are ordered. Rows are sorted by an expression,
+// if there's one, and the id column breaks ties. Rows where the
+// expression is null come last in either direction. Value selects the
+// expression for the row with a given id, so cursors only need the id
+// to know where a page starts.
+type ordering struct {
+	id    string
+	expr  string
+	value string
+	desc  bool
+}
+
+func byId(column string) *ordering {
+	return &ordering{id: column}
+}
+
+// Condition matching the rows that come after, or before, the row with
+// the given id.
+func (o *ordering) beyond(id uint, after bool) clause.Expr {
+	op := "<"
+	if after != o.desc {
+		op = ">"
+	}
+
+	if o.expr == "" {
+		return clause.Expr{SQL: fmt.Sprintf("%s %s ?", o.id, op), Vars: []any{id}}
+	}
+
+	expr, value := o.expr, "("+o.value+")"
+	var sql string
+	if after {
+		sql = fmt.Sprintf("(%[2]s IS NOT NULL AND (%[1]s %[4]s %[2]s OR (%[1]s = %[2]s AND %[3]s %[4]s ?) OR %[1]s IS NULL))"+
+			" OR (%[2]s IS NULL AND %[1]s IS NULL AND %[3]s %[4]s ?)", expr, value, o.id, op)
+	} else {
+		sql = fmt.Sprintf("(%[2]s IS NOT NULL AND %[1]s IS NOT NULL AND (%[1]s %[4]s %[2]s OR (%[1]s = %[2]s AND %[3]s %[4]s ?)))"+
+			" OR (%[2]s IS NULL AND (%[1]s IS NOT NULL OR %[3]s %[4]s ?))", expr, value, o.id, op)
+	}
+
+	// Every placeholder, in the value too, stands for the id
+	vars := make([]any, strings.Count(sql, "?"))
+	for i := range vars {
+		vars[i] = id
+	}
+
+	return clause.Expr{SQL: "(" + sql + ")", Vars: vars}
+}
+
+func (o *ordering) orderBy(backward bool) string {
+	direction := "ASC"
+	if o.desc != backward {
+		direction = "DESC"
+	}
+
+	if o.expr == "" {
+		return o.id + " " + direction
+	}
+
+	nulls := "ASC"
+	if backward {
+		nulls = "DESC"
+	}
+
+	return fmt.Sprintf("(%s IS NULL) %s, %s %s, %s %s", o.expr, nulls, o.expr, direction, o.id, direction)
+}
+
+// Finds a page of the query by keyset pagination, so pages stay stable
+// as rows are added or removed. Rows are in the given order either way.
+// It also reports whether there are more rows past the page, in the
+// direction it was taken.
+func findPage[T any](query *gorm.DB, order *ordering, page *models.Page) ([]*T, bool, error) {
+	if page.After != nil {
+		query = query.Where(order.beyond(*page.After, true))
+	}
+
+	if page.Before != nil {
+		query = query.Where(order.beyond(*page.Before, false))
+	}
+
+	rows := []*T{}
+	err := query.Order(order.orderBy(page.Backward)).Limit(page.Limit + 1).Find(&rows).Error
+	if err != nil {
+		return nil, false, err
+	}
+
+	more := len(rows) > page.Limit
+	if more {
+		rows = rows[:page.Limit]
+	}
+
+	if page.Backward {
+		slices.Reverse(rows)
+	}
+
+	return rows, more, nil
+}
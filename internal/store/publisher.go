@@ -1,6 +1,7 @@
 package store
 
 import (
+	"github.com/google/uuid"
 	"github.com/marcos-brito/booklist/internal/models"
 	"gorm.io/gorm"
 )
@@ -23,3 +24,97 @@ func (as *PublisherStore) FindById(id uint) (*models.Publisher, error) {
 
 	return publisher, nil
 }
+
+func (as *PublisherStore) FindByIds(ids []uint) ([]*models.Publisher, error) {
+	publishers := []*models.Publisher{}
+	err := as.DB.Where("id IN ?", ids).Find(&publishers).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return publishers, nil
+}
+
+func (as *PublisherStore) Create(input *models.CreatePublisher, userUuid uuid.UUID) (*models.Publisher, error) {
+	profile, err := NewUserStore(as.DB).FindProfileByUserUuid(userUuid)
+	if err != nil {
+		return nil, err
+	}
+
+	publisher := models.NewPublisherFromInput(input, profile.ID)
+	err = as.DB.Create(publisher).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return as.FindById(publisher.ID)
+}
+
+func (as *PublisherStore) Update(id uint, changes *models.UpdatePublisher) (*models.Publisher, error) {
+	publisher, err := as.FindById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	if changes.Name != nil {
+		publisher.Name = *changes.Name
+	}
+
+	err = as.DB.Save(publisher).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return publisher, nil
+}
+
+// Moves every book of the source publisher to the target and deletes
+// the source.
+func (as *PublisherStore) Merge(sourceId, targetId uint) (*models.Publisher, error) {
+	err := as.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Model(&models.Book{}).Where("publisher_id = ?", sourceId).Update("publisher_id", targetId).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Delete(&models.Publisher{}, sourceId).Error
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return as.FindById(targetId)
+}
+
+func (as *PublisherStore) Approve(id uint) (*models.Publisher, error) {
+	publisher, err := as.FindById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	publisher.NeedsApproval = false
+	publisher.RejectionReason = nil
+	err = as.DB.Save(publisher).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return publisher, nil
+}
+
+func (as *PublisherStore) Reject(id uint, reason string) (*models.Publisher, error) {
+	publisher, err := as.FindById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	publisher.NeedsApproval = true
+	publisher.RejectionReason = &reason
+	err = as.DB.Save(publisher).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return publisher, nil
+}
@@ -0,0 +1,171 @@
+package store
+
+import (
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/marcos-brito/booklist/internal/models"
+	"gorm.io/gorm"
+)
+
+// The stores are used through these interfaces, so they can be swapped
+// for other implementations, like fakes in tests. The gorm stores in this
+// package are the ones used in production.
+
+type BookRepository interface {
+	FindById(id uint) (*models.Book, error)
+	FindByIds(ids []uint) ([]*models.Book, error)
+	FindAuthorsByBooks(ids []uint) (map[uint][]*models.Author, error)
+	FindByIsbn(isbn string) (*models.Book, error)
+	FindMany(filter *models.BookFilter, viewer *models.Profile, limit, offset int) ([]*models.Book, error)
+	Count(filter *models.BookFilter, viewer *models.Profile) (int64, error)
+	Search(query string, viewer *models.Profile, limit int) ([]*models.BookSearchResult, error)
+	FindPending(limit, offset int) ([]*models.Book, error)
+	CountPending() (int64, error)
+	Approve(id uint) (*models.Book, error)
+	Reject(id uint, reason string) (*models.Book, error)
+	FindSubmitted(userUuid uuid.UUID, limit, offset int) ([]*models.Book, error)
+	CountSubmitted(userUuid uuid.UUID) (int64, error)
+	Create(input *models.CreateBook, userUuid uuid.UUID) (*models.Book, error)
+	FindReviews(id uint, viewer *models.Profile, limit, offset int) ([]*models.Review, error)
+	CountReviews(id uint, viewer *models.Profile) (int64, error)
+}
+
+type AuthorRepository interface {
+	FindById(id uint) (*models.Author, error)
+	FindManyById(ids ...uint) ([]*models.Author, error, *uint)
+	Search(search string, needsApproval *bool, viewer *models.Profile, limit, offset int) ([]*models.Author, error)
+	FindByName(name string, viewer *models.Profile) (*models.Author, error)
+	FindBooks(id uint, viewer *models.Profile) ([]*models.Book, error)
+	Create(input *models.CreateAuthor, userUuid uuid.UUID) (*models.Author, error)
+	Update(id uint, changes *models.UpdateAuthor) (*models.Author, error)
+	Merge(sourceId, targetId uint) (*models.Author, error)
+	Approve(id uint) (*models.Author, error)
+	Reject(id uint, reason string) (*models.Author, error)
+}
+
+type PublisherRepository interface {
+	FindById(id uint) (*models.Publisher, error)
+	FindByIds(ids []uint) ([]*models.Publisher, error)
+	Create(input *models.CreatePublisher, userUuid uuid.UUID) (*models.Publisher, error)
+	Update(id uint, changes *models.UpdatePublisher) (*models.Publisher, error)
+	Merge(sourceId, targetId uint) (*models.Publisher, error)
+	Approve(id uint) (*models.Publisher, error)
+	Reject(id uint, reason string) (*models.Publisher, error)
+}
+
+type ListRepository interface {
+	IsOwner(id uint, userUuid uuid.UUID) (bool, error)
+	FindById(id uint) (*models.List, error)
+	FindBooksByLists(ids []uint) (map[uint][]*models.Book, error)
+	FindBooksPage(id uint, viewer *models.Profile, page *models.Page) ([]*models.Book, bool, error)
+	CountBooks(id uint, viewer *models.Profile) (int64, error)
+	FindByName(name string, userUuid uuid.UUID) (*models.List, error)
+	Create(name string, desc *string, publish bool, userUuid uuid.UUID) (*models.List, error)
+	Delete(id uint) (*models.List, error)
+	Clone(id uint, userUuid uuid.UUID) (*models.List, error)
+	Publish(id uint) (*models.List, error)
+	Unpublish(id uint) (*models.List, error)
+	Follow(id uint, userUuid uuid.UUID) (*models.List, error)
+	Unfollow(id uint, userUuid uuid.UUID) (*models.List, error)
+	IsFollower(id uint, userUuid uuid.UUID) (bool, error)
+	FindFollowers(id uint) ([]*models.Profile, error)
+	CountFollowers(id uint) (int64, error)
+	AddBook(listId, bookId uint) (*models.List, error)
+	RemoveBook(listId, bookId uint) (*models.List, error)
+}
+
+type UserRepository interface {
+	FindProfileByUserUuid(uuid uuid.UUID) (*models.Profile, error)
+	FindFullProfileById(id uint) (*models.Profile, error)
+	FindProfilesByIds(ids []uint) ([]*models.Profile, error)
+	FindProfilesByUserUuids(uuids []uuid.UUID) ([]*models.Profile, error)
+	FindSettingsByUserUuid(uuid uuid.UUID) (*models.Settings, error)
+	FindSettingsByProfileIds(ids []uint) ([]*models.Settings, error)
+	SetModerator(uuid uuid.UUID, moderator bool) (*models.Profile, error)
+	UpdateSettings(uuid uuid.UUID, changes models.UpdateSettings) (*models.Settings, error)
+	DeleteAccount(userUuid uuid.UUID) error
+
+	FindItemById(id uint) (*models.CollectionItem, error)
+	FindItemByBook(userUuid uuid.UUID, bookId uint) (*models.CollectionItem, error)
+	FindItems(userUuid uuid.UUID, filter *models.CollectionFilter, sort *models.CollectionSort) ([]*models.CollectionItem, error)
+	FindPublicItems(userUuid uuid.UUID, viewer *models.Profile, filter *models.CollectionFilter, sort *models.CollectionSort) ([]*models.CollectionItem, error)
+	FindItemsPage(userUuid uuid.UUID, filter *models.CollectionFilter, sort *models.CollectionSort, page *models.Page) ([]*models.CollectionItem, bool, error)
+	CountItems(userUuid uuid.UUID, filter *models.CollectionFilter) (int64, error)
+	FindPublicItemsPage(userUuid uuid.UUID, viewer *models.Profile, filter *models.CollectionFilter, sort *models.CollectionSort, page *models.Page) ([]*models.CollectionItem, bool, error)
+	CountPublicItems(userUuid uuid.UUID, viewer *models.Profile, filter *models.CollectionFilter) (int64, error)
+	FindItemHistory(id uint) ([]*models.ReadingEvent, error)
+	FindItemSessions(id uint) ([]*models.ReadingSession, error)
+	AddToCollection(userUuid uuid.UUID, bookID uint, status models.Status, dates models.ReadingDates) (*models.CollectionItem, error)
+	DeleteFromCollection(id uint) (*models.CollectionItem, error)
+	ChangeItemStatus(id uint, status models.Status, dates models.ReadingDates) (*models.CollectionItem, error)
+	UpdateProgress(id uint, update models.ProgressUpdate) (*models.CollectionItem, error)
+	RateItem(id uint, rating *float64) (*models.CollectionItem, error)
+	ReviewItem(id uint, review *string, spoiler bool) (*models.CollectionItem, error)
+
+	FindLists(userUuid uuid.UUID) ([]*models.List, error)
+	FindPublicLists(userUuid uuid.UUID) ([]*models.List, error)
+	FindListsPage(userUuid uuid.UUID, all bool, page *models.Page) ([]*models.List, bool, error)
+	CountLists(userUuid uuid.UUID, all bool) (int64, error)
+	FindFollowedLists(userUuid uuid.UUID) ([]*models.List, error)
+
+	FindStats(userUuid uuid.UUID, statsRange *models.StatsRange, viewer *models.Profile) (*models.Stats, error)
+}
+
+type GoalRepository interface {
+	FindByYear(profileId uint, year int) (*models.ReadingGoal, error)
+	Set(profileId uint, year int, books, pages *int) (*models.ReadingGoal, error)
+	Complete(goal *models.ReadingGoal) (bool, error)
+	Reopen(goal *models.ReadingGoal) error
+}
+
+type ImportRepository interface {
+	FindById(id uint) (*models.ImportJob, error)
+	Create(profileId uint, format models.ImportFormat, total int) (*models.ImportJob, error)
+	SaveProgress(job *models.ImportJob) error
+	AddError(job *models.ImportJob, line int, title, message string) error
+	Finish(job *models.ImportJob, status models.ImportStatus) error
+}
+
+type ExportRepository interface {
+	FindByToken(token string) (*models.DataExport, error)
+	Create(profileId uint, format models.ExportFormat, ttl time.Duration) (*models.DataExport, error)
+	FindData(profileId uint) (*models.ExportData, error)
+}
+
+var (
+	_ BookRepository      = (*BookStore)(nil)
+	_ AuthorRepository    = (*AuthorStore)(nil)
+	_ PublisherRepository = (*PublisherStore)(nil)
+	_ ListRepository      = (*ListStore)(nil)
+	_ UserRepository      = (*UserStore)(nil)
+	_ GoalRepository      = (*GoalStore)(nil)
+	_ ImportRepository    = (*ImportStore)(nil)
+	_ ExportRepository    = (*ExportStore)(nil)
+)
+
+// Every repository the app uses.
+type Repositories struct {
+	Books      BookRepository
+	Authors    AuthorRepository
+	Publishers PublisherRepository
+	Lists      ListRepository
+	Users      UserRepository
+	Goals      GoalRepository
+	Imports    ImportRepository
+	Exports    ExportRepository
+}
+
+// Returns the gorm stores over the database.
+func NewRepositories(db *gorm.DB) *Repositories {
+	return &Repositories{
+		Books:      NewBookStore(db),
+		Authors:    NewAuthorStore(db),
+		Publishers: NewPublisherStore(db),
+		Lists:      NewListStore(db),
+		Users:      NewUserStore(db),
+		Goals:      NewGoalStore(db),
+		Imports:    NewImportStore(db),
+		Exports:    NewExportStore(db),
+	}
+}
@@ -0,0 +1,42 @@
+package store
+
+import (
+	"github.com/marcos-brito/booklist/internal/models"
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// Scopes a query on books, authors or publishers to the rows the viewer
+// can see. Mirrors the VisibleTo methods on those models.
+func VisibleTo(viewer *models.Profile) func(*gorm.DB) *gorm.DB {
+	return func(db *gorm.DB) *gorm.DB {
+		approved := clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: "needs_approval"}, Value: false}
+		if viewer == nil {
+			return db.Where(approved)
+		}
+
+		if viewer.Moderator {
+			return db
+		}
+
+		owned := clause.Eq{Column: clause.Column{Table: clause.CurrentTable, Name: "profile_id"}, Value: viewer.ID}
+		return db.Where(clause.Or(approved, owned))
+	}
+}
+
+// Scopes a query on books, authors or publishers to the rows that wait
+// for approval and haven't been rejected yet.
+func Pending(db *gorm.DB) *gorm.DB {
+	return db.Where("needs_approval = ? AND rejection_reason IS NULL", true)
+}
+
+// Locks the rows a query reads until its transaction ends. SQLite has no
+// row locks, but its transactions already take the write lock when they
+// begin.
+func ForUpdate(db *gorm.DB) *gorm.DB {
+	if db.Dialector.Name() != "postgres" {
+		return db
+	}
+
+	return db.Clauses(clause.Locking{Strength: "UPDATE"})
+}
@@ -0,0 +1,191 @@
+package store
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/marcos-brito/booklist/internal/models"
+	"gorm.io/gorm"
+)
+
+// How many authors and publishers are ranked in the stats.
+const topCount = 5
+
+type countRow struct {
+	ID    uint
+	Count int
+}
+
+// Aggregates the collection of a user. Finished books are the ones read
+// within the range, while the status distribution covers the whole
+// collection as it is now. Authors and publishers the viewer can't see
+// are left out of the rankings.
+func (us *UserStore) FindStats(userUuid uuid.UUID, statsRange *models.StatsRange, viewer *models.Profile) (*models.Stats, error) {
+	profile, err := us.FindProfileByUserUuid(userUuid)
+	if err != nil {
+		return nil, err
+	}
+
+	stats := &models.Stats{}
+	stats.FinishedPerMonth, err = us.finishedPer(profile.ID, statsRange, "month")
+	if err != nil {
+		return nil, err
+	}
+
+	stats.FinishedPerYear, err = us.finishedPer(profile.ID, statsRange, "year")
+	if err != nil {
+		return nil, err
+	}
+
+	err = us.finished(profile.ID, statsRange).
+		Where("collection_items.started_at IS NOT NULL").
+		Select(fmt.Sprintf("AVG(%s)", daysBetween(us.DB, "collection_items.started_at", "collection_items.finished_at"))).
+		Scan(&stats.AverageDaysToFinish).Error
+	if err != nil {
+		return nil, err
+	}
+
+	stats.StatusDistribution = []*models.StatusCount{}
+	err = us.DB.Model(&models.CollectionItem{}).
+		Select("status, COUNT(*) AS count").
+		Where(&models.CollectionItem{ProfileID: profile.ID}).
+		Group("status").Order("count DESC").Order("status").
+		Scan(&stats.StatusDistribution).Error
+	if err != nil {
+		return nil, err
+	}
+
+	stats.TopAuthors, err = us.topAuthors(profile.ID, statsRange, viewer)
+	if err != nil {
+		return nil, err
+	}
+
+	stats.TopPublishers, err = us.topPublishers(profile.ID, statsRange, viewer)
+	if err != nil {
+		return nil, err
+	}
+
+	return stats, nil
+}
+
+func (us *UserStore) finished(profileId uint, statsRange *models.StatsRange) *gorm.DB {
+	if statsRange == nil {
+		return finishedItems(us.DB, profileId, nil, nil)
+	}
+
+	return finishedItems(us.DB, profileId, statsRange.From, statsRange.To)
+}
+
+// Items of the profile that were read, optionally only the ones finished
+// within the given dates.
+func finishedItems(db *gorm.DB, profileId uint, from, to *time.Time) *gorm.DB {
+	query := db.Model(&models.CollectionItem{}).
+		Where("collection_items.profile_id = ?", profileId).
+		Where("collection_items.status = ? AND collection_items.finished_at IS NOT NULL", models.StatusRead)
+
+	if from != nil {
+		query = query.Where("collection_items.finished_at >= ?", *from)
+	}
+
+	if to != nil {
+		query = query.Where("collection_items.finished_at <= ?", *to)
+	}
+
+	return query
+}
+
+func (us *UserStore) finishedPer(profileId uint, statsRange *models.StatsRange, unit string) ([]*models.PeriodStats, error) {
+	period := truncateDate(us.DB, "collection_items.finished_at", unit)
+	periods := []*models.PeriodStats{}
+	err := us.finished(profileId, statsRange).
+		Select(fmt.Sprintf("%s AS period, COUNT(*) AS books, COALESCE(SUM(books.page_count), 0) AS pages", period)).
+		Joins("JOIN books ON books.id = collection_items.book_id").
+		Group("period").Order("period").
+		Scan(&periods).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	return periods, nil
+}
+
+func (us *UserStore) topAuthors(profileId uint, statsRange *models.StatsRange, viewer *models.Profile) ([]*models.AuthorCount, error) {
+	finished := us.finished(profileId, statsRange).Select("collection_items.book_id")
+	rows := []*countRow{}
+	err := us.DB.Model(&models.Author{}).Scopes(VisibleTo(viewer)).
+		Select("authors.id, COUNT(*) AS count").
+		Joins("JOIN book_authors ON book_authors.author_id = authors.id").
+		Where("book_authors.book_id IN (?)", finished).
+		Group("authors.id").Order("count DESC").Order("authors.id").
+		Limit(topCount).Scan(&rows).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	counts := []*models.AuthorCount{}
+	for _, row := range rows {
+		author := &models.Author{}
+		err = us.DB.First(author, row.ID).Error
+		if err != nil {
+			return nil, err
+		}
+
+		counts = append(counts, &models.AuthorCount{Author: author, Count: row.Count})
+	}
+
+	return counts, nil
+}
+
+func (us *UserStore) topPublishers(profileId uint, statsRange *models.StatsRange, viewer *models.Profile) ([]*models.PublisherCount, error) {
+	finished := us.finished(profileId, statsRange).Select("collection_items.book_id")
+	rows := []*countRow{}
+	err := us.DB.Model(&models.Publisher{}).Scopes(VisibleTo(viewer)).
+		Select("publishers.id, COUNT(*) AS count").
+		Joins("JOIN books ON books.publisher_id = publishers.id").
+		Where("books.id IN (?)", finished).
+		Group("publishers.id").Order("count DESC").Order("publishers.id").
+		Limit(topCount).Scan(&rows).Error
+
+	if err != nil {
+		return nil, err
+	}
+
+	counts := []*models.PublisherCount{}
+	for _, row := range rows {
+		publisher := &models.Publisher{}
+		err = us.DB.First(publisher, row.ID).Error
+		if err != nil {
+			return nil, err
+		}
+
+		counts = append(counts, &models.PublisherCount{Publisher: publisher, Count: row.Count})
+	}
+
+	return counts, nil
+}
+
+// Formats a date column as "2006" for years or "2006-01" for months.
+func truncateDate(db *gorm.DB, column, unit string) string {
+	postgres, sqlite := "YYYY-MM", "%Y-%m"
+	if unit == "year" {
+		postgres, sqlite = "YYYY", "%Y"
+	}
+
+	if db.Dialector.Name() == "postgres" {
+		return fmt.Sprintf("to_char(%s, '%s')", column, postgres)
+	}
+
+	return fmt.Sprintf("strftime('%s', %s)", sqlite, column)
+}
+
+// Fractional days from one date column to another.
+func daysBetween(db *gorm.DB, from, to string) string {
+	if db.Dialector.Name() == "postgres" {
+		return fmt.Sprintf("EXTRACT(EPOCH FROM (%s - %s)) / 86400", to, from)
+	}
+
+	return fmt.Sprintf("julianday(%s) - julianday(%s)", to, from)
+}
@@ -0,0 +1,26 @@
+package store_test
+
+import (
+	"testing"
+
+	"github.com/marcos-brito/booklist/internal/store"
+	"github.com/marcos-brito/booklist/internal/store/storetest"
+	"github.com/marcos-brito/booklist/internal/testdb"
+	"gorm.io/gorm"
+)
+
+var db *gorm.DB
+
+// The tests run once for every database the server supports, or only
+// against the one in TEST_DATABASE if it's set.
+func TestMain(m *testing.M) {
+	testdb.Main(m, testdb.Drivers, func(driver string) func() {
+		var teardown func()
+		db, teardown = testdb.Open(driver)
+		return teardown
+	})
+}
+
+func TestConformance(t *testing.T) {
+	storetest.Run(t, store.NewRepositories(db))
+}
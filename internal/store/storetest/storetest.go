@@ -0,0 +1,1243 @@
+// Package storetest checks that implementations of the repositories in
+// package store behave the same. Every implementation runs the same
+// suite, so they can't drift apart.
+package storetest
+
+import (
+	"fmt"
+	"math/rand/v2"
+	"testing"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/marcos-brito/booklist/internal/models"
+	"github.com/marcos-brito/booklist/internal/store"
+	"github.com/stretchr/testify/assert"
+	"gorm.io/gorm"
+)
+
+// Runs the suite against every repository in repos. Tests only look at rows they create, so repos may hold data
+// already, like a database shared with other tests.
+func Run(t *testing.T, repos *store.Repositories) {
+	t.Run("Books", func(t *testing.T) { testBooks(t, repos) })
+	t.Run("Authors", func(t *testing.T) { testAuthors(t, repos) })
+	t.Run("Publishers", func(t *testing.T) { testPublishers(t, repos) })
+	t.Run("Lists", func(t *testing.T) { testLists(t, repos) })
+	t.Run("Users", func(t *testing.T) { testUsers(t, repos) })
+	t.Run("Goals", func(t *testing.T) { testGoals(t, repos) })
+	t.Run("Imports", func(t *testing.T) { testImports(t, repos) })
+	t.Run("Exports", func(t *testing.T) { testExports(t, repos) })
+}
+
+// Returns a user with a profile, as the stores expect of users who
+// submit anything.
+func newUser(t *testing.T, repos *store.Repositories) (uuid.UUID, *models.Profile) {
+	userUuid := uuid.New()
+	profile, err := repos.Users.FindProfileByUserUuid(userUuid)
+	assert.Nil(t, err)
+
+	return userUuid, profile
+}
+
+func newModerator(t *testing.T, repos *store.Repositories) *models.Profile {
+	userUuid, _ := newUser(t, repos)
+	profile, err := repos.Users.SetModerator(userUuid, true)
+	assert.Nil(t, err)
+
+	return profile
+}
+
+// Creates a book from the input, filling in a title and ISBN if it has
+// none.
+func newBook(t *testing.T, repos *store.Repositories, owner uuid.UUID, input models.CreateBook) *models.Book {
+	if input.Title == "" {
+		input.Title = "Book " + randomWord()
+	}
+
+	if input.Isbn == "" {
+		input.Isbn = randomIsbn()
+	}
+
+	book, err := repos.Books.Create(&input, owner)
+	assert.Nil(t, err)
+
+	return book
+}
+
+func newApprovedBook(t *testing.T, repos *store.Repositories, owner uuid.UUID, input models.CreateBook) *models.Book {
+	book, err := repos.Books.Approve(newBook(t, repos, owner, input).ID)
+	assert.Nil(t, err)
+
+	return book
+}
+
+func newAuthor(t *testing.T, repos *store.Repositories, owner uuid.UUID, name string) *models.Author {
+	author, err := repos.Authors.Create(&models.CreateAuthor{Name: name}, owner)
+	assert.Nil(t, err)
+
+	author, err = repos.Authors.Approve(author.ID)
+	assert.Nil(t, err)
+
+	return author
+}
+
+func newPublisher(t *testing.T, repos *store.Repositories, owner uuid.UUID) *models.Publisher {
+	publisher, err := repos.Publishers.Create(&models.CreatePublisher{Name: "Publisher " + randomWord()}, owner)
+	assert.Nil(t, err)
+
+	publisher, err = repos.Publishers.Approve(publisher.ID)
+	assert.Nil(t, err)
+
+	return publisher
+}
+
+func randomIsbn() string {
+	return fmt.Sprintf("978%010d", rand.IntN(1e10))
+}
+
+// Letters only, so full-text search sees a single word.
+func randomWord() string {
+	letters := make([]byte, 12)
+	for i := range letters {
+		letters[i] = byte('a' + rand.IntN(26))
+	}
+
+	return string(letters)
+}
+
+func bookIds(books []*models.Book) []uint {
+	ids := []uint{}
+	for _, book := range books {
+		ids = append(ids, book.ID)
+	}
+
+	return ids
+}
+
+func itemIds(items []*models.CollectionItem) []uint {
+	ids := []uint{}
+	for _, item := range items {
+		ids = append(ids, item.ID)
+	}
+
+	return ids
+}
+
+// A big enough id that no row has it.
+const missingId = 1 << 30
+
+func testBooks(t *testing.T, repos *store.Repositories) {
+	t.Run("should create books with their authors", func(t *testing.T) {
+		owner, profile := newUser(t, repos)
+		author := newAuthor(t, repos, owner, "Author "+randomWord())
+		publisher := newPublisher(t, repos, owner)
+		pages := 320
+		book := newBook(t, repos, owner, models.CreateBook{
+			Title:     "Dune",
+			Authors:   []uint{author.ID},
+			Publisher: &publisher.ID,
+			PageCount: &pages,
+		})
+
+		assert.True(t, book.NeedsApproval)
+		assert.Equal(t, profile.ID, *book.ProfileID)
+		assert.Len(t, book.Authors, 1)
+
+		found, err := repos.Books.FindById(book.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, "Dune", found.Title)
+		assert.Equal(t, publisher.ID, *found.PublisherID)
+		assert.Equal(t, pages, *found.PageCount)
+
+		found, err = repos.Books.FindByIsbn(book.ISBN)
+		assert.Nil(t, err)
+		assert.Equal(t, book.ID, found.ID)
+
+		authors, err := repos.Books.FindAuthorsByBooks([]uint{book.ID, missingId})
+		assert.Nil(t, err)
+		assert.Len(t, authors, 1)
+		assert.Equal(t, author.ID, authors[book.ID][0].ID)
+
+		books, err := repos.Books.FindByIds([]uint{book.ID, missingId})
+		assert.Nil(t, err)
+		assert.Equal(t, []uint{book.ID}, bookIds(books))
+	})
+
+	t.Run("should refuse an ISBN that's taken", func(t *testing.T) {
+		owner, _ := newUser(t, repos)
+		book := newBook(t, repos, owner, models.CreateBook{})
+
+		_, err := repos.Books.Create(&models.CreateBook{Title: "Copy", Isbn: book.ISBN}, owner)
+		assert.NotNil(t, err)
+	})
+
+	t.Run("should not find books that don't exist", func(t *testing.T) {
+		_, err := repos.Books.FindById(missingId)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+		_, err = repos.Books.FindByIsbn(randomIsbn())
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	})
+
+	t.Run("should only show pending books to their submitter and moderators", func(t *testing.T) {
+		owner, profile := newUser(t, repos)
+		_, other := newUser(t, repos)
+		author := newAuthor(t, repos, owner, "Author "+randomWord())
+		book := newBook(t, repos, owner, models.CreateBook{Authors: []uint{author.ID}})
+		filter := &models.BookFilter{Author: &author.ID}
+
+		for _, viewer := range []*models.Profile{nil, other} {
+			books, err := repos.Books.FindMany(filter, viewer, 10, 0)
+			assert.Nil(t, err)
+			assert.Empty(t, books)
+		}
+
+		for _, viewer := range []*models.Profile{profile, newModerator(t, repos)} {
+			books, err := repos.Books.FindMany(filter, viewer, 10, 0)
+			assert.Nil(t, err)
+			assert.Equal(t, []uint{book.ID}, bookIds(books))
+		}
+
+		_, err := repos.Books.Approve(book.ID)
+		assert.Nil(t, err)
+
+		count, err := repos.Books.Count(filter, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("should filter books", func(t *testing.T) {
+		owner, _ := newUser(t, repos)
+		publisher := newPublisher(t, repos, owner)
+		old := time.Date(1965, time.August, 1, 0, 0, 0, 0, time.UTC)
+		recent := time.Date(2015, time.March, 1, 0, 0, 0, 0, time.UTC)
+		first := newApprovedBook(t, repos, owner, models.CreateBook{Publisher: &publisher.ID, PublishedAt: &old})
+		second := newApprovedBook(t, repos, owner, models.CreateBook{Publisher: &publisher.ID, PublishedAt: &recent})
+		third := newApprovedBook(t, repos, owner, models.CreateBook{Publisher: &publisher.ID})
+
+		books, err := repos.Books.FindMany(&models.BookFilter{Publisher: &publisher.ID}, nil, 10, 0)
+		assert.Nil(t, err)
+		assert.Equal(t, []uint{first.ID, second.ID, third.ID}, bookIds(books))
+
+		books, err = repos.Books.FindMany(&models.BookFilter{Publisher: &publisher.ID}, nil, 1, 1)
+		assert.Nil(t, err)
+		assert.Equal(t, []uint{second.ID}, bookIds(books))
+
+		from, to := 1960, 1965
+		filter := &models.BookFilter{Publisher: &publisher.ID, PublishedFrom: &from, PublishedTo: &to}
+		books, err = repos.Books.FindMany(filter, nil, 10, 0)
+		assert.Nil(t, err)
+		assert.Equal(t, []uint{first.ID}, bookIds(books))
+
+		count, err := repos.Books.Count(filter, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("should list pending books until they are moderated", func(t *testing.T) {
+		owner, _ := newUser(t, repos)
+		before, err := repos.Books.CountPending()
+		assert.Nil(t, err)
+
+		book := newBook(t, repos, owner, models.CreateBook{})
+		count, err := repos.Books.CountPending()
+		assert.Nil(t, err)
+		assert.Equal(t, before+1, count)
+
+		books, err := repos.Books.FindPending(-1, 0)
+		assert.Nil(t, err)
+		assert.Contains(t, bookIds(books), book.ID)
+
+		rejected, err := repos.Books.Reject(book.ID, "Duplicate")
+		assert.Nil(t, err)
+		assert.Equal(t, "Duplicate", *rejected.RejectionReason)
+		assert.True(t, rejected.NeedsApproval)
+
+		count, err = repos.Books.CountPending()
+		assert.Nil(t, err)
+		assert.Equal(t, before, count)
+
+		approved, err := repos.Books.Approve(book.ID)
+		assert.Nil(t, err)
+		assert.Nil(t, approved.RejectionReason)
+		assert.False(t, approved.NeedsApproval)
+	})
+
+	t.Run("should list submitted books newest first", func(t *testing.T) {
+		owner, _ := newUser(t, repos)
+		first := newBook(t, repos, owner, models.CreateBook{})
+		second := newBook(t, repos, owner, models.CreateBook{})
+
+		books, err := repos.Books.FindSubmitted(owner, 10, 0)
+		assert.Nil(t, err)
+		assert.Equal(t, []uint{second.ID, first.ID}, bookIds(books))
+
+		count, err := repos.Books.CountSubmitted(owner)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(2), count)
+	})
+
+	t.Run("should only find reviews in collections the viewer can see", func(t *testing.T) {
+		owner, profile := newUser(t, repos)
+		book := newApprovedBook(t, repos, owner, models.CreateBook{})
+		item, err := repos.Users.AddToCollection(owner, book.ID, models.StatusRead, models.ReadingDates{})
+		assert.Nil(t, err)
+
+		review := "Great"
+		_, err = repos.Users.ReviewItem(item.ID, &review, true)
+		assert.Nil(t, err)
+
+		count, err := repos.Books.CountReviews(book.ID, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(0), count)
+
+		reviews, err := repos.Books.FindReviews(book.ID, profile, 10, 0)
+		assert.Nil(t, err)
+		assert.Len(t, reviews, 1)
+		assert.Equal(t, "Great", reviews[0].Body)
+		assert.Equal(t, item.ID, reviews[0].ID)
+		assert.True(t, reviews[0].Spoiler)
+
+		_, err = repos.Users.UpdateSettings(owner, models.UpdateSettings{ShowCollection: true})
+		assert.Nil(t, err)
+
+		count, err = repos.Books.CountReviews(book.ID, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("should search titles, authors and publishers", func(t *testing.T) {
+		owner, _ := newUser(t, repos)
+		word := randomWord()
+		author := newAuthor(t, repos, owner, "Author "+word)
+		publisher := newPublisher(t, repos, owner)
+		byTitle := newApprovedBook(t, repos, owner, models.CreateBook{Title: "The " + word})
+		byAuthor := newApprovedBook(t, repos, owner, models.CreateBook{Authors: []uint{author.ID}})
+		newApprovedBook(t, repos, owner, models.CreateBook{Publisher: &publisher.ID})
+		newBook(t, repos, owner, models.CreateBook{Title: word})
+
+		results, err := repos.Books.Search(word, nil, 10)
+		assert.Nil(t, err)
+		assert.Len(t, results, 2)
+		assert.Equal(t, byTitle.ID, results[0].Book.ID)
+		assert.Equal(t, byAuthor.ID, results[1].Book.ID)
+		assert.Contains(t, results[0].Highlight, "<b>"+word+"</b>")
+	})
+}
+
+func testAuthors(t *testing.T, repos *store.Repositories) {
+	t.Run("should create and update authors", func(t *testing.T) {
+		owner, profile := newUser(t, repos)
+		author, err := repos.Authors.Create(&models.CreateAuthor{Name: "Frank"}, owner)
+		assert.Nil(t, err)
+		assert.True(t, author.NeedsApproval)
+		assert.Equal(t, profile.ID, *author.ProfileID)
+
+		name := "Frank Herbert"
+		birthDay := time.Date(1920, time.October, 8, 0, 0, 0, 0, time.UTC)
+		updated, err := repos.Authors.Update(author.ID, &models.UpdateAuthor{Name: &name, BirthDay: &birthDay})
+		assert.Nil(t, err)
+		assert.Equal(t, name, updated.Name)
+
+		found, err := repos.Authors.FindById(author.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, name, found.Name)
+		assert.True(t, birthDay.Equal(*found.BirthDay))
+	})
+
+	t.Run("should report the first author that doesn't exist", func(t *testing.T) {
+		owner, _ := newUser(t, repos)
+		author := newAuthor(t, repos, owner, "Author "+randomWord())
+
+		authors, err, missing := repos.Authors.FindManyById(author.ID)
+		assert.Nil(t, err)
+		assert.Nil(t, missing)
+		assert.Len(t, authors, 1)
+
+		_, err, missing = repos.Authors.FindManyById(author.ID, missingId)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+		assert.Equal(t, uint(missingId), *missing)
+	})
+
+	t.Run("should search authors the viewer can see by name", func(t *testing.T) {
+		owner, profile := newUser(t, repos)
+		word := randomWord()
+		approved := newAuthor(t, repos, owner, "B "+word)
+		pending, err := repos.Authors.Create(&models.CreateAuthor{Name: "A " + word}, owner)
+		assert.Nil(t, err)
+
+		authors, err := repos.Authors.Search(word, nil, nil, 10, 0)
+		assert.Nil(t, err)
+		assert.Len(t, authors, 1)
+		assert.Equal(t, approved.ID, authors[0].ID)
+
+		authors, err = repos.Authors.Search(word, nil, profile, 10, 0)
+		assert.Nil(t, err)
+		assert.Len(t, authors, 2)
+		assert.Equal(t, pending.ID, authors[0].ID)
+
+		needsApproval := true
+		authors, err = repos.Authors.Search(word, &needsApproval, profile, 10, 0)
+		assert.Nil(t, err)
+		assert.Len(t, authors, 1)
+		assert.Equal(t, pending.ID, authors[0].ID)
+	})
+
+	t.Run("should find authors by name ignoring case", func(t *testing.T) {
+		owner, profile := newUser(t, repos)
+		name := "Author " + randomWord()
+		pending, err := repos.Authors.Create(&models.CreateAuthor{Name: name}, owner)
+		assert.Nil(t, err)
+
+		_, err = repos.Authors.FindByName(name, nil)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+		found, err := repos.Authors.FindByName(name, profile)
+		assert.Nil(t, err)
+		assert.Equal(t, pending.ID, found.ID)
+
+		approved := newAuthor(t, repos, owner, name)
+		found, err = repos.Authors.FindByName(name, profile)
+		assert.Nil(t, err)
+		assert.Equal(t, approved.ID, found.ID)
+	})
+
+	t.Run("should find the books of an author the viewer can see", func(t *testing.T) {
+		owner, profile := newUser(t, repos)
+		author := newAuthor(t, repos, owner, "Author "+randomWord())
+		approved := newApprovedBook(t, repos, owner, models.CreateBook{Authors: []uint{author.ID}})
+		pending := newBook(t, repos, owner, models.CreateBook{Authors: []uint{author.ID}})
+
+		books, err := repos.Authors.FindBooks(author.ID, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, []uint{approved.ID}, bookIds(books))
+
+		books, err = repos.Authors.FindBooks(author.ID, profile)
+		assert.Nil(t, err)
+		assert.Equal(t, []uint{approved.ID, pending.ID}, bookIds(books))
+	})
+
+	t.Run("should merge authors", func(t *testing.T) {
+		owner, _ := newUser(t, repos)
+		source := newAuthor(t, repos, owner, "Author "+randomWord())
+		target := newAuthor(t, repos, owner, "Author "+randomWord())
+		shared := newApprovedBook(t, repos, owner, models.CreateBook{Authors: []uint{source.ID, target.ID}})
+		moved := newApprovedBook(t, repos, owner, models.CreateBook{Authors: []uint{source.ID}})
+
+		merged, err := repos.Authors.Merge(source.ID, target.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, target.ID, merged.ID)
+
+		_, err = repos.Authors.FindById(source.ID)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+		authors, err := repos.Books.FindAuthorsByBooks([]uint{shared.ID, moved.ID})
+		assert.Nil(t, err)
+		assert.Len(t, authors[shared.ID], 1)
+		assert.Equal(t, target.ID, authors[shared.ID][0].ID)
+		assert.Equal(t, target.ID, authors[moved.ID][0].ID)
+	})
+
+	t.Run("should moderate authors", func(t *testing.T) {
+		owner, _ := newUser(t, repos)
+		author, err := repos.Authors.Create(&models.CreateAuthor{Name: "Author"}, owner)
+		assert.Nil(t, err)
+
+		rejected, err := repos.Authors.Reject(author.ID, "Unknown")
+		assert.Nil(t, err)
+		assert.Equal(t, "Unknown", *rejected.RejectionReason)
+
+		approved, err := repos.Authors.Approve(author.ID)
+		assert.Nil(t, err)
+		assert.False(t, approved.NeedsApproval)
+		assert.Nil(t, approved.RejectionReason)
+
+		_, err = repos.Authors.Approve(missingId)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	})
+}
+
+func testPublishers(t *testing.T, repos *store.Repositories) {
+	t.Run("should create and update publishers", func(t *testing.T) {
+		owner, profile := newUser(t, repos)
+		publisher, err := repos.Publishers.Create(&models.CreatePublisher{Name: "Ace"}, owner)
+		assert.Nil(t, err)
+		assert.True(t, publisher.NeedsApproval)
+		assert.Equal(t, profile.ID, *publisher.ProfileID)
+
+		name := "Ace Books"
+		_, err = repos.Publishers.Update(publisher.ID, &models.UpdatePublisher{Name: &name})
+		assert.Nil(t, err)
+
+		publishers, err := repos.Publishers.FindByIds([]uint{publisher.ID, missingId})
+		assert.Nil(t, err)
+		assert.Len(t, publishers, 1)
+		assert.Equal(t, name, publishers[0].Name)
+	})
+
+	t.Run("should merge publishers", func(t *testing.T) {
+		owner, _ := newUser(t, repos)
+		source := newPublisher(t, repos, owner)
+		target := newPublisher(t, repos, owner)
+		book := newApprovedBook(t, repos, owner, models.CreateBook{Publisher: &source.ID})
+
+		merged, err := repos.Publishers.Merge(source.ID, target.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, target.ID, merged.ID)
+
+		_, err = repos.Publishers.FindById(source.ID)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+		found, err := repos.Books.FindById(book.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, target.ID, *found.PublisherID)
+	})
+
+	t.Run("should moderate publishers", func(t *testing.T) {
+		owner, _ := newUser(t, repos)
+		publisher, err := repos.Publishers.Create(&models.CreatePublisher{Name: "Publisher"}, owner)
+		assert.Nil(t, err)
+
+		rejected, err := repos.Publishers.Reject(publisher.ID, "Unknown")
+		assert.Nil(t, err)
+		assert.Equal(t, "Unknown", *rejected.RejectionReason)
+
+		approved, err := repos.Publishers.Approve(publisher.ID)
+		assert.Nil(t, err)
+		assert.False(t, approved.NeedsApproval)
+		assert.Nil(t, approved.RejectionReason)
+	})
+}
+
+func testLists(t *testing.T, repos *store.Repositories) {
+	t.Run("should create lists owned by their user", func(t *testing.T) {
+		owner, profile := newUser(t, repos)
+		other, _ := newUser(t, repos)
+		desc := "Favorites"
+		list, err := repos.Lists.Create("Best", &desc, true, owner)
+		assert.Nil(t, err)
+		assert.Equal(t, profile.ID, list.ProfileID)
+		assert.True(t, list.Published)
+
+		found, err := repos.Lists.FindByName("Best", owner)
+		assert.Nil(t, err)
+		assert.Equal(t, list.ID, found.ID)
+		assert.Equal(t, desc, *found.Description)
+
+		_, err = repos.Lists.FindByName("Best", other)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+		owned, err := repos.Lists.IsOwner(list.ID, owner)
+		assert.Nil(t, err)
+		assert.True(t, owned)
+
+		owned, err = repos.Lists.IsOwner(list.ID, other)
+		assert.Nil(t, err)
+		assert.False(t, owned)
+
+		_, err = repos.Lists.IsOwner(missingId, owner)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	})
+
+	t.Run("should add and remove books", func(t *testing.T) {
+		owner, profile := newUser(t, repos)
+		list, err := repos.Lists.Create("List", nil, true, owner)
+		assert.Nil(t, err)
+
+		approved := newApprovedBook(t, repos, owner, models.CreateBook{})
+		pending := newBook(t, repos, owner, models.CreateBook{})
+		for _, book := range []*models.Book{approved, pending, approved} {
+			_, err = repos.Lists.AddBook(list.ID, book.ID)
+			assert.Nil(t, err)
+		}
+
+		books, err := repos.Lists.FindBooksByLists([]uint{list.ID})
+		assert.Nil(t, err)
+		assert.Equal(t, []uint{approved.ID, pending.ID}, bookIds(books[list.ID]))
+
+		count, err := repos.Lists.CountBooks(list.ID, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), count)
+
+		count, err = repos.Lists.CountBooks(list.ID, profile)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(2), count)
+
+		_, err = repos.Lists.RemoveBook(list.ID, approved.ID)
+		assert.Nil(t, err)
+
+		books, err = repos.Lists.FindBooksByLists([]uint{list.ID})
+		assert.Nil(t, err)
+		assert.Equal(t, []uint{pending.ID}, bookIds(books[list.ID]))
+	})
+
+	t.Run("should page through the books", func(t *testing.T) {
+		owner, _ := newUser(t, repos)
+		list, err := repos.Lists.Create("List", nil, true, owner)
+		assert.Nil(t, err)
+
+		ids := []uint{}
+		for range 5 {
+			book := newApprovedBook(t, repos, owner, models.CreateBook{})
+			_, err = repos.Lists.AddBook(list.ID, book.ID)
+			assert.Nil(t, err)
+			ids = append(ids, book.ID)
+		}
+
+		books, more, err := repos.Lists.FindBooksPage(list.ID, nil, &models.Page{Limit: 2})
+		assert.Nil(t, err)
+		assert.True(t, more)
+		assert.Equal(t, ids[:2], bookIds(books))
+
+		books, more, err = repos.Lists.FindBooksPage(list.ID, nil, &models.Page{Limit: 2, After: &ids[3]})
+		assert.Nil(t, err)
+		assert.False(t, more)
+		assert.Equal(t, ids[4:], bookIds(books))
+
+		books, more, err = repos.Lists.FindBooksPage(list.ID, nil, &models.Page{Limit: 2, Before: &ids[4], Backward: true})
+		assert.Nil(t, err)
+		assert.True(t, more)
+		assert.Equal(t, ids[2:4], bookIds(books))
+	})
+
+	t.Run("should clone lists with their books", func(t *testing.T) {
+		owner, _ := newUser(t, repos)
+		cloner, profile := newUser(t, repos)
+		desc := "Description"
+		list, err := repos.Lists.Create("List", &desc, true, owner)
+		assert.Nil(t, err)
+
+		book := newApprovedBook(t, repos, owner, models.CreateBook{})
+		_, err = repos.Lists.AddBook(list.ID, book.ID)
+		assert.Nil(t, err)
+
+		clone, err := repos.Lists.Clone(list.ID, cloner)
+		assert.Nil(t, err)
+		assert.NotEqual(t, list.ID, clone.ID)
+		assert.Equal(t, profile.ID, clone.ProfileID)
+		assert.Equal(t, "List", clone.Name)
+		assert.Equal(t, desc, *clone.Description)
+		assert.False(t, clone.Published)
+		assert.Len(t, clone.Books, 1)
+
+		books, err := repos.Lists.FindBooksByLists([]uint{clone.ID})
+		assert.Nil(t, err)
+		assert.Equal(t, []uint{book.ID}, bookIds(books[clone.ID]))
+	})
+
+	t.Run("should publish and delete lists", func(t *testing.T) {
+		owner, _ := newUser(t, repos)
+		list, err := repos.Lists.Create("List", nil, false, owner)
+		assert.Nil(t, err)
+
+		published, err := repos.Lists.Publish(list.ID)
+		assert.Nil(t, err)
+		assert.True(t, published.Published)
+
+		unpublished, err := repos.Lists.Unpublish(list.ID)
+		assert.Nil(t, err)
+		assert.False(t, unpublished.Published)
+
+		_, err = repos.Lists.Delete(list.ID)
+		assert.Nil(t, err)
+
+		_, err = repos.Lists.FindById(list.ID)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	})
+
+	t.Run("should follow and unfollow lists", func(t *testing.T) {
+		owner, _ := newUser(t, repos)
+		first, firstProfile := newUser(t, repos)
+		second, secondProfile := newUser(t, repos)
+		list, err := repos.Lists.Create("List", nil, true, owner)
+		assert.Nil(t, err)
+
+		for _, follower := range []uuid.UUID{first, second, first} {
+			_, err = repos.Lists.Follow(list.ID, follower)
+			assert.Nil(t, err)
+		}
+
+		following, err := repos.Lists.IsFollower(list.ID, first)
+		assert.Nil(t, err)
+		assert.True(t, following)
+
+		followers, err := repos.Lists.FindFollowers(list.ID)
+		assert.Nil(t, err)
+		assert.Len(t, followers, 2)
+		assert.Equal(t, firstProfile.ID, followers[0].ID)
+		assert.Equal(t, secondProfile.ID, followers[1].ID)
+		assert.True(t, followers[0].Settings.Private)
+
+		_, err = repos.Lists.Unfollow(list.ID, first)
+		assert.Nil(t, err)
+
+		following, err = repos.Lists.IsFollower(list.ID, first)
+		assert.Nil(t, err)
+		assert.False(t, following)
+
+		count, err := repos.Lists.CountFollowers(list.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(0), count)
+
+		_, err = repos.Users.UpdateSettings(second, models.UpdateSettings{})
+		assert.Nil(t, err)
+
+		count, err = repos.Lists.CountFollowers(list.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), count)
+
+		_, err = repos.Lists.Follow(missingId, first)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	})
+}
+
+func testUsers(t *testing.T, repos *store.Repositories) {
+	t.Run("should create private profiles on first sight", func(t *testing.T) {
+		userUuid := uuid.New()
+		_, err := repos.Users.FindSettingsByUserUuid(userUuid)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+		profiles, err := repos.Users.FindProfilesByUserUuids([]uuid.UUID{userUuid})
+		assert.Nil(t, err)
+		assert.Empty(t, profiles)
+
+		profile, err := repos.Users.FindProfileByUserUuid(userUuid)
+		assert.Nil(t, err)
+		assert.Equal(t, userUuid, profile.UUID)
+
+		again, err := repos.Users.FindProfileByUserUuid(userUuid)
+		assert.Nil(t, err)
+		assert.Equal(t, profile.ID, again.ID)
+
+		full, err := repos.Users.FindFullProfileById(profile.ID)
+		assert.Nil(t, err)
+		assert.True(t, full.Settings.Private)
+
+		settings, err := repos.Users.FindSettingsByProfileIds([]uint{profile.ID})
+		assert.Nil(t, err)
+		assert.Len(t, settings, 1)
+		assert.Equal(t, profile.ID, settings[0].ProfileID)
+
+		found, err := repos.Users.FindProfilesByIds([]uint{profile.ID, missingId})
+		assert.Nil(t, err)
+		assert.Len(t, found, 1)
+	})
+
+	t.Run("should update settings and moderators", func(t *testing.T) {
+		userUuid, _ := newUser(t, repos)
+		settings, err := repos.Users.UpdateSettings(userUuid, models.UpdateSettings{ShowName: true, ShowStats: true})
+		assert.Nil(t, err)
+		assert.False(t, settings.Private)
+		assert.True(t, settings.ShowName)
+
+		settings, err = repos.Users.FindSettingsByUserUuid(userUuid)
+		assert.Nil(t, err)
+		assert.True(t, settings.ShowStats)
+
+		profile, err := repos.Users.SetModerator(userUuid, true)
+		assert.Nil(t, err)
+		assert.True(t, profile.Moderator)
+
+		profile, err = repos.Users.FindProfileByUserUuid(userUuid)
+		assert.Nil(t, err)
+		assert.True(t, profile.Moderator)
+	})
+
+	t.Run("should keep the history of an item", func(t *testing.T) {
+		userUuid, profile := newUser(t, repos)
+		book := newApprovedBook(t, repos, userUuid, models.CreateBook{})
+		_, err := repos.Users.AddToCollection(uuid.New(), book.ID, models.StatusToRead, models.ReadingDates{})
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+		item, err := repos.Users.AddToCollection(userUuid, book.ID, models.StatusReading, models.ReadingDates{})
+		assert.Nil(t, err)
+		assert.Equal(t, profile.ID, item.ProfileID)
+		assert.NotNil(t, item.StartedAt)
+
+		found, err := repos.Users.FindItemByBook(userUuid, book.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, item.ID, found.ID)
+
+		item, err = repos.Users.ChangeItemStatus(item.ID, models.StatusRead, models.ReadingDates{})
+		assert.Nil(t, err)
+		assert.Equal(t, models.StatusRead, item.Status)
+		assert.NotNil(t, item.FinishedAt)
+
+		events, err := repos.Users.FindItemHistory(item.ID)
+		assert.Nil(t, err)
+		assert.Len(t, events, 2)
+		assert.Equal(t, models.StatusReading, events[0].Status)
+		assert.Equal(t, models.StatusRead, events[1].Status)
+
+		deleted, err := repos.Users.DeleteFromCollection(item.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, item.ID, deleted.ID)
+
+		_, err = repos.Users.FindItemById(item.ID)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	})
+
+	t.Run("should record progress", func(t *testing.T) {
+		userUuid, _ := newUser(t, repos)
+		pages := 200
+		book := newApprovedBook(t, repos, userUuid, models.CreateBook{PageCount: &pages})
+		item, err := repos.Users.AddToCollection(userUuid, book.ID, models.StatusToRead, models.ReadingDates{})
+		assert.Nil(t, err)
+
+		page, minutes := 50, 30
+		item, err = repos.Users.UpdateProgress(item.ID, models.ProgressUpdate{Page: &page, Minutes: &minutes})
+		assert.Nil(t, err)
+		assert.Equal(t, models.StatusReading, item.Status)
+		assert.Equal(t, 50, item.Progress)
+		assert.Equal(t, 25.0, *item.PercentComplete)
+
+		percent := 100.0
+		item, err = repos.Users.UpdateProgress(item.ID, models.ProgressUpdate{Percent: &percent})
+		assert.Nil(t, err)
+		assert.Equal(t, models.StatusRead, item.Status)
+		assert.Equal(t, 200, item.Progress)
+
+		sessions, err := repos.Users.FindItemSessions(item.ID)
+		assert.Nil(t, err)
+		assert.Len(t, sessions, 2)
+		assert.Equal(t, 50, sessions[0].PagesRead)
+		assert.Equal(t, 30, sessions[0].Minutes)
+		assert.Equal(t, 150, sessions[1].PagesRead)
+
+		events, err := repos.Users.FindItemHistory(item.ID)
+		assert.Nil(t, err)
+		assert.Len(t, events, 3)
+	})
+
+	t.Run("should keep the rating of books up to date", func(t *testing.T) {
+		first, _ := newUser(t, repos)
+		second, _ := newUser(t, repos)
+		book := newApprovedBook(t, repos, first, models.CreateBook{})
+		ratings := []float64{4, 2}
+		items := []*models.CollectionItem{}
+		for i, userUuid := range []uuid.UUID{first, second} {
+			item, err := repos.Users.AddToCollection(userUuid, book.ID, models.StatusRead, models.ReadingDates{})
+			assert.Nil(t, err)
+
+			item, err = repos.Users.RateItem(item.ID, &ratings[i])
+			assert.Nil(t, err)
+			assert.Equal(t, ratings[i], *item.Rating)
+			items = append(items, item)
+		}
+
+		found, err := repos.Books.FindById(book.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, 3.0, *found.AverageRating())
+
+		_, err = repos.Users.RateItem(items[0].ID, nil)
+		assert.Nil(t, err)
+
+		found, err = repos.Books.FindById(book.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, 2.0, *found.AverageRating())
+
+		_, err = repos.Users.DeleteFromCollection(items[1].ID)
+		assert.Nil(t, err)
+
+		found, err = repos.Books.FindById(book.ID)
+		assert.Nil(t, err)
+		assert.Nil(t, found.AverageRating())
+	})
+
+	t.Run("should filter, sort and page the collection", func(t *testing.T) {
+		userUuid, _ := newUser(t, repos)
+		titles := []string{"b", "C", "a"}
+		ratings := []float64{3, 5, 4}
+		items := []*models.CollectionItem{}
+		for i, title := range titles {
+			book := newApprovedBook(t, repos, userUuid, models.CreateBook{Title: title})
+			item, err := repos.Users.AddToCollection(userUuid, book.ID, models.StatusRead, models.ReadingDates{})
+			assert.Nil(t, err)
+
+			if i < 2 {
+				item, err = repos.Users.RateItem(item.ID, &ratings[i])
+				assert.Nil(t, err)
+			}
+
+			items = append(items, item)
+		}
+
+		found, err := repos.Users.FindItems(userUuid, nil, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, itemIds(items), itemIds(found))
+
+		sort := &models.CollectionSort{Field: models.CollectionSortFieldTitle}
+		found, err = repos.Users.FindItems(userUuid, nil, sort)
+		assert.Nil(t, err)
+		assert.Equal(t, []uint{items[2].ID, items[0].ID, items[1].ID}, itemIds(found))
+
+		// Unrated items come last either way
+		desc := models.SortDirectionDesc
+		sort = &models.CollectionSort{Field: models.CollectionSortFieldRating, Direction: &desc}
+		found, err = repos.Users.FindItems(userUuid, nil, sort)
+		assert.Nil(t, err)
+		assert.Equal(t, []uint{items[1].ID, items[0].ID, items[2].ID}, itemIds(found))
+
+		found, more, err := repos.Users.FindItemsPage(userUuid, nil, sort, &models.Page{Limit: 1, After: &items[1].ID})
+		assert.Nil(t, err)
+		assert.True(t, more)
+		assert.Equal(t, []uint{items[0].ID}, itemIds(found))
+
+		found, more, err = repos.Users.FindItemsPage(userUuid, nil, sort, &models.Page{Limit: 2, Before: &items[2].ID, Backward: true})
+		assert.Nil(t, err)
+		assert.False(t, more)
+		assert.Equal(t, []uint{items[1].ID, items[0].ID}, itemIds(found))
+
+		minRating := 4.0
+		filter := &models.CollectionFilter{MinRating: &minRating}
+		found, err = repos.Users.FindItems(userUuid, filter, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, []uint{items[1].ID}, itemIds(found))
+
+		count, err := repos.Users.CountItems(userUuid, filter)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("should leave books the viewer can't see out of public collections", func(t *testing.T) {
+		userUuid, profile := newUser(t, repos)
+		approved := newApprovedBook(t, repos, userUuid, models.CreateBook{})
+		pending := newBook(t, repos, userUuid, models.CreateBook{})
+		items := []*models.CollectionItem{}
+		for _, book := range []*models.Book{approved, pending} {
+			item, err := repos.Users.AddToCollection(userUuid, book.ID, models.StatusToRead, models.ReadingDates{})
+			assert.Nil(t, err)
+			items = append(items, item)
+		}
+
+		found, err := repos.Users.FindPublicItems(userUuid, nil, nil, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, []uint{items[0].ID}, itemIds(found))
+
+		found, _, err = repos.Users.FindPublicItemsPage(userUuid, profile, nil, nil, &models.Page{Limit: 10})
+		assert.Nil(t, err)
+		assert.Equal(t, itemIds(items), itemIds(found))
+
+		count, err := repos.Users.CountPublicItems(userUuid, nil, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), count)
+	})
+
+	t.Run("should find the lists of a user", func(t *testing.T) {
+		userUuid, _ := newUser(t, repos)
+		follower, _ := newUser(t, repos)
+		published, err := repos.Lists.Create("Published", nil, true, userUuid)
+		assert.Nil(t, err)
+
+		private, err := repos.Lists.Create("Private", nil, false, userUuid)
+		assert.Nil(t, err)
+
+		lists, err := repos.Users.FindLists(userUuid)
+		assert.Nil(t, err)
+		assert.Len(t, lists, 2)
+
+		lists, err = repos.Users.FindPublicLists(userUuid)
+		assert.Nil(t, err)
+		assert.Len(t, lists, 1)
+		assert.Equal(t, published.ID, lists[0].ID)
+
+		lists, more, err := repos.Users.FindListsPage(userUuid, true, &models.Page{Limit: 1, After: &published.ID})
+		assert.Nil(t, err)
+		assert.False(t, more)
+		assert.Len(t, lists, 1)
+		assert.Equal(t, private.ID, lists[0].ID)
+
+		count, err := repos.Users.CountLists(userUuid, false)
+		assert.Nil(t, err)
+		assert.Equal(t, int64(1), count)
+
+		for _, list := range []*models.List{published, private} {
+			_, err = repos.Lists.Follow(list.ID, follower)
+			assert.Nil(t, err)
+		}
+
+		lists, err = repos.Users.FindFollowedLists(follower)
+		assert.Nil(t, err)
+		assert.Len(t, lists, 1)
+		assert.Equal(t, published.ID, lists[0].ID)
+	})
+
+	t.Run("should aggregate stats", func(t *testing.T) {
+		userUuid, _ := newUser(t, repos)
+		author := newAuthor(t, repos, userUuid, "Author "+randomWord())
+		publisher := newPublisher(t, repos, userUuid)
+		pages := 100
+		for _, month := range []time.Month{time.January, time.January, time.March} {
+			book := newApprovedBook(t, repos, userUuid, models.CreateBook{
+				Authors:   []uint{author.ID},
+				Publisher: &publisher.ID,
+				PageCount: &pages,
+			})
+
+			startedAt := time.Date(2020, month, 1, 0, 0, 0, 0, time.UTC)
+			finishedAt := time.Date(2020, month, 11, 0, 0, 0, 0, time.UTC)
+			_, err := repos.Users.AddToCollection(userUuid, book.ID, models.StatusRead, models.ReadingDates{
+				StartedAt:  &startedAt,
+				FinishedAt: &finishedAt,
+			})
+			assert.Nil(t, err)
+		}
+
+		book := newApprovedBook(t, repos, userUuid, models.CreateBook{})
+		_, err := repos.Users.AddToCollection(userUuid, book.ID, models.StatusToRead, models.ReadingDates{})
+		assert.Nil(t, err)
+
+		stats, err := repos.Users.FindStats(userUuid, nil, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, []*models.PeriodStats{
+			{Period: "2020-01", Books: 2, Pages: 200},
+			{Period: "2020-03", Books: 1, Pages: 100},
+		}, stats.FinishedPerMonth)
+		assert.Equal(t, []*models.PeriodStats{{Period: "2020", Books: 3, Pages: 300}}, stats.FinishedPerYear)
+		assert.InDelta(t, 10, *stats.AverageDaysToFinish, 0.01)
+		assert.Equal(t, []*models.StatusCount{
+			{Status: models.StatusRead, Count: 3},
+			{Status: models.StatusToRead, Count: 1},
+		}, stats.StatusDistribution)
+		assert.Len(t, stats.TopAuthors, 1)
+		assert.Equal(t, author.ID, stats.TopAuthors[0].Author.ID)
+		assert.Equal(t, 3, stats.TopAuthors[0].Count)
+		assert.Len(t, stats.TopPublishers, 1)
+		assert.Equal(t, 3, stats.TopPublishers[0].Count)
+
+		from := time.Date(2020, time.February, 1, 0, 0, 0, 0, time.UTC)
+		stats, err = repos.Users.FindStats(userUuid, &models.StatsRange{From: &from}, nil)
+		assert.Nil(t, err)
+		assert.Len(t, stats.FinishedPerMonth, 1)
+		assert.Equal(t, 1, stats.TopAuthors[0].Count)
+	})
+
+	t.Run("should delete accounts with everything they own", func(t *testing.T) {
+		userUuid, profile := newUser(t, repos)
+		other, _ := newUser(t, repos)
+		book := newApprovedBook(t, repos, userUuid, models.CreateBook{})
+		item, err := repos.Users.AddToCollection(userUuid, book.ID, models.StatusRead, models.ReadingDates{})
+		assert.Nil(t, err)
+
+		rating := 5.0
+		_, err = repos.Users.RateItem(item.ID, &rating)
+		assert.Nil(t, err)
+
+		list, err := repos.Lists.Create("List", nil, true, userUuid)
+		assert.Nil(t, err)
+
+		_, err = repos.Lists.Follow(list.ID, other)
+		assert.Nil(t, err)
+
+		books := 1
+		_, err = repos.Goals.Set(profile.ID, 2024, &books, nil)
+		assert.Nil(t, err)
+
+		job, err := repos.Imports.Create(profile.ID, models.ImportFormatGoodreads, 1)
+		assert.Nil(t, err)
+
+		export, err := repos.Exports.Create(profile.ID, models.ExportFormatZip, time.Hour)
+		assert.Nil(t, err)
+
+		err = repos.Users.DeleteAccount(userUuid)
+		assert.Nil(t, err)
+
+		_, err = repos.Goals.FindByYear(profile.ID, 2024)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+		_, err = repos.Imports.FindById(job.ID)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+		_, err = repos.Exports.FindByToken(export.Token)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+		_, err = repos.Users.FindSettingsByUserUuid(userUuid)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+		_, err = repos.Users.FindItemById(item.ID)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+		_, err = repos.Lists.FindById(list.ID)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+		lists, err := repos.Users.FindFollowedLists(other)
+		assert.Nil(t, err)
+		assert.Empty(t, lists)
+
+		found, err := repos.Books.FindById(book.ID)
+		assert.Nil(t, err)
+		assert.Nil(t, found.ProfileID)
+		assert.Nil(t, found.AverageRating())
+
+		err = repos.Users.DeleteAccount(userUuid)
+		assert.Nil(t, err)
+	})
+}
+
+func testGoals(t *testing.T, repos *store.Repositories) {
+	// Reads a book with the page count, finished on the date.
+	read := func(t *testing.T, userUuid uuid.UUID, pages int, finishedAt time.Time) *models.CollectionItem {
+		book := newApprovedBook(t, repos, userUuid, models.CreateBook{PageCount: &pages})
+		item, err := repos.Users.AddToCollection(userUuid, book.ID, models.StatusRead, models.ReadingDates{FinishedAt: &finishedAt})
+		assert.Nil(t, err)
+
+		return item
+	}
+
+	t.Run("should count what was read in the year", func(t *testing.T) {
+		userUuid, profile := newUser(t, repos)
+		read(t, userUuid, 100, time.Date(2021, time.January, 1, 0, 0, 0, 0, time.UTC))
+		read(t, userUuid, 250, time.Date(2021, time.December, 31, 12, 0, 0, 0, time.UTC))
+		read(t, userUuid, 400, time.Date(2022, time.January, 1, 0, 0, 0, 0, time.UTC))
+
+		_, err := repos.Goals.FindByYear(profile.ID, 2021)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+
+		books := 3
+		goal, err := repos.Goals.Set(profile.ID, 2021, &books, nil)
+		assert.Nil(t, err)
+		assert.Equal(t, 2, goal.BooksRead)
+		assert.Equal(t, 350, goal.PagesRead)
+		assert.False(t, goal.Reached())
+	})
+
+	t.Run("should replace the targets of an existing goal", func(t *testing.T) {
+		_, profile := newUser(t, repos)
+		books, pages := 10, 1000
+		first, err := repos.Goals.Set(profile.ID, 2021, &books, nil)
+		assert.Nil(t, err)
+
+		second, err := repos.Goals.Set(profile.ID, 2021, nil, &pages)
+		assert.Nil(t, err)
+		assert.Equal(t, first.ID, second.ID)
+		assert.Nil(t, second.Books)
+		assert.Equal(t, 1000, *second.Pages)
+	})
+
+	t.Run("should only complete a goal once until it's reopened", func(t *testing.T) {
+		_, profile := newUser(t, repos)
+		goal, err := repos.Goals.Set(profile.ID, 2021, nil, nil)
+		assert.Nil(t, err)
+
+		completed, err := repos.Goals.Complete(goal)
+		assert.Nil(t, err)
+		assert.True(t, completed)
+		assert.NotNil(t, goal.CompletedAt)
+
+		completed, err = repos.Goals.Complete(goal)
+		assert.Nil(t, err)
+		assert.False(t, completed)
+
+		err = repos.Goals.Reopen(goal)
+		assert.Nil(t, err)
+
+		found, err := repos.Goals.FindByYear(profile.ID, 2021)
+		assert.Nil(t, err)
+		assert.Nil(t, found.CompletedAt)
+
+		completed, err = repos.Goals.Complete(found)
+		assert.Nil(t, err)
+		assert.True(t, completed)
+	})
+}
+
+func testImports(t *testing.T, repos *store.Repositories) {
+	t.Run("should keep track of a job", func(t *testing.T) {
+		_, profile := newUser(t, repos)
+		job, err := repos.Imports.Create(profile.ID, models.ImportFormatGoodreads, 3)
+		assert.Nil(t, err)
+		assert.Equal(t, models.ImportStatusPending, job.Status)
+		assert.Empty(t, job.Errors)
+
+		job.Status = models.ImportStatusRunning
+		job.Processed = 2
+		job.Imported = 1
+		err = repos.Imports.SaveProgress(job)
+		assert.Nil(t, err)
+
+		err = repos.Imports.AddError(job, 4, "Second", "Book has no title")
+		assert.Nil(t, err)
+		err = repos.Imports.AddError(job, 2, "First", "Book has no valid ISBN")
+		assert.Nil(t, err)
+		assert.Len(t, job.Errors, 2)
+
+		found, err := repos.Imports.FindById(job.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, models.ImportStatusRunning, found.Status)
+		assert.Equal(t, 2, found.Processed)
+		assert.Equal(t, 1, found.Imported)
+		assert.Nil(t, found.FinishedAt)
+		assert.Len(t, found.Errors, 2)
+		assert.Equal(t, 2, found.Errors[0].Line)
+		assert.Equal(t, "First", found.Errors[0].Title)
+
+		job.Processed = 3
+		err = repos.Imports.Finish(job, models.ImportStatusDone)
+		assert.Nil(t, err)
+
+		found, err = repos.Imports.FindById(job.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, models.ImportStatusDone, found.Status)
+		assert.Equal(t, 3, found.Processed)
+		assert.NotNil(t, found.FinishedAt)
+
+		_, err = repos.Imports.FindById(missingId)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	})
+}
+
+func testExports(t *testing.T, repos *store.Repositories) {
+	t.Run("should only find exports that haven't expired", func(t *testing.T) {
+		_, profile := newUser(t, repos)
+		export, err := repos.Exports.Create(profile.ID, models.ExportFormatZip, time.Hour)
+		assert.Nil(t, err)
+		assert.NotEmpty(t, export.Token)
+
+		found, err := repos.Exports.FindByToken(export.Token)
+		assert.Nil(t, err)
+		assert.Equal(t, export.ID, found.ID)
+		assert.Equal(t, profile.ID, found.ProfileID)
+
+		expired, err := repos.Exports.Create(profile.ID, models.ExportFormatZip, -time.Hour)
+		assert.Nil(t, err)
+
+		_, err = repos.Exports.FindByToken(expired.Token)
+		assert.ErrorIs(t, err, gorm.ErrRecordNotFound)
+	})
+
+	t.Run("should load everything of the profile", func(t *testing.T) {
+		userUuid, profile := newUser(t, repos)
+		author := newAuthor(t, repos, userUuid, "Author "+randomWord())
+		publisher := newPublisher(t, repos, userUuid)
+		book := newApprovedBook(t, repos, userUuid, models.CreateBook{
+			Authors:   []uint{author.ID},
+			Publisher: &publisher.ID,
+		})
+
+		pending := newBook(t, repos, userUuid, models.CreateBook{})
+		_, err := repos.Users.AddToCollection(userUuid, book.ID, models.StatusReading, models.ReadingDates{})
+		assert.Nil(t, err)
+
+		list, err := repos.Lists.Create("List", nil, false, userUuid)
+		assert.Nil(t, err)
+		_, err = repos.Lists.AddBook(list.ID, book.ID)
+		assert.Nil(t, err)
+
+		data, err := repos.Exports.FindData(profile.ID)
+		assert.Nil(t, err)
+		assert.Equal(t, profile.ID, data.Settings.ProfileID)
+
+		assert.Len(t, data.Collection, 1)
+		assert.Equal(t, book.ID, data.Collection[0].Book.ID)
+		assert.Equal(t, author.ID, data.Collection[0].Book.Authors[0].ID)
+		assert.Equal(t, publisher.Name, data.Collection[0].Book.Publisher.Name)
+
+		assert.Len(t, data.Lists, 1)
+		assert.Len(t, data.Lists[0].Books, 1)
+		assert.Equal(t, author.ID, data.Lists[0].Books[0].Authors[0].ID)
+		assert.Equal(t, publisher.Name, data.Lists[0].Books[0].Publisher.Name)
+
+		assert.Equal(t, []uint{book.ID, pending.ID}, bookIds(data.SubmittedBooks))
+		assert.Len(t, data.SubmittedBooks[0].Authors, 1)
+	})
+}
@@ -1,6 +1,8 @@
 package store
 
 import (
+	"time"
+
 	"github.com/google/uuid"
 	"github.com/marcos-brito/booklist/internal/models"
 	"gorm.io/gorm"
@@ -37,6 +39,38 @@ func (us *UserStore) FindFullProfileById(id uint) (*models.Profile, error) {
 	return profile, nil
 }
 
+func (us *UserStore) FindProfilesByIds(ids []uint) ([]*models.Profile, error) {
+	profiles := []*models.Profile{}
+	err := us.DB.Where("id IN ?", ids).Find(&profiles).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return profiles, nil
+}
+
+// Unlike FindProfileByUserUuid, profiles that don't exist yet are left
+// out rather than created.
+func (us *UserStore) FindProfilesByUserUuids(uuids []uuid.UUID) ([]*models.Profile, error) {
+	profiles := []*models.Profile{}
+	err := us.DB.Where("uuid IN ?", uuids).Find(&profiles).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return profiles, nil
+}
+
+func (us *UserStore) FindSettingsByProfileIds(ids []uint) ([]*models.Settings, error) {
+	settings := []*models.Settings{}
+	err := us.DB.Where("profile_id IN ?", ids).Find(&settings).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return settings, nil
+}
+
 func (us *UserStore) FindItemById(id uint) (*models.CollectionItem, error) {
 	item := &models.CollectionItem{}
 	err := us.DB.First(item, id).Error
@@ -48,6 +82,23 @@ func (us *UserStore) FindItemById(id uint) (*models.CollectionItem, error) {
 	return item, nil
 }
 
+// Returns the item of the user for the book, if the book is in their
+// collection.
+func (us *UserStore) FindItemByBook(userUuid uuid.UUID, bookId uint) (*models.CollectionItem, error) {
+	profile, err := us.FindProfileByUserUuid(userUuid)
+	if err != nil {
+		return nil, err
+	}
+
+	item := &models.CollectionItem{}
+	err = us.DB.Where(&models.CollectionItem{ProfileID: profile.ID, BookID: bookId}).First(item).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
 func (us *UserStore) FindSettingsByUserUuid(uuid uuid.UUID) (*models.Settings, error) {
 	profile := &models.Profile{}
 	err := us.DB.First(profile, &models.Profile{UUID: uuid}).Error
@@ -64,14 +115,33 @@ func (us *UserStore) FindSettingsByUserUuid(uuid uuid.UUID) (*models.Settings, e
 	return settings, nil
 }
 
-func (us *UserStore) FindItems(userUuid uuid.UUID) ([]*models.CollectionItem, error) {
+// Returns the items in the user's collection that match the filter, in
+// the given order. Both can be nil.
+func (us *UserStore) FindItems(userUuid uuid.UUID, filter *models.CollectionFilter, sort *models.CollectionSort) ([]*models.CollectionItem, error) {
+	profile, err := us.FindProfileByUserUuid(userUuid)
+	if err != nil {
+		return nil, err
+	}
+
+	items := []*models.CollectionItem{}
+	err = us.filteredItems(us.items(profile.ID), filter).Order(itemOrdering(sort).orderBy(false)).Find(&items).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// Same as FindItems, leaving out books the viewer can't see.
+func (us *UserStore) FindPublicItems(userUuid uuid.UUID, viewer *models.Profile, filter *models.CollectionFilter, sort *models.CollectionSort) ([]*models.CollectionItem, error) {
 	profile, err := us.FindProfileByUserUuid(userUuid)
 	if err != nil {
 		return nil, err
 	}
 
 	items := []*models.CollectionItem{}
-	err = us.DB.Find(&items, models.CollectionItem{ProfileID: profile.ID}).Error
+	query := us.filteredItems(us.publicItems(profile.ID, viewer), filter)
+	err = query.Order(itemOrdering(sort).orderBy(false)).Find(&items).Error
 	if err != nil {
 		return nil, err
 	}
@@ -79,6 +149,141 @@ func (us *UserStore) FindItems(userUuid uuid.UUID) ([]*models.CollectionItem, er
 	return items, nil
 }
 
+// Returns a page of the user's collection that matches the filter, in
+// the given order.
+func (us *UserStore) FindItemsPage(userUuid uuid.UUID, filter *models.CollectionFilter, sort *models.CollectionSort, page *models.Page) ([]*models.CollectionItem, bool, error) {
+	profile, err := us.FindProfileByUserUuid(userUuid)
+	if err != nil {
+		return nil, false, err
+	}
+
+	query := us.filteredItems(us.items(profile.ID), filter)
+	return findPage[models.CollectionItem](query, itemOrdering(sort), page)
+}
+
+func (us *UserStore) CountItems(userUuid uuid.UUID, filter *models.CollectionFilter) (int64, error) {
+	profile, err := us.FindProfileByUserUuid(userUuid)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int64
+	err = us.filteredItems(us.items(profile.ID), filter).Count(&count).Error
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+// Same as FindItemsPage, leaving out books the viewer can't see.
+func (us *UserStore) FindPublicItemsPage(userUuid uuid.UUID, viewer *models.Profile, filter *models.CollectionFilter, sort *models.CollectionSort, page *models.Page) ([]*models.CollectionItem, bool, error) {
+	profile, err := us.FindProfileByUserUuid(userUuid)
+	if err != nil {
+		return nil, false, err
+	}
+
+	query := us.filteredItems(us.publicItems(profile.ID, viewer), filter)
+	return findPage[models.CollectionItem](query, itemOrdering(sort), page)
+}
+
+func (us *UserStore) CountPublicItems(userUuid uuid.UUID, viewer *models.Profile, filter *models.CollectionFilter) (int64, error) {
+	profile, err := us.FindProfileByUserUuid(userUuid)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int64
+	err = us.filteredItems(us.publicItems(profile.ID, viewer), filter).Count(&count).Error
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (us *UserStore) items(profileId uint) *gorm.DB {
+	return us.DB.Model(&models.CollectionItem{}).Where(&models.CollectionItem{ProfileID: profileId})
+}
+
+func (us *UserStore) publicItems(profileId uint, viewer *models.Profile) *gorm.DB {
+	visible := us.DB.Model(&models.Book{}).Scopes(VisibleTo(viewer)).Select("books.id")
+	return us.items(profileId).Where("book_id IN (?)", visible)
+}
+
+func (us *UserStore) filteredItems(query *gorm.DB, filter *models.CollectionFilter) *gorm.DB {
+	if filter == nil {
+		return query
+	}
+
+	if len(filter.Statuses) > 0 {
+		query = query.Where("collection_items.status IN ?", filter.Statuses)
+	}
+
+	if filter.Author != nil {
+		query = query.Where("collection_items.book_id IN (?)",
+			us.DB.Table("book_authors").Select("book_id").Where("author_id = ?", *filter.Author))
+	}
+
+	if filter.Publisher != nil {
+		query = query.Where("collection_items.book_id IN (?)",
+			us.DB.Model(&models.Book{}).Select("id").Where("publisher_id = ?", *filter.Publisher))
+	}
+
+	if filter.AddedFrom != nil {
+		query = query.Where("collection_items.created_at >= ?", *filter.AddedFrom)
+	}
+
+	if filter.AddedTo != nil {
+		query = query.Where("collection_items.created_at <= ?", *filter.AddedTo)
+	}
+
+	if filter.FinishedFrom != nil {
+		query = query.Where("collection_items.finished_at >= ?", *filter.FinishedFrom)
+	}
+
+	if filter.FinishedTo != nil {
+		query = query.Where("collection_items.finished_at <= ?", *filter.FinishedTo)
+	}
+
+	if filter.MinRating != nil {
+		query = query.Where("collection_items.rating >= ?", *filter.MinRating)
+	}
+
+	if filter.MaxRating != nil {
+		query = query.Where("collection_items.rating <= ?", *filter.MaxRating)
+	}
+
+	return query
+}
+
+// Items are in the order they were added unless sorted otherwise.
+// Titles are sorted ignoring case.
+func itemOrdering(sort *models.CollectionSort) *ordering {
+	order := byId("collection_items.id")
+	if sort == nil {
+		return order
+	}
+
+	switch sort.Field {
+	case models.CollectionSortFieldTitle:
+		order.expr = "(SELECT LOWER(books.title) FROM books WHERE books.id = collection_items.book_id)"
+		order.value = "SELECT LOWER(books.title) FROM collection_items JOIN books ON books.id = collection_items.book_id WHERE collection_items.id = ?"
+	case models.CollectionSortFieldDateAdded:
+		order.expr = "collection_items.created_at"
+		order.value = "SELECT created_at FROM collection_items WHERE id = ?"
+	case models.CollectionSortFieldDateFinished:
+		order.expr = "collection_items.finished_at"
+		order.value = "SELECT finished_at FROM collection_items WHERE id = ?"
+	case models.CollectionSortFieldRating:
+		order.expr = "collection_items.rating"
+		order.value = "SELECT rating FROM collection_items WHERE id = ?"
+	}
+
+	order.desc = sort.Direction != nil && *sort.Direction == models.SortDirectionDesc
+	return order
+}
+
 func (us *UserStore) FindLists(userUuid uuid.UUID) ([]*models.List, error) {
 	profile, err := us.FindProfileByUserUuid(userUuid)
 	if err != nil {
@@ -109,20 +314,75 @@ func (us *UserStore) FindPublicLists(userUuid uuid.UUID) ([]*models.List, error)
 	return lists, nil
 }
 
-func (us *UserStore) AddToCollection(userUuid uuid.UUID, bookID uint, status models.Status) (*models.CollectionItem, error) {
+// Returns a page of the user's lists, in the order they were created.
+// Only published lists are included unless all is set.
+func (us *UserStore) FindListsPage(userUuid uuid.UUID, all bool, page *models.Page) ([]*models.List, bool, error) {
+	profile, err := us.FindProfileByUserUuid(userUuid)
+	if err != nil {
+		return nil, false, err
+	}
+
+	return findPage[models.List](us.lists(profile.ID, all), byId("lists.id"), page)
+}
+
+func (us *UserStore) CountLists(userUuid uuid.UUID, all bool) (int64, error) {
+	profile, err := us.FindProfileByUserUuid(userUuid)
+	if err != nil {
+		return 0, err
+	}
+
+	var count int64
+	err = us.lists(profile.ID, all).Count(&count).Error
+	if err != nil {
+		return 0, err
+	}
+
+	return count, nil
+}
+
+func (us *UserStore) lists(profileId uint, all bool) *gorm.DB {
+	query := us.DB.Model(&models.List{}).Where(&models.List{ProfileID: profileId})
+	if !all {
+		query = query.Where(&models.List{Published: true})
+	}
+
+	return query
+}
+
+// Returns the published lists followed by the user. Lists that were
+// unpublished after being followed are left out.
+func (us *UserStore) FindFollowedLists(userUuid uuid.UUID) ([]*models.List, error) {
+	profile, err := us.FindProfileByUserUuid(userUuid)
+	if err != nil {
+		return nil, err
+	}
+
+	lists := []*models.List{}
+	err = us.DB.Model(profile).Where(&models.List{Published: true}).Association("FollowedLists").Find(&lists)
+	if err != nil {
+		return nil, err
+	}
+
+	return lists, nil
+}
+
+func (us *UserStore) AddToCollection(userUuid uuid.UUID, bookID uint, status models.Status, dates models.ReadingDates) (*models.CollectionItem, error) {
 	profile := &models.Profile{}
 	err := us.DB.First(profile, &models.Profile{UUID: userUuid}).Error
 	if err != nil {
 		return nil, err
 	}
 
-	item := &models.CollectionItem{
-		ProfileID: profile.ID,
-		BookID:    bookID,
-		Status:    status,
-	}
+	item := models.NewCollectionItem(profile.ID, bookID, status, dates, time.Now())
+	err = us.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Create(item).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Create(item.Event()).Error
+	})
 
-	err = us.DB.Create(item).Error
 	if err != nil {
 		return nil, err
 	}
@@ -135,9 +395,42 @@ func (us *UserStore) AddToCollection(userUuid uuid.UUID, bookID uint, status mod
 	return item, nil
 }
 
+// Returns every status change of the item, oldest first.
+func (us *UserStore) FindItemHistory(id uint) ([]*models.ReadingEvent, error) {
+	events := []*models.ReadingEvent{}
+	err := us.DB.Where(&models.ReadingEvent{CollectionItemID: id}).Order("created_at, id").Find(&events).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return events, nil
+}
+
+// Returns every progress update of the item, oldest first.
+func (us *UserStore) FindItemSessions(id uint) ([]*models.ReadingSession, error) {
+	sessions := []*models.ReadingSession{}
+	err := us.DB.Where(&models.ReadingSession{CollectionItemID: id}).Order("created_at, id").Find(&sessions).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return sessions, nil
+}
+
 func (us *UserStore) DeleteFromCollection(id uint) (*models.CollectionItem, error) {
-	item := &models.CollectionItem{}
-	err := us.DB.Delete(&item, id).Error
+	item, err := us.FindItemById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	err = us.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Delete(item).Error
+		if err != nil {
+			return err
+		}
+
+		return updateBookRating(tx, item.BookID, item.Rating, nil)
+	})
 
 	if err != nil {
 		return nil, err
@@ -146,6 +439,21 @@ func (us *UserStore) DeleteFromCollection(id uint) (*models.CollectionItem, erro
 	return item, nil
 }
 
+func (us *UserStore) SetModerator(uuid uuid.UUID, moderator bool) (*models.Profile, error) {
+	profile, err := us.FindProfileByUserUuid(uuid)
+	if err != nil {
+		return nil, err
+	}
+
+	profile.Moderator = moderator
+	err = us.DB.Save(profile).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return profile, nil
+}
+
 func (us *UserStore) UpdateSettings(uuid uuid.UUID, changes models.UpdateSettings) (*models.Settings, error) {
 	settings, err := us.FindSettingsByUserUuid(uuid)
 	if err != nil {
@@ -167,15 +475,64 @@ func (us *UserStore) UpdateSettings(uuid uuid.UUID, changes models.UpdateSetting
 	return settings, nil
 }
 
-func (us *UserStore) ChangeItemStatus(id uint, status models.Status) (*models.CollectionItem, error) {
+func (us *UserStore) ChangeItemStatus(id uint, status models.Status, dates models.ReadingDates) (*models.CollectionItem, error) {
 	item := &models.CollectionItem{}
 	err := us.DB.First(item, id).Error
 	if err != nil {
 		return nil, err
 	}
 
-	item.Status = status
-	err = us.DB.Save(item).Error
+	item.Transition(status, dates, time.Now())
+	err = us.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Save(item).Error
+		if err != nil {
+			return err
+		}
+
+		return tx.Create(item.Event()).Error
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	err = us.DB.First(item, item.ID).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+// Records a reading session and moves the item forward. If that changes
+// its status, the change goes to the history like any other.
+func (us *UserStore) UpdateProgress(id uint, update models.ProgressUpdate) (*models.CollectionItem, error) {
+	item := &models.CollectionItem{}
+	err := us.DB.Preload("Book").First(item, id).Error
+	if err != nil {
+		return nil, err
+	}
+
+	status := item.Status
+	session := item.Advance(update, item.Book.PageCount, time.Now())
+	err = us.DB.Transaction(func(tx *gorm.DB) error {
+		err := tx.Omit("Book").Save(item).Error
+		if err != nil {
+			return err
+		}
+
+		err = tx.Create(session).Error
+		if err != nil {
+			return err
+		}
+
+		if item.Status == status {
+			return nil
+		}
+
+		return tx.Create(item.Event()).Error
+	})
+
 	if err != nil {
 		return nil, err
 	}
@@ -187,3 +544,78 @@ func (us *UserStore) ChangeItemStatus(id uint, status models.Status) (*models.Co
 
 	return item, nil
 }
+
+// Rates the item, or removes its rating if there is none, and updates
+// the rating aggregates of the book. The item is locked while that
+// happens so concurrent ratings can't apply the same previous one twice.
+func (us *UserStore) RateItem(id uint, rating *float64) (*models.CollectionItem, error) {
+	err := us.DB.Transaction(func(tx *gorm.DB) error {
+		item := &models.CollectionItem{}
+		err := tx.Scopes(ForUpdate).First(item, id).Error
+		if err != nil {
+			return err
+		}
+
+		previous := item.Rating
+		item.Rating = rating
+		err = tx.Save(item).Error
+		if err != nil {
+			return err
+		}
+
+		return updateBookRating(tx, item.BookID, previous, rating)
+	})
+
+	if err != nil {
+		return nil, err
+	}
+
+	return us.FindItemById(id)
+}
+
+// Reviews the item, or removes its review if there is none.
+func (us *UserStore) ReviewItem(id uint, review *string, spoiler bool) (*models.CollectionItem, error) {
+	item, err := us.FindItemById(id)
+	if err != nil {
+		return nil, err
+	}
+
+	item.Review = review
+	item.Spoiler = spoiler && review != nil
+	item.ReviewedAt = nil
+	if review != nil {
+		now := time.Now()
+		item.ReviewedAt = &now
+	}
+
+	err = us.DB.Save(item).Error
+	if err != nil {
+		return nil, err
+	}
+
+	return us.FindItemById(id)
+}
+
+// Applies the change from one rating to another to the count and total
+// of the book, so its average stays correct without recomputing it.
+func updateBookRating(tx *gorm.DB, bookId uint, from, to *float64) error {
+	count, total := 0, 0.0
+	if from != nil {
+		count--
+		total -= *from
+	}
+
+	if to != nil {
+		count++
+		total += *to
+	}
+
+	if count == 0 && total == 0 {
+		return nil
+	}
+
+	return tx.Model(&models.Book{}).Where("id = ?", bookId).UpdateColumns(map[string]any{
+		"rating_count": gorm.Expr("rating_count + ?", count),
+		"rating_total": gorm.Expr("rating_total + ?", total),
+	}).Error
+}
@@ -0,0 +1,28 @@
+package stream
+
+import (
+	"github.com/google/uuid"
+)
+
+// Emitted when a user reaches a yearly reading goal. A goal that falls
+// short again, like when a book is removed, emits it again once it's
+// reached back.
+type GoalCompleted struct {
+	UserUUID uuid.UUID
+	Year     int
+	Books    int
+	Pages    int
+}
+
+func (e *GoalCompleted) StreamName() string {
+	return "goal-completed"
+}
+
+func (e *GoalCompleted) Values() values {
+	return values{
+		"user":  e.UserUUID.String(),
+		"year":  e.Year,
+		"books": e.Books,
+		"pages": e.Pages,
+	}
+}
@@ -0,0 +1,151 @@
+// Package testdb sets up the databases that test suites run against.
+// Suites run once for every database the server supports, each one
+// migrated from scratch.
+package testdb
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"log"
+	"os"
+	"path/filepath"
+	"runtime"
+	"testing"
+	"time"
+
+	"github.com/marcos-brito/booklist/internal/config"
+	"github.com/marcos-brito/booklist/internal/conn"
+	"github.com/marcos-brito/booklist/internal/migrate"
+	"github.com/testcontainers/testcontainers-go"
+	"github.com/testcontainers/testcontainers-go/modules/postgres"
+	"github.com/testcontainers/testcontainers-go/wait"
+	"gorm.io/gorm"
+)
+
+// Every database the server supports.
+var Drivers = []string{config.DriverSQLite, config.DriverPostgres}
+
+// Runs the tests once for every driver, or only for the one in
+// TEST_DATABASE if it's set. Setup is called before each run and
+// returns its teardown. Meant to be called from TestMain.
+func Main(m *testing.M, drivers []string, setup func(driver string) func()) {
+	if driver := os.Getenv("TEST_DATABASE"); driver != "" {
+		drivers = []string{driver}
+	}
+
+	for _, driver := range drivers {
+		log.Printf("running tests against %s", driver)
+		teardown := setup(driver)
+		code := m.Run()
+		teardown()
+
+		if code != 0 {
+			os.Exit(code)
+		}
+	}
+}
+
+// Opens a database for the driver with every migration applied. Tests
+// can't run without it, so any error is fatal.
+func Open(driver string) (*gorm.DB, func()) {
+	var db *gorm.DB
+	var teardown func()
+	var err error
+
+	switch driver {
+	case config.DriverSQLite:
+		db, teardown, err = OpenSQLite()
+	case config.DriverPostgres:
+		db, teardown, err = OpenPostgres()
+	default:
+		err = fmt.Errorf("unknown database driver %q", driver)
+	}
+
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	migrator, err := migrate.New(db)
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	_, err = migrator.Up()
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	return db, teardown
+}
+
+// Opens a database in a temporary file, removed on teardown.
+func OpenSQLite() (*gorm.DB, func(), error) {
+	dir, err := os.MkdirTemp("", "booklist")
+	if err != nil {
+		return nil, nil, err
+	}
+
+	db, err := conn.NewSQLiteConnection(filepath.Join(dir, "booklist.db"))
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return db, func() {
+		if err := os.RemoveAll(dir); err != nil {
+			log.Fatalf("failed to remove database: %s", err)
+		}
+	}, nil
+}
+
+// Starts a server with the credentials in the .env at the root of the
+// repository, stopped on teardown.
+func OpenPostgres() (*gorm.DB, func(), error) {
+	_, file, _, _ := runtime.Caller(0)
+	env := filepath.Join(filepath.Dir(file), "..", "..", ".env")
+
+	cfg, err := config.Load(flag.NewFlagSet("test", flag.ContinueOnError), []string{"-config", env})
+	if err != nil {
+		return nil, nil, err
+	}
+
+	container := StartPostgres(&cfg.Database.Postgres)
+	db, err := conn.NewPostgresConnection(&cfg.Database.Postgres)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	return db, func() {
+		if err := testcontainers.TerminateContainer(container); err != nil {
+			log.Fatalf("failed to terminate container: %s", err)
+		}
+	}, nil
+}
+
+// Starts a server for the database in the config and points the config
+// at it.
+func StartPostgres(cfg *config.Postgres) testcontainers.Container {
+	ctx := context.Background()
+	container, err := postgres.Run(ctx,
+		"postgres:16-alpine",
+		postgres.WithDatabase(cfg.Name),
+		postgres.WithUsername(cfg.User),
+		postgres.WithPassword(cfg.Password),
+		testcontainers.WithWaitStrategy(
+			wait.ForLog("database system is ready to accept connections").
+				WithOccurrence(2).
+				WithStartupTimeout(5*time.Second)),
+	)
+
+	if err != nil {
+		log.Fatalf("failed to start container: %s", err)
+	}
+
+	port, err := container.MappedPort(ctx, "5432")
+	if err != nil {
+		log.Fatal(err)
+	}
+
+	cfg.Port = port.Int()
+	return container
+}